@@ -53,7 +53,7 @@ func NewApp(cfg config.Config) (*app, *sqlx.DB, error) {
 	}
 
 	log.Printf("create new database repository")
-	dbRepository := repository.NewRepository(db, cfg.Backend.BaseUrl)
+	dbRepository := repository.NewRepository(db, cfg.Backend.BaseUrl, cfg.Backend.PriorityOrder)
 
 	jiraService := service.NewService(dbRepository)
 
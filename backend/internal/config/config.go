@@ -19,6 +19,8 @@ type Backend struct {
 	Port             string        `yaml:"port"`
 	AnalyticsTimeout time.Duration `yaml:"analyticsTimeout"`
 	ResourceTimeout  time.Duration `yaml:"resourceTimeout"`
+	// PriorityOrder - имена приоритетов Jira от высшего к низшему
+	PriorityOrder []string `yaml:"priorityOrder"`
 }
 
 type Logging struct {
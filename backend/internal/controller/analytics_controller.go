@@ -32,7 +32,7 @@ func (h *AnalyticsController) GetProjectAnalytics(w http.ResponseWriter, r *http
 	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
 	defer cancel()
 
-	analytics, err := h.service.GetProjectAnalytics(ctx, projectKey)
+	analytics, err := h.service.GetProjectAnalytics(ctx, projectKey, parseIssueFilter(r))
 	if err != nil {
 		log.Printf("Error fetching analytics for project %s: %v", projectKey, err)
 
@@ -116,7 +116,9 @@ func (h *AnalyticsController) DeleteProjectAnalytics(w http.ResponseWriter, r *h
 	utils.WriteJSONResponse(w, response)
 }
 
-// GET /api/v1/graph/get/{taskNumber:[0-9]+}
+// GET /api/v1/graph/get/{taskNumber:[0-9]+}?project=KEY&component=UI&cf.team=Core
+// Параметры component, label, fixVersion и cf.<поле> фильтруют задачи,
+// такая аналитика считается заново и не кэшируется.
 func (h *AnalyticsController) GetGraph(w http.ResponseWriter, r *http.Request) {
 	params := mux.Vars(r)
 	taskNumber, err := strconv.Atoi(params["taskNumber"])
@@ -131,10 +133,12 @@ func (h *AnalyticsController) GetGraph(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	filter := parseIssueFilter(r)
+
 	ctx, cancel := context.WithTimeout(r.Context(), h.cfg.AnalyticsTimeout)
 	defer cancel()
 
-	data, err := h.getAnalyticsData(ctx, taskNumber, projectKey)
+	data, err := h.getAnalyticsData(ctx, taskNumber, projectKey, filter)
 	if err != nil {
 		if errors.Is(err, context.DeadlineExceeded) {
 			http.Error(w, "Request Timeout", http.StatusRequestTimeout)
@@ -174,17 +178,17 @@ func (h *AnalyticsController) MakeGraph(w http.ResponseWriter, r *http.Request)
 
 	switch taskNumber {
 	case 1:
-		_, err = h.service.CalculateOpenTimeHistogram(ctx, projectKey, taskNumber)
+		_, err = h.service.CalculateOpenTimeHistogram(ctx, projectKey, taskNumber, models.IssueFilter{})
 	case 2:
-		_, err = h.service.CalculateStatusTimeDistribution(ctx, projectKey, taskNumber)
+		_, err = h.service.CalculateStatusTimeDistribution(ctx, projectKey, taskNumber, models.IssueFilter{})
 	case 3:
-		_, err = h.service.CalculateActivityGraph(ctx, projectKey, taskNumber)
+		_, err = h.service.CalculateActivityGraph(ctx, projectKey, taskNumber, models.IssueFilter{})
 	case 4:
-		_, err = h.service.CalculateComplexityGraph(ctx, projectKey, taskNumber)
+		_, err = h.service.CalculateComplexityGraph(ctx, projectKey, taskNumber, models.IssueFilter{})
 	case 5:
-		_, err = h.service.CalculatePriorityDistribution(ctx, projectKey, taskNumber)
+		_, err = h.service.CalculatePriorityDistribution(ctx, projectKey, taskNumber, models.IssueFilter{})
 	case 6:
-		_, err = h.service.CalculatePriorityDistributionClosedTasks(ctx, projectKey, taskNumber)
+		_, err = h.service.CalculatePriorityDistributionClosedTasks(ctx, projectKey, taskNumber, models.IssueFilter{})
 	default:
 		http.Error(w, "invalid task number", http.StatusBadRequest)
 		return
@@ -224,13 +228,15 @@ func (h *AnalyticsController) GetComparison(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
+	filter := parseIssueFilter(r)
+
 	ctx, cancel := context.WithTimeout(r.Context(), h.cfg.AnalyticsTimeout)
 	defer cancel()
 
 	var comparisonResults []map[string]interface{}
 
 	for _, projectKey := range projectKeys {
-		data, err := h.getAnalyticsData(ctx, taskNumber, projectKey)
+		data, err := h.getAnalyticsData(ctx, taskNumber, projectKey, filter)
 		if err != nil {
 			if errors.Is(err, context.DeadlineExceeded) {
 				http.Error(w, "Request Timeout", http.StatusRequestTimeout)
@@ -256,20 +262,68 @@ func (h *AnalyticsController) GetComparison(w http.ResponseWriter, r *http.Reque
 	utils.WriteJSONResponse(w, response)
 }
 
-func (h *AnalyticsController) getAnalyticsData(ctx context.Context, taskNumber int, projectKey string) (interface{}, error) {
+// GET /api/v1/projects/{key}/customFields/{field}/groups?cf.team=Core
+func (h *AnalyticsController) GroupByCustomField(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	projectKey, field := vars["key"], vars["field"]
+	filter := parseIssueFilter(r)
+
+	ctx, cancel := context.WithTimeout(r.Context(), h.cfg.AnalyticsTimeout)
+	defer cancel()
+
+	groups, err := h.service.GroupByCustomField(ctx, projectKey, field, filter)
+	if err != nil {
+		var invalidInputErr *models.InvalidInputError
+		if errors.As(err, &invalidInputErr) {
+			utils.WriteErrorResponse(w, http.StatusBadRequest, err)
+			return
+		}
+		utils.WriteErrorResponse(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.WriteJSONResponse(w, map[string]interface{}{
+		"data": groups,
+	})
+}
+
+// parseIssueFilter читает фильтр задач из параметров запроса component, label,
+// fixVersion и cf.<поле>=значение для пользовательских полей
+func parseIssueFilter(r *http.Request) models.IssueFilter {
+	query := r.URL.Query()
+	filter := models.IssueFilter{
+		Component:  query.Get("component"),
+		Label:      query.Get("label"),
+		FixVersion: query.Get("fixVersion"),
+	}
+
+	for param, values := range query {
+		name, ok := strings.CutPrefix(param, "cf.")
+		if !ok || name == "" || len(values) == 0 {
+			continue
+		}
+		if filter.CustomFields == nil {
+			filter.CustomFields = make(map[string]string)
+		}
+		filter.CustomFields[name] = values[0]
+	}
+	return filter
+}
+
+func (h *AnalyticsController) getAnalyticsData(ctx context.Context, taskNumber int, projectKey string, filter models.IssueFilter) (interface{}, error) {
 	switch taskNumber {
 	case 1:
-		return h.service.GetOpenTimeHistogram(ctx, projectKey, taskNumber)
+		return h.service.GetOpenTimeHistogram(ctx, projectKey, taskNumber, filter)
 	case 2:
-		return h.service.GetStatusTimeDistribution(ctx, projectKey, taskNumber)
+		return h.service.GetStatusTimeDistribution(ctx, projectKey, taskNumber, filter)
 	case 3:
-		return h.service.GetActivityGraph(ctx, projectKey, taskNumber)
+		return h.service.GetActivityGraph(ctx, projectKey, taskNumber, filter)
 	case 4:
-		return h.service.GetComplexityGraph(ctx, projectKey, taskNumber)
+		return h.service.GetComplexityGraph(ctx, projectKey, taskNumber, filter)
 	case 5:
-		return h.service.GetPriorityDistribution(ctx, projectKey, taskNumber)
+		return h.service.GetPriorityDistribution(ctx, projectKey, taskNumber, filter)
 	case 6:
-		return h.service.GetPriorityDistributionClosedTasks(ctx, projectKey, taskNumber)
+		return h.service.GetPriorityDistributionClosedTasks(ctx, projectKey, taskNumber, filter)
 	default:
 		return nil, fmt.Errorf("invalid task number")
 	}
@@ -0,0 +1,85 @@
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/gorilla/mux"
+	"jiraAnalyzer/backend/internal/models"
+	"jiraAnalyzer/backend/internal/service"
+	"jiraAnalyzer/backend/internal/utils"
+	"net/http"
+	"strconv"
+)
+
+type AuthorController struct {
+	service *service.AuthorService
+}
+
+func NewAuthorController(service *service.AuthorService) *AuthorController {
+	return &AuthorController{service: service}
+}
+
+// GET /api/v1/authors/{id}
+func (h *AuthorController) GetAuthor(w http.ResponseWriter, r *http.Request) {
+	authorID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, fmt.Errorf("invalid author id"))
+		return
+	}
+
+	author, err := h.service.GetAuthor(r.Context(), authorID)
+	if err != nil {
+		writeAuthorError(w, fmt.Errorf("failed to get author: %w", err))
+		return
+	}
+
+	utils.WriteJSONResponse(w, map[string]interface{}{
+		"data": author,
+	})
+}
+
+// POST /api/v1/authors/{id}/merge
+// Тело запроса: {"intoId": 42}. Автор {id} объединяется с автором intoId.
+func (h *AuthorController) MergeAuthors(w http.ResponseWriter, r *http.Request) {
+	sourceID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, fmt.Errorf("invalid author id"))
+		return
+	}
+
+	var request struct {
+		IntoID int `json:"intoId"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, fmt.Errorf("error while decoding merge request: %w", err))
+		return
+	}
+	if request.IntoID <= 0 {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, fmt.Errorf("intoId is required"))
+		return
+	}
+
+	author, err := h.service.MergeAuthors(r.Context(), sourceID, request.IntoID)
+	if err != nil {
+		writeAuthorError(w, fmt.Errorf("failed to merge authors: %w", err))
+		return
+	}
+
+	utils.WriteJSONResponse(w, map[string]interface{}{
+		"data": author,
+	})
+}
+
+func writeAuthorError(w http.ResponseWriter, err error) {
+	var notFoundErr *models.NotFoundError
+	var invalidInputErr *models.InvalidInputError
+	switch {
+	case errors.As(err, &notFoundErr):
+		utils.WriteErrorResponse(w, http.StatusNotFound, err)
+	case errors.As(err, &invalidInputErr):
+		utils.WriteErrorResponse(w, http.StatusBadRequest, err)
+	default:
+		utils.WriteErrorResponse(w, http.StatusInternalServerError, err)
+	}
+}
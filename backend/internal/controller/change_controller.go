@@ -0,0 +1,109 @@
+package controller
+
+import (
+	"fmt"
+	"github.com/gorilla/mux"
+	"jiraAnalyzer/backend/internal/service"
+	"jiraAnalyzer/backend/internal/utils"
+	"net/http"
+	"strconv"
+)
+
+type ChangeController struct {
+	service *service.ChangeService
+}
+
+func NewChangeController(service *service.ChangeService) *ChangeController {
+	return &ChangeController{service: service}
+}
+
+// GET /api/v1/issues/{key}/changes?field=assignee
+func (h *ChangeController) GetIssueFieldChanges(w http.ResponseWriter, r *http.Request) {
+	issueKey := mux.Vars(r)["key"]
+	field := r.URL.Query().Get("field")
+
+	changes, err := h.service.GetIssueFieldChanges(r.Context(), issueKey, field)
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusInternalServerError, fmt.Errorf("failed to get issue changes: %w", err))
+		return
+	}
+
+	utils.WriteJSONResponse(w, map[string]interface{}{
+		"changes": changes,
+	})
+}
+
+// GET /api/v1/projects/{key}/changes?field=priority&page=1&limit=20
+func (h *ChangeController) GetProjectFieldChanges(w http.ResponseWriter, r *http.Request) {
+	projectKey := mux.Vars(r)["key"]
+	field := r.URL.Query().Get("field")
+
+	page, limit, err := parsePagination(r)
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, err)
+		return
+	}
+
+	changes, err := h.service.GetProjectFieldChanges(r.Context(), projectKey, field, page, limit)
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusInternalServerError, fmt.Errorf("failed to get project changes: %w", err))
+		return
+	}
+
+	utils.WriteJSONResponse(w, map[string]interface{}{
+		"changes": changes,
+	})
+}
+
+// GET /api/v1/projects/{key}/reassignments
+func (h *ChangeController) GetReassignments(w http.ResponseWriter, r *http.Request) {
+	projectKey := mux.Vars(r)["key"]
+
+	data, err := h.service.GetReassignmentCounts(r.Context(), projectKey)
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusInternalServerError, fmt.Errorf("failed to get reassignments: %w", err))
+		return
+	}
+
+	utils.WriteJSONResponse(w, map[string]interface{}{
+		"data": data,
+	})
+}
+
+// GET /api/v1/projects/{key}/priorityEscalations
+func (h *ChangeController) GetPriorityEscalations(w http.ResponseWriter, r *http.Request) {
+	projectKey := mux.Vars(r)["key"]
+
+	data, err := h.service.GetPriorityEscalations(r.Context(), projectKey)
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusInternalServerError, fmt.Errorf("failed to get priority escalations: %w", err))
+		return
+	}
+
+	utils.WriteJSONResponse(w, map[string]interface{}{
+		"data": data,
+	})
+}
+
+// parsePagination читает параметры page и limit, по умолчанию 1 и 20
+func parsePagination(r *http.Request) (int, int, error) {
+	page, limit := 1, 20
+
+	if pageStr := r.URL.Query().Get("page"); pageStr != "" {
+		var err error
+		page, err = strconv.Atoi(pageStr)
+		if err != nil || page <= 0 {
+			return 0, 0, fmt.Errorf("invalid 'page' parameter: must be a positive integer")
+		}
+	}
+
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		var err error
+		limit, err = strconv.Atoi(limitStr)
+		if err != nil || limit <= 0 {
+			return 0, 0, fmt.Errorf("invalid 'limit' parameter: must be a positive integer")
+		}
+	}
+
+	return page, limit, nil
+}
@@ -0,0 +1,47 @@
+package controller
+
+import (
+	"fmt"
+	"github.com/gorilla/mux"
+	"jiraAnalyzer/backend/internal/service"
+	"jiraAnalyzer/backend/internal/utils"
+	"net/http"
+)
+
+type CommentController struct {
+	service *service.CommentService
+}
+
+func NewCommentController(service *service.CommentService) *CommentController {
+	return &CommentController{service: service}
+}
+
+// GET /api/v1/issues/{key}/comments
+func (h *CommentController) GetIssueComments(w http.ResponseWriter, r *http.Request) {
+	issueKey := mux.Vars(r)["key"]
+
+	comments, err := h.service.GetIssueComments(r.Context(), issueKey)
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusInternalServerError, fmt.Errorf("failed to get comments: %w", err))
+		return
+	}
+
+	utils.WriteJSONResponse(w, map[string]interface{}{
+		"comments": comments,
+	})
+}
+
+// GET /api/v1/projects/{key}/comments/stats
+func (h *CommentController) GetCommentStats(w http.ResponseWriter, r *http.Request) {
+	projectKey := mux.Vars(r)["key"]
+
+	stats, err := h.service.GetCommentStats(r.Context(), projectKey)
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusInternalServerError, fmt.Errorf("failed to get comment stats: %w", err))
+		return
+	}
+
+	utils.WriteJSONResponse(w, map[string]interface{}{
+		"data": stats,
+	})
+}
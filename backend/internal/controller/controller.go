@@ -9,15 +9,27 @@ import (
 type Controller struct {
 	*ProjectController
 	*IssueController
+	*AuthorController
+	*ChangeController
+	*CommentController
+	*WorklogController
+	*DependencyController
+	*SprintController
 	*AnalyticsController
 	*JiraController
 }
 
 func NewController(service *service.Service, logger *logrus.Logger, cfg config.Backend) *Controller {
 	return &Controller{
-		ProjectController:   NewProjectController(service.Projects),
-		IssueController:     NewIssueController(service.Issues),
-		AnalyticsController: NewAnalyticsController(service.Analytics, cfg),
-		JiraController:      NewJiraController(service.JiraClient),
+		ProjectController:    NewProjectController(service.Projects),
+		IssueController:      NewIssueController(service.Issues),
+		AuthorController:     NewAuthorController(service.Authors),
+		ChangeController:     NewChangeController(service.Changes),
+		CommentController:    NewCommentController(service.Comments),
+		WorklogController:    NewWorklogController(service.Worklogs),
+		DependencyController: NewDependencyController(service.Dependencies),
+		SprintController:     NewSprintController(service.Sprints),
+		AnalyticsController:  NewAnalyticsController(service.Analytics, cfg),
+		JiraController:       NewJiraController(service.JiraClient),
 	}
 }
@@ -0,0 +1,90 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"github.com/gorilla/mux"
+	"jiraAnalyzer/backend/internal/models"
+	"jiraAnalyzer/backend/internal/service"
+	"jiraAnalyzer/backend/internal/utils"
+	"net/http"
+	"strconv"
+)
+
+const (
+	defaultGraphDepth = 2
+	maxGraphDepth     = 5
+)
+
+type DependencyController struct {
+	service *service.DependencyService
+}
+
+func NewDependencyController(service *service.DependencyService) *DependencyController {
+	return &DependencyController{service: service}
+}
+
+// GET /api/v1/issues/{key}/dependencies?depth=2
+func (h *DependencyController) GetDependencyGraph(w http.ResponseWriter, r *http.Request) {
+	issueKey := mux.Vars(r)["key"]
+
+	depth := defaultGraphDepth
+	if depthStr := r.URL.Query().Get("depth"); depthStr != "" {
+		var err error
+		depth, err = strconv.Atoi(depthStr)
+		if err != nil || depth <= 0 || depth > maxGraphDepth {
+			utils.WriteErrorResponse(w, http.StatusBadRequest, fmt.Errorf("invalid 'depth' parameter: must be between 1 and %d", maxGraphDepth))
+			return
+		}
+	}
+
+	graph, err := h.service.GetDependencyGraph(r.Context(), issueKey, depth)
+	if err != nil {
+		var notFoundErr *models.NotFoundError
+		if errors.As(err, &notFoundErr) {
+			utils.WriteErrorResponse(w, http.StatusNotFound, err)
+			return
+		}
+		utils.WriteErrorResponse(w, http.StatusInternalServerError, fmt.Errorf("failed to get dependency graph: %w", err))
+		return
+	}
+
+	utils.WriteJSONResponse(w, map[string]interface{}{
+		"data": graph,
+	})
+}
+
+// GET /api/v1/projects/{key}/dependencies/cycles?type=Blocks
+func (h *DependencyController) GetDependencyCycles(w http.ResponseWriter, r *http.Request) {
+	projectKey := mux.Vars(r)["key"]
+
+	linkType := r.URL.Query().Get("type")
+	if linkType == "" {
+		linkType = service.BlocksLinkType
+	}
+
+	cycles, err := h.service.FindCycles(r.Context(), projectKey, linkType)
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusInternalServerError, fmt.Errorf("failed to find dependency cycles: %w", err))
+		return
+	}
+
+	utils.WriteJSONResponse(w, map[string]interface{}{
+		"cycles": cycles,
+	})
+}
+
+// GET /api/v1/projects/{key}/dependencies/longestChain
+func (h *DependencyController) GetLongestBlockingChain(w http.ResponseWriter, r *http.Request) {
+	projectKey := mux.Vars(r)["key"]
+
+	chain, err := h.service.GetLongestBlockingChain(r.Context(), projectKey)
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusInternalServerError, fmt.Errorf("failed to get blocking chain: %w", err))
+		return
+	}
+
+	utils.WriteJSONResponse(w, map[string]interface{}{
+		"data": chain,
+	})
+}
@@ -45,12 +45,18 @@ func (h *JiraController) UpdateConnectorProject(w http.ResponseWriter, r *http.R
 	}
 
 	projectKeys := strings.Split(projectKeysParam, ",")
-	err := h.service.UpdateConnectorProject(r.Context(), projectKeys)
+	jobID, err := h.service.UpdateConnectorProject(r.Context(), projectKeys)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	w.WriteHeader(http.StatusOK)
-	w.Write([]byte(`{"status": "success"}`))
+	response := map[string]interface{}{
+		"status": "accepted",
+		"jobId":  jobID,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(response)
 }
@@ -0,0 +1,63 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"github.com/gorilla/mux"
+	"jiraAnalyzer/backend/internal/models"
+	"jiraAnalyzer/backend/internal/service"
+	"jiraAnalyzer/backend/internal/utils"
+	"net/http"
+	"strconv"
+)
+
+type SprintController struct {
+	service *service.SprintService
+}
+
+func NewSprintController(service *service.SprintService) *SprintController {
+	return &SprintController{service: service}
+}
+
+// GET /api/v1/projects/{key}/sprints
+func (h *SprintController) GetProjectSprints(w http.ResponseWriter, r *http.Request) {
+	projectKey := mux.Vars(r)["key"]
+
+	sprints, err := h.service.GetProjectSprints(r.Context(), projectKey)
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusInternalServerError, fmt.Errorf("failed to get sprints: %w", err))
+		return
+	}
+
+	utils.WriteJSONResponse(w, map[string]interface{}{
+		"sprints": sprints,
+	})
+}
+
+// GET /api/v1/sprints/{id}/report
+func (h *SprintController) GetSprintReport(w http.ResponseWriter, r *http.Request) {
+	sprintID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, fmt.Errorf("invalid sprint id"))
+		return
+	}
+
+	report, err := h.service.GetSprintReport(r.Context(), sprintID)
+	if err != nil {
+		var notFoundErr *models.NotFoundError
+		var invalidInputErr *models.InvalidInputError
+		switch {
+		case errors.As(err, &notFoundErr):
+			utils.WriteErrorResponse(w, http.StatusNotFound, err)
+		case errors.As(err, &invalidInputErr):
+			utils.WriteErrorResponse(w, http.StatusBadRequest, err)
+		default:
+			utils.WriteErrorResponse(w, http.StatusInternalServerError, fmt.Errorf("failed to build sprint report: %w", err))
+		}
+		return
+	}
+
+	utils.WriteJSONResponse(w, map[string]interface{}{
+		"data": report,
+	})
+}
@@ -0,0 +1,88 @@
+package controller
+
+import (
+	"fmt"
+	"github.com/gorilla/mux"
+	"jiraAnalyzer/backend/internal/service"
+	"jiraAnalyzer/backend/internal/utils"
+	"net/http"
+	"time"
+)
+
+type WorklogController struct {
+	service *service.WorklogService
+}
+
+func NewWorklogController(service *service.WorklogService) *WorklogController {
+	return &WorklogController{service: service}
+}
+
+// GET /api/v1/projects/{key}/worklogs/weekly?from=2024-01-01&to=2024-04-01
+func (h *WorklogController) GetWeeklyAuthorHours(w http.ResponseWriter, r *http.Request) {
+	projectKey := mux.Vars(r)["key"]
+
+	from, to, err := parseDateRange(r)
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, err)
+		return
+	}
+
+	data, err := h.service.GetWeeklyAuthorHours(r.Context(), projectKey, from, to)
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusInternalServerError, fmt.Errorf("failed to get weekly hours: %w", err))
+		return
+	}
+
+	utils.WriteJSONResponse(w, map[string]interface{}{
+		"data": data,
+	})
+}
+
+// GET /api/v1/projects/{key}/worklogs/issueTypes?from=2024-01-01&to=2024-04-01
+func (h *WorklogController) GetIssueTypeHours(w http.ResponseWriter, r *http.Request) {
+	projectKey := mux.Vars(r)["key"]
+
+	from, to, err := parseDateRange(r)
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusBadRequest, err)
+		return
+	}
+
+	data, err := h.service.GetIssueTypeHours(r.Context(), projectKey, from, to)
+	if err != nil {
+		utils.WriteErrorResponse(w, http.StatusInternalServerError, fmt.Errorf("failed to get issue type hours: %w", err))
+		return
+	}
+
+	utils.WriteJSONResponse(w, map[string]interface{}{
+		"data": data,
+	})
+}
+
+// parseDateRange читает необязательные параметры from и to в формате YYYY-MM-DD.
+// Граница to не включается.
+func parseDateRange(r *http.Request) (*time.Time, *time.Time, error) {
+	var from, to *time.Time
+
+	if fromStr := r.URL.Query().Get("from"); fromStr != "" {
+		t, err := time.Parse(time.DateOnly, fromStr)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid 'from' parameter: expected YYYY-MM-DD")
+		}
+		from = &t
+	}
+
+	if toStr := r.URL.Query().Get("to"); toStr != "" {
+		t, err := time.Parse(time.DateOnly, toStr)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid 'to' parameter: expected YYYY-MM-DD")
+		}
+		to = &t
+	}
+
+	if from != nil && to != nil && !from.Before(*to) {
+		return nil, nil, fmt.Errorf("'from' must be before 'to'")
+	}
+
+	return from, to, nil
+}
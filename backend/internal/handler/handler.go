@@ -10,6 +10,12 @@ func NewHandler(controllers *controller.Controller, r *mux.Router) *mux.Router {
 	setProjectRoute(controllers.ProjectController, r)
 	setAnalyticRoute(controllers.AnalyticsController, r)
 	setIssueRoute(controllers.IssueController, r)
+	setAuthorRoute(controllers.AuthorController, r)
+	setChangeRoute(controllers.ChangeController, r)
+	setCommentRoute(controllers.CommentController, r)
+	setWorklogRoute(controllers.WorklogController, r)
+	setDependencyRoute(controllers.DependencyController, r)
+	setSprintRoute(controllers.SprintController, r)
 	setConnectorRoute(controllers.JiraController, r)
 
 	return r
@@ -22,6 +28,7 @@ func setAnalyticRoute(ac *controller.AnalyticsController, r *mux.Router) {
 	r.HandleFunc("/api/v1/graph/get/{taskNumber:[0-9]+}", ac.GetGraph).Methods(http.MethodOptions, http.MethodGet)
 	r.HandleFunc("/api/v1/graph/make/{taskNumber:[0-9]}", ac.MakeGraph).Methods(http.MethodOptions, http.MethodPost)
 	r.HandleFunc("/api/v1/compare/{taskNumber:[0-9]+}", ac.GetComparison).Methods(http.MethodOptions, http.MethodGet)
+	r.HandleFunc("/api/v1/projects/{key}/customFields/{field}/groups", ac.GroupByCustomField).Methods(http.MethodOptions, http.MethodGet)
 }
 
 func setProjectRoute(pc *controller.ProjectController, r *mux.Router) {
@@ -40,6 +47,39 @@ func setIssueRoute(ic *controller.IssueController, r *mux.Router) {
 	r.HandleFunc("/api/v1/issues/{id}", ic.DeleteIssue).Methods(http.MethodOptions, http.MethodDelete)
 }
 
+func setAuthorRoute(ac *controller.AuthorController, r *mux.Router) {
+	r.HandleFunc("/api/v1/authors/{id:[0-9]+}", ac.GetAuthor).Methods(http.MethodOptions, http.MethodGet)
+	r.HandleFunc("/api/v1/authors/{id:[0-9]+}/merge", ac.MergeAuthors).Methods(http.MethodOptions, http.MethodPost)
+}
+
+func setChangeRoute(cc *controller.ChangeController, r *mux.Router) {
+	r.HandleFunc("/api/v1/issues/{key}/changes", cc.GetIssueFieldChanges).Methods(http.MethodOptions, http.MethodGet)
+	r.HandleFunc("/api/v1/projects/{key}/changes", cc.GetProjectFieldChanges).Methods(http.MethodOptions, http.MethodGet)
+	r.HandleFunc("/api/v1/projects/{key}/reassignments", cc.GetReassignments).Methods(http.MethodOptions, http.MethodGet)
+	r.HandleFunc("/api/v1/projects/{key}/priorityEscalations", cc.GetPriorityEscalations).Methods(http.MethodOptions, http.MethodGet)
+}
+
+func setCommentRoute(cc *controller.CommentController, r *mux.Router) {
+	r.HandleFunc("/api/v1/issues/{key}/comments", cc.GetIssueComments).Methods(http.MethodOptions, http.MethodGet)
+	r.HandleFunc("/api/v1/projects/{key}/comments/stats", cc.GetCommentStats).Methods(http.MethodOptions, http.MethodGet)
+}
+
+func setWorklogRoute(wc *controller.WorklogController, r *mux.Router) {
+	r.HandleFunc("/api/v1/projects/{key}/worklogs/weekly", wc.GetWeeklyAuthorHours).Methods(http.MethodOptions, http.MethodGet)
+	r.HandleFunc("/api/v1/projects/{key}/worklogs/issueTypes", wc.GetIssueTypeHours).Methods(http.MethodOptions, http.MethodGet)
+}
+
+func setDependencyRoute(dc *controller.DependencyController, r *mux.Router) {
+	r.HandleFunc("/api/v1/issues/{key}/dependencies", dc.GetDependencyGraph).Methods(http.MethodOptions, http.MethodGet)
+	r.HandleFunc("/api/v1/projects/{key}/dependencies/cycles", dc.GetDependencyCycles).Methods(http.MethodOptions, http.MethodGet)
+	r.HandleFunc("/api/v1/projects/{key}/dependencies/longestChain", dc.GetLongestBlockingChain).Methods(http.MethodOptions, http.MethodGet)
+}
+
+func setSprintRoute(sc *controller.SprintController, r *mux.Router) {
+	r.HandleFunc("/api/v1/projects/{key}/sprints", sc.GetProjectSprints).Methods(http.MethodOptions, http.MethodGet)
+	r.HandleFunc("/api/v1/sprints/{id:[0-9]+}/report", sc.GetSprintReport).Methods(http.MethodOptions, http.MethodGet)
+}
+
 func setConnectorRoute(jc *controller.JiraController, r *mux.Router) {
 	r.HandleFunc("/api/v1/connector/updateProject", jc.UpdateConnectorProject).Methods(http.MethodOptions, http.MethodPost)
 	r.HandleFunc("/api/v1/connector/projects", jc.GetConnectorProjects).Methods(http.MethodOptions, http.MethodGet)
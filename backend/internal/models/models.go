@@ -30,6 +30,10 @@ type Issue struct {
 	TimeSpent   int        `json:"time_spent" db:"time_spent"`
 	CreatorID   int        `json:"creator_id" db:"creator_id"`
 	AssigneeID  *int       `json:"assignee_id,omitempty" db:"assignee_id"` // Может быть NULL
+	// DeletedAt - когда задача не найдена в Jira при полной синхронизации
+	DeletedAt *time.Time `json:"deleted_at,omitempty" db:"deleted_at"`
+	// MovedTo - новый ключ задачи, перенесённой в другой проект
+	MovedTo *string `json:"moved_to,omitempty" db:"moved_to"`
 }
 
 type StatusChange struct {
@@ -42,8 +46,15 @@ type StatusChange struct {
 }
 
 type Author struct {
-	ID          string `json:"id"`
-	DisplayName string `json:"displayName"`
+	ID          int     `json:"id" db:"id"`
+	AccountID   string  `json:"accountId" db:"account_id"`
+	DisplayName string  `json:"displayName" db:"display_name"`
+	Email       *string `json:"email,omitempty" db:"email"`
+	Active      bool    `json:"active" db:"active"`
+	// MergedInto - id автора, с которым объединён этот дубликат
+	MergedInto *int `json:"mergedInto,omitempty" db:"merged_into"`
+	// DisplayNames - все отображаемые имена автора в порядке появления
+	DisplayNames []string `json:"displayNames" db:"-"`
 }
 
 type ProjectAnalytics struct {
@@ -92,3 +103,158 @@ type ComparisonProjects struct {
 	ClosedTasks       int     `db:"closed_tasks"`
 	AverageTimeIssues float64 `db:"average_time_issues"`
 }
+
+// FieldChange - изменение поля задачи из истории Jira.
+type FieldChange struct {
+	IssueID    string    `json:"issue_id" db:"issue_id"`
+	AuthorID   *int      `json:"author_id,omitempty" db:"author_id"`
+	Created    time.Time `json:"created" db:"created"`
+	Field      string    `json:"field" db:"field"`
+	FromValue  *string   `json:"from_value,omitempty" db:"from_value"`
+	FromString *string   `json:"from_string,omitempty" db:"from_string"`
+	ToValue    *string   `json:"to_value,omitempty" db:"to_value"`
+	ToString   *string   `json:"to_string,omitempty" db:"to_string"`
+}
+
+// ReassignmentData - количество переназначений задачи на другого исполнителя.
+type ReassignmentData struct {
+	IssueKey      string `json:"issue_key" db:"issue_key"`
+	Reassignments int    `json:"reassignments" db:"reassignments"`
+}
+
+// PriorityEscalation - повышение приоритета задачи.
+type PriorityEscalation struct {
+	IssueKey     string    `json:"issue_key" db:"issue_key"`
+	AuthorID     *int      `json:"author_id,omitempty" db:"author_id"`
+	Created      time.Time `json:"created" db:"created"`
+	FromPriority string    `json:"from_priority" db:"from_priority"`
+	ToPriority   string    `json:"to_priority" db:"to_priority"`
+}
+
+type Comment struct {
+	JiraID     string    `json:"jira_id" db:"jira_id"`
+	IssueID    string    `json:"issue_id" db:"issue_id"`
+	AuthorID   *int      `json:"author_id,omitempty" db:"author_id"`
+	AuthorName *string   `json:"author_name,omitempty" db:"author_name"`
+	Created    time.Time `json:"created" db:"created"`
+	Updated    time.Time `json:"updated" db:"updated"`
+	Body       string    `json:"body" db:"body"`
+}
+
+// CommentStats - статистика обсуждений по проекту. Время первого ответа -
+// время от создания задачи до первого комментария не от её автора.
+type CommentStats struct {
+	TotalIssues              int     `json:"total_issues" db:"total_issues"`
+	TotalComments            int     `json:"total_comments" db:"total_comments"`
+	CommentedIssues          int     `json:"commented_issues" db:"commented_issues"`
+	AverageCommentsPerIssue  float64 `json:"average_comments_per_issue" db:"-"`
+	AnsweredIssues           int     `json:"answered_issues" db:"answered_issues"`
+	AverageFirstResponseTime float64 `json:"average_first_response_hours" db:"average_first_response_hours"`
+	MedianFirstResponseTime  float64 `json:"median_first_response_hours" db:"median_first_response_hours"`
+}
+
+// AuthorWeekHours - часы, списанные автором за неделю. Week - понедельник недели.
+type AuthorWeekHours struct {
+	AuthorID   int       `json:"author_id" db:"author_id"`
+	AuthorName string    `json:"author_name" db:"author_name"`
+	Week       time.Time `json:"week" db:"week"`
+	Hours      float64   `json:"hours" db:"hours"`
+}
+
+// IssueTypeHours - часы, списанные на задачи одного типа.
+type IssueTypeHours struct {
+	IssueType string  `json:"issue_type" db:"issue_type"`
+	Issues    int     `json:"issues" db:"issues"`
+	Hours     float64 `json:"hours" db:"hours"`
+}
+
+// IssueLink - ребро графа связей: SourceKey <Outward> TargetKey, например "A blocks B".
+type IssueLink struct {
+	JiraID    string `json:"jira_id" db:"jira_id"`
+	SourceKey string `json:"source_key" db:"source_key"`
+	TargetKey string `json:"target_key" db:"target_key"`
+	LinkType  string `json:"link_type" db:"link_type"`
+	Outward   string `json:"outward" db:"outward"`
+	Inward    string `json:"inward" db:"inward"`
+}
+
+// GraphNode - задача в графе связей. Known = false, если задачи нет в базе,
+// например она из проекта, который не синхронизировался.
+type GraphNode struct {
+	Key        string  `json:"key" db:"key"`
+	ProjectKey *string `json:"project_key,omitempty" db:"project_key"`
+	Summary    *string `json:"summary,omitempty" db:"summary"`
+	Status     *string `json:"status,omitempty" db:"status"`
+	Open       bool    `json:"open" db:"open"`
+	Known      bool    `json:"known" db:"-"`
+}
+
+type DependencyGraph struct {
+	Root  string      `json:"root"`
+	Depth int         `json:"depth"`
+	Nodes []GraphNode `json:"nodes"`
+	Edges []IssueLink `json:"edges"`
+}
+
+// BlockingChain - самая длинная цепочка открытых задач, где каждая блокирует следующую.
+type BlockingChain struct {
+	Length int         `json:"length"`
+	Issues []GraphNode `json:"issues"`
+}
+
+type Sprint struct {
+	ID           int        `json:"id" db:"id"`
+	BoardID      int        `json:"board_id" db:"board_id"`
+	ProjectKey   string     `json:"project_key" db:"project_key"`
+	Name         string     `json:"name" db:"name"`
+	State        string     `json:"state" db:"state"`
+	Goal         *string    `json:"goal,omitempty" db:"goal"`
+	StartDate    *time.Time `json:"start_date,omitempty" db:"start_date"`
+	EndDate      *time.Time `json:"end_date,omitempty" db:"end_date"`
+	CompleteDate *time.Time `json:"complete_date,omitempty" db:"complete_date"`
+}
+
+// IssueResolution - время закрытия задачи, nil для открытых задач.
+type IssueResolution struct {
+	Key    string     `db:"key"`
+	Closed *time.Time `db:"closed"`
+}
+
+// SprintReport - отчёт по спринту. Committed - задачи в спринте на момент
+// старта, Added - добавленные после старта, Removed - убранные до окончания,
+// CarriedOver - незавершённые к окончанию, из них MovedToNext - попавшие
+// в следующий спринт доски.
+type SprintReport struct {
+	Sprint       Sprint   `json:"sprint"`
+	NextSprintID *int     `json:"next_sprint_id,omitempty"`
+	Committed    []string `json:"committed"`
+	Completed    []string `json:"completed"`
+	Added        []string `json:"added"`
+	Removed      []string `json:"removed"`
+	CarriedOver  []string `json:"carried_over"`
+	MovedToNext  []string `json:"moved_to_next"`
+}
+
+// IssueFilter ограничивает набор задач, по которым строится аналитика.
+// CustomFields - значения пользовательских полей по логическим именам,
+// для полей-списков значение должно входить в список. Component, Label
+// и FixVersion - имена, пустое значение фильтр не ограничивает.
+type IssueFilter struct {
+	CustomFields map[string]string `json:"customFields,omitempty"`
+	Component    string            `json:"component,omitempty"`
+	Label        string            `json:"label,omitempty"`
+	FixVersion   string            `json:"fixVersion,omitempty"`
+}
+
+func (f IssueFilter) IsEmpty() bool {
+	return len(f.CustomFields) == 0 && f.Component == "" && f.Label == "" && f.FixVersion == ""
+}
+
+// CustomFieldGroup - сводка по задачам с одним значением пользовательского поля.
+// Value = nil - задачи, у которых поле не заполнено.
+type CustomFieldGroup struct {
+	Value              *string `json:"value" db:"value"`
+	TaskCount          int     `json:"task_count" db:"task_count"`
+	ClosedCount        int     `json:"closed_count" db:"closed_count"`
+	AvgResolutionHours float64 `json:"avg_resolution_hours" db:"avg_resolution_hours"`
+}
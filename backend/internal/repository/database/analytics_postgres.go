@@ -16,21 +16,26 @@ func NewAnalyticsPostgres(db *sqlx.DB) *AnalyticsPostgres {
 	return &AnalyticsPostgres{db: db}
 }
 
-func (r *AnalyticsPostgres) GetProjectAnalytics(ctx context.Context, projectKey string) (models.ProjectAnalytics, error) {
+func (r *AnalyticsPostgres) GetProjectAnalytics(ctx context.Context, projectKey string, filter models.IssueFilter) (models.ProjectAnalytics, error) {
 	var analytics models.ProjectAnalytics
 
+	filterParam, err := issueFilterParam(filter)
+	if err != nil {
+		return analytics, err
+	}
+
 	// Общее количество задач
-	err := r.db.GetContext(ctx, &analytics.TotalIssues, `
-        SELECT COUNT(*) FROM issues WHERE project_key = $1
-    `, projectKey)
+	err = r.db.GetContext(ctx, &analytics.TotalIssues, `
+        SELECT COUNT(*) FROM issues WHERE project_key = $1 AND `+issueFilterCondition("issues", 2)+`
+    `, projectKey, filterParam)
 	if err != nil {
 		return analytics, fmt.Errorf("failed to get total issues: %w", err)
 	}
 
 	// Количество закрытых задач
 	err = r.db.GetContext(ctx, &analytics.ClosedIssues, `
-        SELECT COUNT(*) FROM issues WHERE project_key = $1 AND status = 'Closed'
-    `, projectKey)
+        SELECT COUNT(*) FROM issues WHERE project_key = $1 AND status = 'Closed' AND `+issueFilterCondition("issues", 2)+`
+    `, projectKey, filterParam)
 	if err != nil {
 		return analytics, fmt.Errorf("failed to get closed issues: %w", err)
 	}
@@ -39,8 +44,8 @@ func (r *AnalyticsPostgres) GetProjectAnalytics(ctx context.Context, projectKey
 	err = r.db.GetContext(ctx, &analytics.OpenIssues, `
         SELECT COUNT(*) 
         FROM issues 
-        WHERE project_key = $1 AND status = 'Open'
-    `, projectKey)
+        WHERE project_key = $1 AND status = 'Open' AND `+issueFilterCondition("issues", 2)+`
+    `, projectKey, filterParam)
 	if err != nil {
 		return analytics, fmt.Errorf("failed to fetch open issues count: %w", err)
 	}
@@ -49,10 +54,10 @@ func (r *AnalyticsPostgres) GetProjectAnalytics(ctx context.Context, projectKey
 	err = r.db.GetContext(ctx, &analytics.ReopenIssues, `
 		SELECT COUNT(DISTINCT issue_id) AS reopened_tasks 
 		FROM status_changes WHERE issue_id IN (
-    	SELECT key FROM issues WHERE project_key = $1
+    	SELECT key FROM issues WHERE project_key = $1 AND `+issueFilterCondition("issues", 2)+`
     	) AND from_status IN ('Closed', 'Resolved')
 		AND to_status NOT IN ('Closed', 'Resolved')
-		`, projectKey)
+		`, projectKey, filterParam)
 	if err != nil {
 		return analytics, fmt.Errorf("failed to get reopen issues count: %w", err)
 	}
@@ -60,16 +65,16 @@ func (r *AnalyticsPostgres) GetProjectAnalytics(ctx context.Context, projectKey
 	// Количество разрешенных задач
 	err = r.db.GetContext(ctx, &analytics.ResolvedIssues, `SELECT COUNT(*) AS resolved_tasks
 		FROM issues
-		WHERE project_key = $1 AND status = 'Resolved'
-		`, projectKey)
+		WHERE project_key = $1 AND status = 'Resolved' AND `+issueFilterCondition("issues", 2)+`
+		`, projectKey, filterParam)
 	if err != nil {
 		return analytics, fmt.Errorf("failed to get resolved issues count: %w", err)
 	}
 
 	err = r.db.GetContext(ctx, &analytics.InProgressIssues, `SELECT COUNT(*) AS in_progress_tasks
 		FROM issues
-		WHERE project_key = $1 AND status = 'In Progress'
-		`, projectKey)
+		WHERE project_key = $1 AND status = 'In Progress' AND `+issueFilterCondition("issues", 2)+`
+		`, projectKey, filterParam)
 	if err != nil {
 		return analytics, fmt.Errorf("failed to get in_progress issues count: %w", err)
 	}
@@ -79,7 +84,7 @@ func (r *AnalyticsPostgres) GetProjectAnalytics(ctx context.Context, projectKey
 		SELECT COALESCE(AVG(EXTRACT(EPOCH FROM (closed - created)) / 3600), 0)
     	AS avg_completion_time_hours
 		FROM issues
-		WHERE project_key = $1 AND closed IS NOT NULL`, projectKey)
+		WHERE project_key = $1 AND closed IS NOT NULL AND `+issueFilterCondition("issues", 2), projectKey, filterParam)
 	if err != nil {
 		return analytics, fmt.Errorf("failed to get average time issues: %w", err)
 	}
@@ -89,9 +94,9 @@ func (r *AnalyticsPostgres) GetProjectAnalytics(ctx context.Context, projectKey
 		SELECT COUNT(*) / 7 
     	AS avg_tasks_per_day_last_week
 		FROM issues
-		WHERE project_key = $1
+		WHERE project_key = $1 AND `+issueFilterCondition("issues", 2)+`
 		AND created >= NOW() - INTERVAL '7 days'
-		`, projectKey)
+		`, projectKey, filterParam)
 	if err != nil {
 		return analytics, fmt.Errorf("failed to get average issues count in last week: %w", err)
 	}
@@ -146,12 +151,17 @@ func (r *AnalyticsPostgres) DeleteProjectAnalytics(ctx context.Context, projectK
 	return nil
 }
 
-func (r *AnalyticsPostgres) CalculateOpenTimeHistogram(ctx context.Context, projectKey string) ([]models.HistogramData, error) {
+func (r *AnalyticsPostgres) CalculateOpenTimeHistogram(ctx context.Context, projectKey string, filter models.IssueFilter) ([]models.HistogramData, error) {
+	filterParam, err := issueFilterParam(filter)
+	if err != nil {
+		return nil, err
+	}
+
 	query := `
     WITH task_durations AS (
     	SELECT EXTRACT(EPOCH FROM (closed - created)) / 3600 AS duration_hours
     	FROM issues
-    	WHERE project_key = $1 AND closed IS NOT NULL
+    	WHERE project_key = $1 AND closed IS NOT NULL AND ` + issueFilterCondition("issues", 2) + `
 	)
 	SELECT 
     	FLOOR(duration_hours / 24) AS day_interval,
@@ -162,14 +172,19 @@ func (r *AnalyticsPostgres) CalculateOpenTimeHistogram(ctx context.Context, proj
     `
 
 	var histogram []models.HistogramData
-	err := r.db.SelectContext(ctx, &histogram, query, projectKey)
+	err = r.db.SelectContext(ctx, &histogram, query, projectKey, filterParam)
 	if err != nil {
 		return nil, fmt.Errorf("failed to calculate open time histogram: %w", err)
 	}
 	return histogram, nil
 }
 
-func (r *AnalyticsPostgres) CalculateStatusTimeDistribution(ctx context.Context, projectKey string) ([]models.StatusTimeData, error) {
+func (r *AnalyticsPostgres) CalculateStatusTimeDistribution(ctx context.Context, projectKey string, filter models.IssueFilter) ([]models.StatusTimeData, error) {
+	filterParam, err := issueFilterParam(filter)
+	if err != nil {
+		return nil, err
+	}
+
 	query := `
     WITH status_durations AS (
     	SELECT 
@@ -178,7 +193,7 @@ func (r *AnalyticsPostgres) CalculateStatusTimeDistribution(ctx context.Context,
         	to_status,
         	EXTRACT(EPOCH FROM (created - LAG(created) OVER (PARTITION BY issue_id ORDER BY created))) / 3600 AS duration_hours
     	FROM status_changes
-    	WHERE issue_id IN (SELECT key FROM issues WHERE project_key = $1)
+    	WHERE issue_id IN (SELECT key FROM issues WHERE project_key = $1 AND ` + issueFilterCondition("issues", 2) + `)
 	)
 	SELECT 
     	from_status AS status,
@@ -191,14 +206,19 @@ func (r *AnalyticsPostgres) CalculateStatusTimeDistribution(ctx context.Context,
     `
 
 	var distribution []models.StatusTimeData
-	err := r.db.SelectContext(ctx, &distribution, query, projectKey)
+	err = r.db.SelectContext(ctx, &distribution, query, projectKey, filterParam)
 	if err != nil {
 		return nil, fmt.Errorf("failed to calculate status time distribution: %w", err)
 	}
 	return distribution, nil
 }
 
-func (r *AnalyticsPostgres) CalculateActivityGraph(ctx context.Context, projectKey string) ([]models.ActivityData, error) {
+func (r *AnalyticsPostgres) CalculateActivityGraph(ctx context.Context, projectKey string, filter models.IssueFilter) ([]models.ActivityData, error) {
+	filterParam, err := issueFilterParam(filter)
+	if err != nil {
+		return nil, err
+	}
+
 	query := `
     WITH daily_stats AS (
     	SELECT 
@@ -206,7 +226,7 @@ func (r *AnalyticsPostgres) CalculateActivityGraph(ctx context.Context, projectK
         	COUNT(*) FILTER (WHERE status = 'Open') AS opened_tasks,
         	COUNT(*) FILTER (WHERE status = 'Closed') AS closed_tasks
     	FROM issues
-    	WHERE project_key = $1
+    	WHERE project_key = $1 AND ` + issueFilterCondition("issues", 2) + `
     	GROUP BY day
 	)
 	SELECT 
@@ -218,23 +238,28 @@ func (r *AnalyticsPostgres) CalculateActivityGraph(ctx context.Context, projectK
     `
 
 	var activity []models.ActivityData
-	err := r.db.SelectContext(ctx, &activity, query, projectKey)
+	err = r.db.SelectContext(ctx, &activity, query, projectKey, filterParam)
 	if err != nil {
 		return nil, fmt.Errorf("failed to calculate activity graph: %w", err)
 	}
 	return activity, nil
 }
 
-func (r *AnalyticsPostgres) CalculateComplexityGraph(ctx context.Context, projectKey string) ([]models.ComplexityData, error) {
+func (r *AnalyticsPostgres) CalculateComplexityGraph(ctx context.Context, projectKey string, filter models.IssueFilter) ([]models.ComplexityData, error) {
+	filterParam, err := issueFilterParam(filter)
+	if err != nil {
+		return nil, err
+	}
+
 	query := `
         SELECT FLOOR(EXTRACT(EPOCH FROM (closed - created)) / 3600) AS time_spent_hours, COUNT(*) AS task_count
         FROM issues
-        WHERE project_key = $1 AND closed IS NOT NULL
+        WHERE project_key = $1 AND closed IS NOT NULL AND ` + issueFilterCondition("issues", 2) + `
         GROUP BY time_spent_hours
         ORDER BY time_spent_hours
     `
 	var data []models.ComplexityData
-	err := r.db.SelectContext(ctx, &data, query, projectKey)
+	err = r.db.SelectContext(ctx, &data, query, projectKey, filterParam)
 	if err != nil {
 		return nil, fmt.Errorf("failed to calculate complexity graph: %w", err)
 	}
@@ -242,18 +267,23 @@ func (r *AnalyticsPostgres) CalculateComplexityGraph(ctx context.Context, projec
 	return data, nil
 }
 
-func (r *AnalyticsPostgres) CalculatePriorityDistribution(ctx context.Context, projectKey string) ([]models.PriorityData, error) {
+func (r *AnalyticsPostgres) CalculatePriorityDistribution(ctx context.Context, projectKey string, filter models.IssueFilter) ([]models.PriorityData, error) {
+	filterParam, err := issueFilterParam(filter)
+	if err != nil {
+		return nil, err
+	}
+
 	query := `
         SELECT 
             priority, COUNT(*) AS task_count
         FROM issues
-        WHERE project_key = $1
+        WHERE project_key = $1 AND ` + issueFilterCondition("issues", 2) + `
         GROUP BY priority
         ORDER BY task_count DESC;
     `
 
 	var distribution []models.PriorityData
-	err := r.db.SelectContext(ctx, &distribution, query, projectKey)
+	err = r.db.SelectContext(ctx, &distribution, query, projectKey, filterParam)
 	if err != nil {
 		return nil, fmt.Errorf("failed to calculate priority distribution: %w", err)
 	}
@@ -261,18 +291,58 @@ func (r *AnalyticsPostgres) CalculatePriorityDistribution(ctx context.Context, p
 	return distribution, nil
 }
 
-func (r *AnalyticsPostgres) CalculatePriorityDistributionClosedTasks(ctx context.Context, projectKey string) ([]models.PriorityData, error) {
+func (r *AnalyticsPostgres) CalculatePriorityDistributionClosedTasks(ctx context.Context, projectKey string, filter models.IssueFilter) ([]models.PriorityData, error) {
+	filterParam, err := issueFilterParam(filter)
+	if err != nil {
+		return nil, err
+	}
+
 	query := `
         SELECT priority, COUNT(*) AS task_count
         FROM issues
-        WHERE project_key = $1 AND closed IS NOT NULL
+        WHERE project_key = $1 AND closed IS NOT NULL AND ` + issueFilterCondition("issues", 2) + `
         GROUP BY priority
         ORDER BY task_count DESC
     `
 	var data []models.PriorityData
-	err := r.db.SelectContext(ctx, &data, query, projectKey)
+	err = r.db.SelectContext(ctx, &data, query, projectKey, filterParam)
 	if err != nil {
 		return nil, fmt.Errorf("failed to calculate priority distribution for closed tasks: %w", err)
 	}
 	return data, nil
 }
+
+// GroupByCustomField группирует задачи проекта по значению пользовательского поля.
+// Задача с полем-списком попадает в группу каждого элемента списка.
+func (r *AnalyticsPostgres) GroupByCustomField(ctx context.Context, projectKey, field string, filter models.IssueFilter) ([]models.CustomFieldGroup, error) {
+	filterParam, err := issueFilterParam(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `
+        SELECT
+            v.value,
+            COUNT(*) AS task_count,
+            COUNT(*) FILTER (WHERE i.closed IS NOT NULL) AS closed_count,
+            COALESCE(AVG(EXTRACT(EPOCH FROM (i.closed - i.created)) / 3600), 0) AS avg_resolution_hours
+        FROM issues i
+        CROSS JOIN LATERAL (
+            SELECT jsonb_array_elements_text(i.custom_fields -> $3::text) AS value
+            WHERE jsonb_typeof(i.custom_fields -> $3::text) = 'array'
+            UNION ALL
+            SELECT i.custom_fields ->> $3::text
+            WHERE jsonb_typeof(i.custom_fields -> $3::text) IS DISTINCT FROM 'array'
+        ) v
+        WHERE i.project_key = $1 AND ` + issueFilterCondition("i", 2) + `
+        GROUP BY v.value
+        ORDER BY task_count DESC, v.value
+    `
+
+	var groups []models.CustomFieldGroup
+	err = r.db.SelectContext(ctx, &groups, query, projectKey, filterParam, field)
+	if err != nil {
+		return nil, fmt.Errorf("failed to group issues by custom field: %w", err)
+	}
+	return groups, nil
+}
@@ -2,6 +2,8 @@ package database
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
 	"github.com/jmoiron/sqlx"
 	"jiraAnalyzer/backend/internal/models"
@@ -17,20 +19,88 @@ func NewAuthorPostgres(db *sqlx.DB) *AuthorPostgres {
 
 func (r *AuthorPostgres) GetAuthorById(ctx context.Context, id int) (models.Author, error) {
 	var author models.Author
-	query := "SELECT id, display_name FROM authors WHERE id = $1"
+	query := "SELECT id, account_id, display_name, email, active, merged_into FROM authors WHERE id = $1"
 	err := r.db.GetContext(ctx, &author, query, id)
-	if err != nil {
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.Author{}, &models.NotFoundError{Message: fmt.Sprintf("author %d", id)}
+	} else if err != nil {
 		return models.Author{}, fmt.Errorf("failed to get author by ID: %w", err)
 	}
+
+	namesQuery := "SELECT display_name FROM author_names WHERE author_id = $1 ORDER BY first_seen, display_name"
+	if err := r.db.SelectContext(ctx, &author.DisplayNames, namesQuery, id); err != nil {
+		return models.Author{}, fmt.Errorf("failed to get author names: %w", err)
+	}
 	return author, nil
 }
 
 func (r *AuthorPostgres) CreateAuthor(ctx context.Context, author models.Author) error {
+	// Без идентификатора Jira автор идентифицируется по имени, как при миграции
 	query := `
-        INSERT INTO authors (display_name)
-        VALUES ($1)
+        INSERT INTO authors (account_id, display_name, email, active)
+        VALUES (COALESCE(NULLIF($1, ''), 'name:' || $2), $2, $3, $4)
         RETURNING id
     `
-	err := r.db.QueryRowContext(ctx, query, author.DisplayName).Scan(&author.ID)
+	err := r.db.QueryRowContext(ctx, query, author.AccountID, author.DisplayName, author.Email, author.Active).Scan(&author.ID)
 	return err
 }
+
+// MergeAuthors переносит все ссылки на автора sourceID на targetID.
+// Строка дубликата остаётся с merged_into, чтобы синхронизация продолжала
+// сопоставлять его идентификатор с основным автором.
+func (r *AuthorPostgres) MergeAuthors(ctx context.Context, sourceID, targetID int) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var authors []models.Author
+	lockQuery := "SELECT id, merged_into FROM authors WHERE id IN ($1, $2) FOR UPDATE"
+	if err := tx.SelectContext(ctx, &authors, lockQuery, sourceID, targetID); err != nil {
+		return fmt.Errorf("failed to lock authors: %w", err)
+	}
+
+	found := make(map[int]models.Author, len(authors))
+	for _, a := range authors {
+		found[a.ID] = a
+	}
+	for _, id := range []int{sourceID, targetID} {
+		if _, ok := found[id]; !ok {
+			return &models.NotFoundError{Message: fmt.Sprintf("author %d", id)}
+		}
+	}
+	if found[sourceID].MergedInto != nil {
+		return &models.InvalidInputError{Message: fmt.Sprintf("author %d is already merged", sourceID)}
+	}
+	if found[targetID].MergedInto != nil {
+		return &models.InvalidInputError{Message: fmt.Sprintf("author %d is merged into author %d", targetID, *found[targetID].MergedInto)}
+	}
+
+	queries := []string{
+		"UPDATE issues SET creator_id = $2 WHERE creator_id = $1",
+		"UPDATE issues SET assignee_id = $2 WHERE assignee_id = $1",
+		"UPDATE status_changes SET author_id = $2 WHERE author_id = $1",
+		"UPDATE field_changes SET author_id = $2 WHERE author_id = $1",
+		"UPDATE comments SET author_id = $2 WHERE author_id = $1",
+		"UPDATE worklogs SET author_id = $2 WHERE author_id = $1",
+		// Дубликаты, ранее объединённые с sourceID, указывают на новый основной автор
+		"UPDATE authors SET merged_into = $2 WHERE merged_into = $1 OR id = $1",
+		`INSERT INTO author_names (author_id, display_name, first_seen, last_seen)
+            SELECT $2, display_name, first_seen, last_seen FROM author_names WHERE author_id = $1
+            ON CONFLICT (author_id, display_name) DO UPDATE SET
+                first_seen = LEAST(author_names.first_seen, EXCLUDED.first_seen),
+                last_seen = GREATEST(author_names.last_seen, EXCLUDED.last_seen)`,
+		"DELETE FROM author_names WHERE author_id = $1",
+	}
+	for _, query := range queries {
+		if _, err := tx.ExecContext(ctx, query, sourceID, targetID); err != nil {
+			return fmt.Errorf("failed to merge authors: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit author merge: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"github.com/jmoiron/sqlx"
+	"jiraAnalyzer/backend/internal/models"
+)
+
+type CommentPostgres struct {
+	db *sqlx.DB
+}
+
+func NewCommentPostgres(db *sqlx.DB) *CommentPostgres {
+	return &CommentPostgres{db: db}
+}
+
+func (r *CommentPostgres) GetIssueComments(ctx context.Context, issueKey string) ([]models.Comment, error) {
+	query := `
+        SELECT c.jira_id, c.issue_id, c.author_id, a.display_name AS author_name, c.created, c.updated, c.body
+        FROM comments c
+        LEFT JOIN authors a ON a.id = c.author_id
+        WHERE c.issue_id = $1
+        ORDER BY c.created
+    `
+
+	var comments []models.Comment
+	err := r.db.SelectContext(ctx, &comments, query, issueKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get issue comments: %w", err)
+	}
+	return comments, nil
+}
+
+func (r *CommentPostgres) GetCommentStats(ctx context.Context, projectKey string) (models.CommentStats, error) {
+	query := `
+    WITH project_issues AS (
+        SELECT key, created, creator_id FROM issues WHERE project_key = $1 AND deleted_at IS NULL
+    ),
+    project_comments AS (
+        SELECT c.issue_id, c.author_id, c.created
+        FROM comments c
+        JOIN project_issues pi ON pi.key = c.issue_id
+    ),
+    first_responses AS (
+        SELECT EXTRACT(EPOCH FROM (MIN(pc.created) - pi.created)) / 3600 AS response_hours
+        FROM project_issues pi
+        JOIN project_comments pc ON pc.issue_id = pi.key
+        WHERE pc.author_id IS DISTINCT FROM pi.creator_id
+        GROUP BY pi.key, pi.created
+    )
+    SELECT
+        (SELECT COUNT(*) FROM project_issues) AS total_issues,
+        (SELECT COUNT(*) FROM project_comments) AS total_comments,
+        (SELECT COUNT(DISTINCT issue_id) FROM project_comments) AS commented_issues,
+        (SELECT COUNT(*) FROM first_responses) AS answered_issues,
+        COALESCE((SELECT AVG(response_hours) FROM first_responses), 0) AS average_first_response_hours,
+        COALESCE((SELECT PERCENTILE_CONT(0.5) WITHIN GROUP (ORDER BY response_hours) FROM first_responses), 0)
+            AS median_first_response_hours
+    `
+
+	var stats models.CommentStats
+	err := r.db.GetContext(ctx, &stats, query, projectKey)
+	if err != nil {
+		return models.CommentStats{}, fmt.Errorf("failed to get comment stats: %w", err)
+	}
+	return stats, nil
+}
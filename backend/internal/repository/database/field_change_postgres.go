@@ -0,0 +1,116 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"jiraAnalyzer/backend/internal/models"
+)
+
+// DefaultPriorityOrder - приоритеты стандартных схем Jira Cloud (Highest...
+// Lowest) и Jira Server (Blocker...Trivial) от высшего к низшему.
+var DefaultPriorityOrder = []string{"Highest", "Blocker", "Critical", "High", "Major", "Medium", "Minor", "Low", "Trivial", "Lowest"}
+
+type FieldChangePostgres struct {
+	db *sqlx.DB
+	// priorityOrder - имена приоритетов от высшего к низшему
+	priorityOrder []string
+}
+
+func NewFieldChangePostgres(db *sqlx.DB, priorityOrder []string) *FieldChangePostgres {
+	if len(priorityOrder) == 0 {
+		priorityOrder = DefaultPriorityOrder
+	}
+	return &FieldChangePostgres{db: db, priorityOrder: priorityOrder}
+}
+
+// GetIssueFieldChanges возвращает историю изменений задачи, при пустом field - по всем полям
+func (r *FieldChangePostgres) GetIssueFieldChanges(ctx context.Context, issueKey, field string) ([]models.FieldChange, error) {
+	query := `
+        SELECT issue_id, author_id, created, field, from_value, from_string, to_value, to_string
+        FROM field_changes
+        WHERE issue_id = $1 AND ($2 = '' OR field = $2)
+        ORDER BY created, history_id, item_index
+    `
+
+	var changes []models.FieldChange
+	err := r.db.SelectContext(ctx, &changes, query, issueKey, field)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get issue field changes: %w", err)
+	}
+	return changes, nil
+}
+
+// GetProjectFieldChanges возвращает изменения поля по всем задачам проекта с пагинацией
+func (r *FieldChangePostgres) GetProjectFieldChanges(ctx context.Context, projectKey, field string, page, limit int) ([]models.FieldChange, error) {
+	query := `
+        SELECT fc.issue_id, fc.author_id, fc.created, fc.field, fc.from_value, fc.from_string, fc.to_value, fc.to_string
+        FROM field_changes fc
+        JOIN issues i ON i.key = fc.issue_id
+        WHERE i.project_key = $1 AND i.deleted_at IS NULL AND ($2 = '' OR fc.field = $2)
+        ORDER BY fc.created DESC, fc.id DESC
+        LIMIT $3 OFFSET $4
+    `
+
+	var changes []models.FieldChange
+	offset := (page - 1) * limit
+	err := r.db.SelectContext(ctx, &changes, query, projectKey, field, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get project field changes: %w", err)
+	}
+	return changes, nil
+}
+
+// GetReassignmentCounts считает переназначения задач проекта. Первое назначение
+// исполнителя переназначением не считается.
+func (r *FieldChangePostgres) GetReassignmentCounts(ctx context.Context, projectKey string) ([]models.ReassignmentData, error) {
+	query := `
+        SELECT fc.issue_id AS issue_key, COUNT(*) AS reassignments
+        FROM field_changes fc
+        JOIN issues i ON i.key = fc.issue_id
+        WHERE i.project_key = $1 AND i.deleted_at IS NULL
+            AND fc.field = 'assignee'
+            AND fc.from_value IS NOT NULL
+            AND fc.to_value IS NOT NULL
+        GROUP BY fc.issue_id
+        ORDER BY reassignments DESC, issue_key
+    `
+
+	var data []models.ReassignmentData
+	err := r.db.SelectContext(ctx, &data, query, projectKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get reassignment counts: %w", err)
+	}
+	return data, nil
+}
+
+// GetPriorityEscalations возвращает повышения приоритета задач проекта.
+// Id приоритетов не отражают их порядок в собственных схемах приоритетов,
+// поэтому приоритеты сравниваются по позиции имени в настроенном списке
+// (priorityOrder в настройках Backend). Переходы с приоритетами не из
+// списка не учитываются.
+func (r *FieldChangePostgres) GetPriorityEscalations(ctx context.Context, projectKey string) ([]models.PriorityEscalation, error) {
+	query := `
+        WITH ranks AS (
+            SELECT name, rank FROM unnest($2::text[]) WITH ORDINALITY AS p(name, rank)
+        )
+        SELECT fc.issue_id AS issue_key, fc.author_id, fc.created,
+            fc.from_string AS from_priority, fc.to_string AS to_priority
+        FROM field_changes fc
+        JOIN issues i ON i.key = fc.issue_id
+        JOIN ranks from_rank ON from_rank.name = fc.from_string
+        JOIN ranks to_rank ON to_rank.name = fc.to_string
+        WHERE i.project_key = $1 AND i.deleted_at IS NULL
+            AND fc.field = 'priority'
+            AND to_rank.rank < from_rank.rank
+        ORDER BY fc.created DESC
+    `
+
+	var data []models.PriorityEscalation
+	err := r.db.SelectContext(ctx, &data, query, projectKey, pq.Array(r.priorityOrder))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get priority escalations: %w", err)
+	}
+	return data, nil
+}
@@ -0,0 +1,43 @@
+package database
+
+import (
+	"encoding/json"
+	"fmt"
+	"jiraAnalyzer/backend/internal/models"
+)
+
+// issueFilterCondition возвращает SQL-условие на задачи таблицы alias,
+// param - номер параметра с JSON фильтра из issueFilterParam. Пустой
+// фильтр пропускает все задачи. Задачи, удалённые в Jira, исключаются всегда.
+func issueFilterCondition(alias string, param int) string {
+	return fmt.Sprintf(`%[1]s.deleted_at IS NULL
+        AND NOT EXISTS (
+            SELECT 1 FROM jsonb_each_text($%[2]d::jsonb -> 'customFields') f
+            WHERE NOT COALESCE(
+                %[1]s.custom_fields ->> f.key = f.value OR %[1]s.custom_fields -> f.key @> to_jsonb(f.value),
+                false
+            )
+        )
+        AND ($%[2]d::jsonb ->> 'component' IS NULL OR EXISTS (
+            SELECT 1 FROM issue_components ic
+            JOIN components c ON c.id = ic.component_id
+            WHERE ic.issue_id = %[1]s.key AND c.name = $%[2]d::jsonb ->> 'component'
+        ))
+        AND ($%[2]d::jsonb ->> 'label' IS NULL OR EXISTS (
+            SELECT 1 FROM issue_labels il
+            WHERE il.issue_id = %[1]s.key AND il.label = $%[2]d::jsonb ->> 'label'
+        ))
+        AND ($%[2]d::jsonb ->> 'fixVersion' IS NULL OR EXISTS (
+            SELECT 1 FROM issue_fix_versions ifv
+            JOIN versions v ON v.id = ifv.version_id
+            WHERE ifv.issue_id = %[1]s.key AND v.name = $%[2]d::jsonb ->> 'fixVersion'
+        ))`, alias, param)
+}
+
+func issueFilterParam(filter models.IssueFilter) (string, error) {
+	data, err := json.Marshal(filter)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal issue filter: %w", err)
+	}
+	return string(data), nil
+}
@@ -0,0 +1,74 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"jiraAnalyzer/backend/internal/models"
+)
+
+type IssueLinkPostgres struct {
+	db *sqlx.DB
+}
+
+func NewIssueLinkPostgres(db *sqlx.DB) *IssueLinkPostgres {
+	return &IssueLinkPostgres{db: db}
+}
+
+// GetLinksForIssues возвращает связи, в которых участвует любая из задач
+func (r *IssueLinkPostgres) GetLinksForIssues(ctx context.Context, issueKeys []string) ([]models.IssueLink, error) {
+	query := `
+        SELECT jira_id, source_key, target_key, link_type, outward, inward
+        FROM issue_links
+        WHERE source_key = ANY($1) OR target_key = ANY($1)
+        ORDER BY jira_id
+    `
+
+	var links []models.IssueLink
+	err := r.db.SelectContext(ctx, &links, query, pq.Array(issueKeys))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get issue links: %w", err)
+	}
+	return links, nil
+}
+
+// GetProjectLinks возвращает связи, у которых хотя бы одна сторона - задача проекта.
+// При пустом linkType возвращаются связи всех типов.
+func (r *IssueLinkPostgres) GetProjectLinks(ctx context.Context, projectKey, linkType string) ([]models.IssueLink, error) {
+	query := `
+        SELECT l.jira_id, l.source_key, l.target_key, l.link_type, l.outward, l.inward
+        FROM issue_links l
+        WHERE ($2 = '' OR l.link_type = $2)
+            AND EXISTS (
+                SELECT 1 FROM issues i
+                WHERE i.project_key = $1 AND i.deleted_at IS NULL
+                    AND i.key IN (l.source_key, l.target_key)
+            )
+        ORDER BY l.jira_id
+    `
+
+	var links []models.IssueLink
+	err := r.db.SelectContext(ctx, &links, query, projectKey, linkType)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get project links: %w", err)
+	}
+	return links, nil
+}
+
+// GetGraphNodes возвращает сведения о задачах графа. Задач, которых нет
+// в базе, в результате не будет.
+func (r *IssueLinkPostgres) GetGraphNodes(ctx context.Context, issueKeys []string) ([]models.GraphNode, error) {
+	query := `
+        SELECT key, project_key, summary, status, status NOT IN ('Closed', 'Resolved') AS open
+        FROM issues
+        WHERE key = ANY($1) AND deleted_at IS NULL
+    `
+
+	var nodes []models.GraphNode
+	err := r.db.SelectContext(ctx, &nodes, query, pq.Array(issueKeys))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get graph nodes: %w", err)
+	}
+	return nodes, nil
+}
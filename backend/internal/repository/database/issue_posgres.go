@@ -32,6 +32,7 @@ func (r *IssuePostgres) GetAllIssues(ctx context.Context, page, limit int) ([]mo
 	query := `
         SELECT key, project_key, created, updated, closed, summary, description, issue_type, priority, status, time_spent, creator_id, assignee_id
         FROM issues
+        WHERE deleted_at IS NULL
         LIMIT $1 OFFSET $2
     `
 	err := r.db.SelectContext(ctx, &issues, query, limit, offset)
@@ -45,7 +46,8 @@ func (r *IssuePostgres) GetAllIssues(ctx context.Context, page, limit int) ([]mo
 func (r *IssuePostgres) GetIssueById(ctx context.Context, id int) (models.Issue, error) {
 	var issue models.Issue
 	query := `
-        SELECT key, project_key, created, updated, summary, description, issue_type, priority, status, time_spent, creator_id, assignee_id
+        SELECT key, project_key, created, updated, summary, description, issue_type, priority, status, time_spent, creator_id, assignee_id,
+            deleted_at, moved_to
         FROM issues
         WHERE id = $1
     `
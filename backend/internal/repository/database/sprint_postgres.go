@@ -0,0 +1,116 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"jiraAnalyzer/backend/internal/models"
+)
+
+const sprintColumns = "id, board_id, project_key, name, state, goal, start_date, end_date, complete_date"
+
+type SprintPostgres struct {
+	db *sqlx.DB
+}
+
+func NewSprintPostgres(db *sqlx.DB) *SprintPostgres {
+	return &SprintPostgres{db: db}
+}
+
+func (r *SprintPostgres) GetProjectSprints(ctx context.Context, projectKey string) ([]models.Sprint, error) {
+	query := `SELECT ` + sprintColumns + `
+        FROM sprints
+        WHERE project_key = $1
+        ORDER BY start_date DESC NULLS FIRST, id DESC
+    `
+
+	var sprints []models.Sprint
+	err := r.db.SelectContext(ctx, &sprints, query, projectKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get project sprints: %w", err)
+	}
+	return sprints, nil
+}
+
+func (r *SprintPostgres) GetSprint(ctx context.Context, sprintID int) (models.Sprint, error) {
+	var sprint models.Sprint
+	err := r.db.GetContext(ctx, &sprint, "SELECT "+sprintColumns+" FROM sprints WHERE id = $1", sprintID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.Sprint{}, &models.NotFoundError{Message: fmt.Sprintf("sprint %d", sprintID)}
+	} else if err != nil {
+		return models.Sprint{}, fmt.Errorf("failed to get sprint: %w", err)
+	}
+	return sprint, nil
+}
+
+// GetNextSprint возвращает следующий по дате старта спринт той же доски или nil
+func (r *SprintPostgres) GetNextSprint(ctx context.Context, sprint models.Sprint) (*models.Sprint, error) {
+	query := `SELECT ` + sprintColumns + `
+        FROM sprints
+        WHERE board_id = $1 AND id <> $2
+            AND (start_date IS NULL OR start_date > $3)
+        ORDER BY start_date NULLS LAST, id
+        LIMIT 1
+    `
+
+	var next models.Sprint
+	err := r.db.GetContext(ctx, &next, query, sprint.BoardID, sprint.ID, sprint.StartDate)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to get next sprint: %w", err)
+	}
+	return &next, nil
+}
+
+func (r *SprintPostgres) GetSprintIssueKeys(ctx context.Context, sprintID int) ([]string, error) {
+	var keys []string
+	err := r.db.SelectContext(ctx, &keys, "SELECT issue_key FROM sprint_issues WHERE sprint_id = $1 ORDER BY issue_key", sprintID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sprint issues: %w", err)
+	}
+	return keys, nil
+}
+
+// GetSprintFieldChanges возвращает изменения поля Sprint у задач, которые
+// сейчас в спринте или когда-либо в нём были. Значения поля - списки id
+// спринтов через запятую.
+func (r *SprintPostgres) GetSprintFieldChanges(ctx context.Context, sprintID int) ([]models.FieldChange, error) {
+	query := `
+        WITH sprint_ref AS (SELECT $1::int::text AS id),
+        related AS (
+            SELECT issue_key AS issue_id FROM sprint_issues WHERE sprint_id = $1::int
+            UNION
+            SELECT fc.issue_id
+            FROM field_changes fc, sprint_ref s
+            WHERE fc.field = 'Sprint' AND (
+                s.id = ANY(string_to_array(replace(COALESCE(fc.from_value, ''), ' ', ''), ','))
+                OR s.id = ANY(string_to_array(replace(COALESCE(fc.to_value, ''), ' ', ''), ','))
+            )
+        )
+        SELECT fc.issue_id, fc.author_id, fc.created, fc.field, fc.from_value, fc.from_string, fc.to_value, fc.to_string
+        FROM field_changes fc
+        JOIN related r ON r.issue_id = fc.issue_id
+        WHERE fc.field = 'Sprint'
+        ORDER BY fc.issue_id, fc.created, fc.history_id, fc.item_index
+    `
+
+	var changes []models.FieldChange
+	err := r.db.SelectContext(ctx, &changes, query, sprintID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sprint field changes: %w", err)
+	}
+	return changes, nil
+}
+
+func (r *SprintPostgres) GetIssueResolutions(ctx context.Context, issueKeys []string) ([]models.IssueResolution, error) {
+	var resolutions []models.IssueResolution
+	err := r.db.SelectContext(ctx, &resolutions, "SELECT key, closed FROM issues WHERE key = ANY($1)", pq.Array(issueKeys))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get issue resolutions: %w", err)
+	}
+	return resolutions, nil
+}
@@ -0,0 +1,68 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"github.com/jmoiron/sqlx"
+	"jiraAnalyzer/backend/internal/models"
+	"time"
+)
+
+type WorklogPostgres struct {
+	db *sqlx.DB
+}
+
+func NewWorklogPostgres(db *sqlx.DB) *WorklogPostgres {
+	return &WorklogPostgres{db: db}
+}
+
+// GetWeeklyAuthorHours возвращает часы, списанные по задачам проекта, по авторам
+// и неделям. Границы from и to необязательны.
+func (r *WorklogPostgres) GetWeeklyAuthorHours(ctx context.Context, projectKey string, from, to *time.Time) ([]models.AuthorWeekHours, error) {
+	query := `
+        SELECT
+            a.id AS author_id,
+            a.display_name AS author_name,
+            DATE_TRUNC('week', w.started) AS week,
+            SUM(w.time_spent_seconds) / 3600.0 AS hours
+        FROM worklogs w
+        JOIN issues i ON i.key = w.issue_id
+        JOIN authors a ON a.id = w.author_id
+        WHERE i.project_key = $1 AND i.deleted_at IS NULL
+            AND ($2::timestamp IS NULL OR w.started >= $2)
+            AND ($3::timestamp IS NULL OR w.started < $3)
+        GROUP BY a.id, a.display_name, week
+        ORDER BY week, author_name
+    `
+
+	var data []models.AuthorWeekHours
+	err := r.db.SelectContext(ctx, &data, query, projectKey, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get weekly author hours: %w", err)
+	}
+	return data, nil
+}
+
+// GetIssueTypeHours возвращает часы, списанные по задачам проекта, по типам задач.
+func (r *WorklogPostgres) GetIssueTypeHours(ctx context.Context, projectKey string, from, to *time.Time) ([]models.IssueTypeHours, error) {
+	query := `
+        SELECT
+            i.issue_type,
+            COUNT(DISTINCT i.key) AS issues,
+            SUM(w.time_spent_seconds) / 3600.0 AS hours
+        FROM worklogs w
+        JOIN issues i ON i.key = w.issue_id
+        WHERE i.project_key = $1 AND i.deleted_at IS NULL
+            AND ($2::timestamp IS NULL OR w.started >= $2)
+            AND ($3::timestamp IS NULL OR w.started < $3)
+        GROUP BY i.issue_type
+        ORDER BY hours DESC
+    `
+
+	var data []models.IssueTypeHours
+	err := r.db.SelectContext(ctx, &data, query, projectKey, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get issue type hours: %w", err)
+	}
+	return data, nil
+}
@@ -51,22 +51,31 @@ func (c *HTTPJiraClient) GetConnectorProjects(ctx context.Context, page, limit i
 	return response.Projects, response.PageInfo, nil
 }
 
-func (c *HTTPJiraClient) UpdateConnectorProject(ctx context.Context, projectKeys []string) error {
+// UpdateConnectorProject ставит синхронизацию проектов в очередь коннектора
+// и возвращает идентификатор фоновой задачи.
+func (c *HTTPJiraClient) UpdateConnectorProject(ctx context.Context, projectKeys []string) (int, error) {
 	url := fmt.Sprintf("%s/updateProject?projects=%s", c.url, strings.Join(projectKeys, ","))
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
+		return 0, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	resp, err := c.client.Do(req)
 	if err != nil {
-		return fmt.Errorf("failed to call JiraConnector: %w", err)
+		return 0, fmt.Errorf("failed to call JiraConnector: %w", err)
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("unexpected status code from JiraConnector: %d", resp.StatusCode)
+	if resp.StatusCode != http.StatusAccepted {
+		return 0, fmt.Errorf("unexpected status code from JiraConnector: %d", resp.StatusCode)
+	}
+
+	var response struct {
+		JobID int `json:"jobId"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return 0, fmt.Errorf("failed to decode response: %w", err)
 	}
 
-	return nil
+	return response.JobID, nil
 }
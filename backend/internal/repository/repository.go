@@ -6,6 +6,7 @@ import (
 	"jiraAnalyzer/backend/internal/models"
 	"jiraAnalyzer/backend/internal/repository/database"
 	"jiraAnalyzer/backend/internal/repository/jira"
+	"time"
 )
 
 type Projects interface {
@@ -28,41 +29,85 @@ type Issues interface {
 type Authors interface {
 	GetAuthorById(ctx context.Context, id int) (models.Author, error)
 	CreateAuthor(ctx context.Context, author models.Author) error
+	MergeAuthors(ctx context.Context, sourceID, targetID int) error
+}
+
+type FieldChanges interface {
+	GetIssueFieldChanges(ctx context.Context, issueKey, field string) ([]models.FieldChange, error)
+	GetProjectFieldChanges(ctx context.Context, projectKey, field string, page, limit int) ([]models.FieldChange, error)
+	GetReassignmentCounts(ctx context.Context, projectKey string) ([]models.ReassignmentData, error)
+	GetPriorityEscalations(ctx context.Context, projectKey string) ([]models.PriorityEscalation, error)
+}
+
+type Comments interface {
+	GetIssueComments(ctx context.Context, issueKey string) ([]models.Comment, error)
+	GetCommentStats(ctx context.Context, projectKey string) (models.CommentStats, error)
+}
+
+type Worklogs interface {
+	GetWeeklyAuthorHours(ctx context.Context, projectKey string, from, to *time.Time) ([]models.AuthorWeekHours, error)
+	GetIssueTypeHours(ctx context.Context, projectKey string, from, to *time.Time) ([]models.IssueTypeHours, error)
+}
+
+type IssueLinks interface {
+	GetLinksForIssues(ctx context.Context, issueKeys []string) ([]models.IssueLink, error)
+	GetProjectLinks(ctx context.Context, projectKey, linkType string) ([]models.IssueLink, error)
+	GetGraphNodes(ctx context.Context, issueKeys []string) ([]models.GraphNode, error)
+}
+
+type Sprints interface {
+	GetProjectSprints(ctx context.Context, projectKey string) ([]models.Sprint, error)
+	GetSprint(ctx context.Context, sprintID int) (models.Sprint, error)
+	GetNextSprint(ctx context.Context, sprint models.Sprint) (*models.Sprint, error)
+	GetSprintIssueKeys(ctx context.Context, sprintID int) ([]string, error)
+	GetSprintFieldChanges(ctx context.Context, sprintID int) ([]models.FieldChange, error)
+	GetIssueResolutions(ctx context.Context, issueKeys []string) ([]models.IssueResolution, error)
 }
 
 type Analytics interface {
-	GetProjectAnalytics(ctx context.Context, projectKey string) (models.ProjectAnalytics, error)
+	GetProjectAnalytics(ctx context.Context, projectKey string, filter models.IssueFilter) (models.ProjectAnalytics, error)
 	GetAnalytics(ctx context.Context, projectKey string, taskNumber int) ([]byte, error)
 	IsProjectAnalyzed(ctx context.Context, projectKey string) (bool, error)
 	DeleteProjectAnalytics(ctx context.Context, projectKey string) error
 	SaveAnalytics(ctx context.Context, projectKey string, taskNumber int, data []byte) error
-	CalculateOpenTimeHistogram(ctx context.Context, projectKey string) ([]models.HistogramData, error)
-	CalculateStatusTimeDistribution(ctx context.Context, projectKey string) ([]models.StatusTimeData, error)
-	CalculateActivityGraph(ctx context.Context, projectKey string) ([]models.ActivityData, error)
-	CalculateComplexityGraph(ctx context.Context, projectKey string) ([]models.ComplexityData, error)
-	CalculatePriorityDistribution(ctx context.Context, projectKey string) ([]models.PriorityData, error)
-	CalculatePriorityDistributionClosedTasks(ctx context.Context, projectKey string) ([]models.PriorityData, error)
+	CalculateOpenTimeHistogram(ctx context.Context, projectKey string, filter models.IssueFilter) ([]models.HistogramData, error)
+	CalculateStatusTimeDistribution(ctx context.Context, projectKey string, filter models.IssueFilter) ([]models.StatusTimeData, error)
+	CalculateActivityGraph(ctx context.Context, projectKey string, filter models.IssueFilter) ([]models.ActivityData, error)
+	CalculateComplexityGraph(ctx context.Context, projectKey string, filter models.IssueFilter) ([]models.ComplexityData, error)
+	CalculatePriorityDistribution(ctx context.Context, projectKey string, filter models.IssueFilter) ([]models.PriorityData, error)
+	CalculatePriorityDistributionClosedTasks(ctx context.Context, projectKey string, filter models.IssueFilter) ([]models.PriorityData, error)
+	GroupByCustomField(ctx context.Context, projectKey, field string, filter models.IssueFilter) ([]models.CustomFieldGroup, error)
 }
 
 type JiraClient interface {
 	GetConnectorProjects(ctx context.Context, page, limit int, search string) ([]models.Project, models.PageInfo, error)
-	UpdateConnectorProject(ctx context.Context, projectKeys []string) error
+	UpdateConnectorProject(ctx context.Context, projectKeys []string) (int, error)
 }
 
 type Repository struct {
 	Projects
 	Issues
 	Authors
+	FieldChanges
+	Comments
+	Worklogs
+	IssueLinks
+	Sprints
 	Analytics
 	JiraClient
 }
 
-func NewRepository(db *sqlx.DB, url string) *Repository {
+func NewRepository(db *sqlx.DB, url string, priorityOrder []string) *Repository {
 	return &Repository{
-		Projects:   database.NewProjectPostgres(db),
-		Issues:     database.NewIssuePostgres(db),
-		Authors:    database.NewAuthorPostgres(db),
-		Analytics:  database.NewAnalyticsPostgres(db),
-		JiraClient: jira.NewHTTPJiraClient(url),
+		Projects:     database.NewProjectPostgres(db),
+		Issues:       database.NewIssuePostgres(db),
+		Authors:      database.NewAuthorPostgres(db),
+		FieldChanges: database.NewFieldChangePostgres(db, priorityOrder),
+		Comments:     database.NewCommentPostgres(db),
+		Worklogs:     database.NewWorklogPostgres(db),
+		IssueLinks:   database.NewIssueLinkPostgres(db),
+		Sprints:      database.NewSprintPostgres(db),
+		Analytics:    database.NewAnalyticsPostgres(db),
+		JiraClient:   jira.NewHTTPJiraClient(url),
 	}
 }
@@ -21,12 +21,12 @@ func NewAnalyticsService(repo *repository.Repository) *AnalyticsService {
 }
 
 // Метод для получения аналитики
-func (s *AnalyticsService) GetProjectAnalytics(ctx context.Context, projectKey string) (models.ProjectAnalytics, error) {
+func (s *AnalyticsService) GetProjectAnalytics(ctx context.Context, projectKey string, filter models.IssueFilter) (models.ProjectAnalytics, error) {
 	if projectKey == "" {
 		return models.ProjectAnalytics{}, &models.InvalidInputError{Message: "project key cannot be empty"}
 	}
 
-	analytics, err := s.repo.GetProjectAnalytics(ctx, projectKey)
+	analytics, err := s.repo.GetProjectAnalytics(ctx, projectKey, filter)
 	if err != nil {
 		return models.ProjectAnalytics{}, fmt.Errorf("failed to get project analytics: %w", err)
 	}
@@ -42,12 +42,17 @@ func (s *AnalyticsService) DeleteProjectAnalytics(ctx context.Context, projectKe
 	return s.repo.DeleteProjectAnalytics(ctx, projectKey)
 }
 
-func (s *AnalyticsService) CalculateOpenTimeHistogram(ctx context.Context, projectKey string, taskNumber int) ([]models.HistogramData, error) {
-	histogram, err := s.repo.CalculateOpenTimeHistogram(ctx, projectKey)
+func (s *AnalyticsService) CalculateOpenTimeHistogram(ctx context.Context, projectKey string, taskNumber int, filter models.IssueFilter) ([]models.HistogramData, error) {
+	histogram, err := s.repo.CalculateOpenTimeHistogram(ctx, projectKey, filter)
 	if err != nil {
 		return nil, fmt.Errorf("failed to calculate histogram: %w", err)
 	}
 
+	// Отфильтрованная аналитика не кэшируется
+	if !filter.IsEmpty() {
+		return histogram, nil
+	}
+
 	data, err := json.Marshal(histogram)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal histogram data: %w", err)
@@ -60,14 +65,18 @@ func (s *AnalyticsService) CalculateOpenTimeHistogram(ctx context.Context, proje
 	return histogram, nil
 }
 
-func (s *AnalyticsService) GetOpenTimeHistogram(ctx context.Context, projectKey string, taskNumber int) ([]models.HistogramData, error) {
+func (s *AnalyticsService) GetOpenTimeHistogram(ctx context.Context, projectKey string, taskNumber int, filter models.IssueFilter) ([]models.HistogramData, error) {
+	if !filter.IsEmpty() {
+		return s.CalculateOpenTimeHistogram(ctx, projectKey, taskNumber, filter)
+	}
+
 	data, err := s.repo.GetAnalytics(ctx, projectKey, taskNumber)
 	if err != nil && !errors.Is(err, sql.ErrNoRows) {
 		return nil, fmt.Errorf("failed to get histogram data: %w", err)
 	}
 
 	if len(data) == 0 {
-		return s.CalculateOpenTimeHistogram(ctx, projectKey, taskNumber)
+		return s.CalculateOpenTimeHistogram(ctx, projectKey, taskNumber, filter)
 	}
 
 	var histogram []models.HistogramData
@@ -78,12 +87,17 @@ func (s *AnalyticsService) GetOpenTimeHistogram(ctx context.Context, projectKey
 	return histogram, nil
 }
 
-func (s *AnalyticsService) CalculateStatusTimeDistribution(ctx context.Context, projectKey string, taskNumber int) ([]models.StatusTimeData, error) {
-	distribution, err := s.repo.CalculateStatusTimeDistribution(ctx, projectKey)
+func (s *AnalyticsService) CalculateStatusTimeDistribution(ctx context.Context, projectKey string, taskNumber int, filter models.IssueFilter) ([]models.StatusTimeData, error) {
+	distribution, err := s.repo.CalculateStatusTimeDistribution(ctx, projectKey, filter)
 	if err != nil {
 		return nil, fmt.Errorf("failed to calculate status time distribution: %w", err)
 	}
 
+	// Отфильтрованная аналитика не кэшируется
+	if !filter.IsEmpty() {
+		return distribution, nil
+	}
+
 	data, err := json.Marshal(distribution)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal status time distribution data: %w", err)
@@ -96,14 +110,18 @@ func (s *AnalyticsService) CalculateStatusTimeDistribution(ctx context.Context,
 	return distribution, nil
 }
 
-func (s *AnalyticsService) GetStatusTimeDistribution(ctx context.Context, projectKey string, taskNumber int) ([]models.StatusTimeData, error) {
+func (s *AnalyticsService) GetStatusTimeDistribution(ctx context.Context, projectKey string, taskNumber int, filter models.IssueFilter) ([]models.StatusTimeData, error) {
+	if !filter.IsEmpty() {
+		return s.CalculateStatusTimeDistribution(ctx, projectKey, taskNumber, filter)
+	}
+
 	data, err := s.repo.GetAnalytics(ctx, projectKey, taskNumber)
 	if err != nil && !errors.Is(err, sql.ErrNoRows) {
 		return nil, fmt.Errorf("failed to get status time distribution data: %w", err)
 	}
 
 	if len(data) == 0 {
-		return s.CalculateStatusTimeDistribution(ctx, projectKey, taskNumber)
+		return s.CalculateStatusTimeDistribution(ctx, projectKey, taskNumber, filter)
 	}
 
 	var distribution []models.StatusTimeData
@@ -114,12 +132,17 @@ func (s *AnalyticsService) GetStatusTimeDistribution(ctx context.Context, projec
 	return distribution, nil
 }
 
-func (s *AnalyticsService) CalculateActivityGraph(ctx context.Context, projectKey string, taskNumber int) ([]models.ActivityData, error) {
-	activity, err := s.repo.CalculateActivityGraph(ctx, projectKey)
+func (s *AnalyticsService) CalculateActivityGraph(ctx context.Context, projectKey string, taskNumber int, filter models.IssueFilter) ([]models.ActivityData, error) {
+	activity, err := s.repo.CalculateActivityGraph(ctx, projectKey, filter)
 	if err != nil {
 		return nil, fmt.Errorf("failed to calculate activity graph: %w", err)
 	}
 
+	// Отфильтрованная аналитика не кэшируется
+	if !filter.IsEmpty() {
+		return activity, nil
+	}
+
 	data, err := json.Marshal(activity)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal activity graph data: %w", err)
@@ -132,14 +155,18 @@ func (s *AnalyticsService) CalculateActivityGraph(ctx context.Context, projectKe
 	return activity, nil
 }
 
-func (s *AnalyticsService) GetActivityGraph(ctx context.Context, projectKey string, taskNumber int) ([]models.ActivityData, error) {
+func (s *AnalyticsService) GetActivityGraph(ctx context.Context, projectKey string, taskNumber int, filter models.IssueFilter) ([]models.ActivityData, error) {
+	if !filter.IsEmpty() {
+		return s.CalculateActivityGraph(ctx, projectKey, taskNumber, filter)
+	}
+
 	data, err := s.repo.GetAnalytics(ctx, projectKey, taskNumber)
 	if err != nil && !errors.Is(err, sql.ErrNoRows) {
 		return nil, fmt.Errorf("failed to get activity graph data: %w", err)
 	}
 
 	if len(data) == 0 {
-		return s.CalculateActivityGraph(ctx, projectKey, taskNumber)
+		return s.CalculateActivityGraph(ctx, projectKey, taskNumber, filter)
 	}
 
 	var activity []models.ActivityData
@@ -150,12 +177,17 @@ func (s *AnalyticsService) GetActivityGraph(ctx context.Context, projectKey stri
 	return activity, nil
 }
 
-func (s *AnalyticsService) CalculateComplexityGraph(ctx context.Context, projectKey string, taskNumber int) ([]models.ComplexityData, error) {
-	complexity, err := s.repo.CalculateComplexityGraph(ctx, projectKey)
+func (s *AnalyticsService) CalculateComplexityGraph(ctx context.Context, projectKey string, taskNumber int, filter models.IssueFilter) ([]models.ComplexityData, error) {
+	complexity, err := s.repo.CalculateComplexityGraph(ctx, projectKey, filter)
 	if err != nil {
 		return nil, fmt.Errorf("failed to calculate complexity graph: %w", err)
 	}
 
+	// Отфильтрованная аналитика не кэшируется
+	if !filter.IsEmpty() {
+		return complexity, nil
+	}
+
 	data, err := json.Marshal(complexity)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal complexity graph data: %w", err)
@@ -168,14 +200,18 @@ func (s *AnalyticsService) CalculateComplexityGraph(ctx context.Context, project
 	return complexity, nil
 }
 
-func (s *AnalyticsService) GetComplexityGraph(ctx context.Context, projectKey string, taskNumber int) ([]models.ComplexityData, error) {
+func (s *AnalyticsService) GetComplexityGraph(ctx context.Context, projectKey string, taskNumber int, filter models.IssueFilter) ([]models.ComplexityData, error) {
+	if !filter.IsEmpty() {
+		return s.CalculateComplexityGraph(ctx, projectKey, taskNumber, filter)
+	}
+
 	data, err := s.repo.GetAnalytics(ctx, projectKey, taskNumber)
 	if err != nil && !errors.Is(err, sql.ErrNoRows) {
 		return nil, fmt.Errorf("failed to get complexity graph data: %w", err)
 	}
 
 	if len(data) == 0 {
-		return s.CalculateComplexityGraph(ctx, projectKey, taskNumber)
+		return s.CalculateComplexityGraph(ctx, projectKey, taskNumber, filter)
 	}
 
 	var complexity []models.ComplexityData
@@ -186,12 +222,17 @@ func (s *AnalyticsService) GetComplexityGraph(ctx context.Context, projectKey st
 	return complexity, nil
 }
 
-func (s *AnalyticsService) CalculatePriorityDistribution(ctx context.Context, projectKey string, taskNumber int) ([]models.PriorityData, error) {
-	distribution, err := s.repo.CalculatePriorityDistribution(ctx, projectKey)
+func (s *AnalyticsService) CalculatePriorityDistribution(ctx context.Context, projectKey string, taskNumber int, filter models.IssueFilter) ([]models.PriorityData, error) {
+	distribution, err := s.repo.CalculatePriorityDistribution(ctx, projectKey, filter)
 	if err != nil {
 		return nil, fmt.Errorf("failed to calculate priority distribution: %w", err)
 	}
 
+	// Отфильтрованная аналитика не кэшируется
+	if !filter.IsEmpty() {
+		return distribution, nil
+	}
+
 	data, err := json.Marshal(distribution)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal priority distribution data: %w", err)
@@ -204,14 +245,18 @@ func (s *AnalyticsService) CalculatePriorityDistribution(ctx context.Context, pr
 	return distribution, nil
 }
 
-func (s *AnalyticsService) GetPriorityDistribution(ctx context.Context, projectKey string, taskNumber int) ([]models.PriorityData, error) {
+func (s *AnalyticsService) GetPriorityDistribution(ctx context.Context, projectKey string, taskNumber int, filter models.IssueFilter) ([]models.PriorityData, error) {
+	if !filter.IsEmpty() {
+		return s.CalculatePriorityDistribution(ctx, projectKey, taskNumber, filter)
+	}
+
 	data, err := s.repo.GetAnalytics(ctx, projectKey, taskNumber)
 	if err != nil && !errors.Is(err, sql.ErrNoRows) {
 		return nil, fmt.Errorf("failed to get priority distribution data: %w", err)
 	}
 
 	if len(data) == 0 {
-		return s.CalculatePriorityDistribution(ctx, projectKey, taskNumber)
+		return s.CalculatePriorityDistribution(ctx, projectKey, taskNumber, filter)
 	}
 
 	var distribution []models.PriorityData
@@ -222,12 +267,17 @@ func (s *AnalyticsService) GetPriorityDistribution(ctx context.Context, projectK
 	return distribution, nil
 }
 
-func (s *AnalyticsService) CalculatePriorityDistributionClosedTasks(ctx context.Context, projectKey string, taskNumber int) ([]models.PriorityData, error) {
-	distribution, err := s.repo.CalculatePriorityDistributionClosedTasks(ctx, projectKey)
+func (s *AnalyticsService) CalculatePriorityDistributionClosedTasks(ctx context.Context, projectKey string, taskNumber int, filter models.IssueFilter) ([]models.PriorityData, error) {
+	distribution, err := s.repo.CalculatePriorityDistributionClosedTasks(ctx, projectKey, filter)
 	if err != nil {
 		return nil, fmt.Errorf("failed to calculate priority distribution closed tasks: %w", err)
 	}
 
+	// Отфильтрованная аналитика не кэшируется
+	if !filter.IsEmpty() {
+		return distribution, nil
+	}
+
 	data, err := json.Marshal(distribution)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal priority distribution closed tasks data: %w", err)
@@ -240,14 +290,18 @@ func (s *AnalyticsService) CalculatePriorityDistributionClosedTasks(ctx context.
 	return distribution, nil
 }
 
-func (s *AnalyticsService) GetPriorityDistributionClosedTasks(ctx context.Context, projectKey string, taskNumber int) ([]models.PriorityData, error) {
+func (s *AnalyticsService) GetPriorityDistributionClosedTasks(ctx context.Context, projectKey string, taskNumber int, filter models.IssueFilter) ([]models.PriorityData, error) {
+	if !filter.IsEmpty() {
+		return s.CalculatePriorityDistributionClosedTasks(ctx, projectKey, taskNumber, filter)
+	}
+
 	data, err := s.repo.GetAnalytics(ctx, projectKey, taskNumber)
 	if err != nil && !errors.Is(err, sql.ErrNoRows) {
 		return nil, fmt.Errorf("failed to get priority distribution closed tasks data: %w", err)
 	}
 
 	if len(data) == 0 {
-		return s.CalculatePriorityDistributionClosedTasks(ctx, projectKey, taskNumber)
+		return s.CalculatePriorityDistributionClosedTasks(ctx, projectKey, taskNumber, filter)
 	}
 
 	var distribution []models.PriorityData
@@ -257,3 +311,15 @@ func (s *AnalyticsService) GetPriorityDistributionClosedTasks(ctx context.Contex
 
 	return distribution, nil
 }
+
+func (s *AnalyticsService) GroupByCustomField(ctx context.Context, projectKey, field string, filter models.IssueFilter) ([]models.CustomFieldGroup, error) {
+	if field == "" {
+		return nil, &models.InvalidInputError{Message: "custom field name cannot be empty"}
+	}
+
+	groups, err := s.repo.GroupByCustomField(ctx, projectKey, field, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to group issues by custom field: %w", err)
+	}
+	return groups, nil
+}
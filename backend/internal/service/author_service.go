@@ -0,0 +1,32 @@
+package service
+
+import (
+	"context"
+	"jiraAnalyzer/backend/internal/models"
+	"jiraAnalyzer/backend/internal/repository"
+)
+
+type AuthorService struct {
+	repo *repository.Repository
+}
+
+func NewAuthorService(repo *repository.Repository) *AuthorService {
+	return &AuthorService{repo: repo}
+}
+
+func (s *AuthorService) GetAuthor(ctx context.Context, id int) (models.Author, error) {
+	return s.repo.GetAuthorById(ctx, id)
+}
+
+// MergeAuthors объединяет дубликат sourceID с автором targetID и возвращает
+// итогового автора.
+func (s *AuthorService) MergeAuthors(ctx context.Context, sourceID, targetID int) (models.Author, error) {
+	if sourceID == targetID {
+		return models.Author{}, &models.InvalidInputError{Message: "cannot merge author into itself"}
+	}
+
+	if err := s.repo.MergeAuthors(ctx, sourceID, targetID); err != nil {
+		return models.Author{}, err
+	}
+	return s.repo.GetAuthorById(ctx, targetID)
+}
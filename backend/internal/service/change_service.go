@@ -0,0 +1,31 @@
+package service
+
+import (
+	"context"
+	"jiraAnalyzer/backend/internal/models"
+	"jiraAnalyzer/backend/internal/repository"
+)
+
+type ChangeService struct {
+	repo *repository.Repository
+}
+
+func NewChangeService(repo *repository.Repository) *ChangeService {
+	return &ChangeService{repo: repo}
+}
+
+func (s *ChangeService) GetIssueFieldChanges(ctx context.Context, issueKey, field string) ([]models.FieldChange, error) {
+	return s.repo.GetIssueFieldChanges(ctx, issueKey, field)
+}
+
+func (s *ChangeService) GetProjectFieldChanges(ctx context.Context, projectKey, field string, page, limit int) ([]models.FieldChange, error) {
+	return s.repo.GetProjectFieldChanges(ctx, projectKey, field, page, limit)
+}
+
+func (s *ChangeService) GetReassignmentCounts(ctx context.Context, projectKey string) ([]models.ReassignmentData, error) {
+	return s.repo.GetReassignmentCounts(ctx, projectKey)
+}
+
+func (s *ChangeService) GetPriorityEscalations(ctx context.Context, projectKey string) ([]models.PriorityEscalation, error) {
+	return s.repo.GetPriorityEscalations(ctx, projectKey)
+}
@@ -0,0 +1,31 @@
+package service
+
+import (
+	"context"
+	"jiraAnalyzer/backend/internal/models"
+	"jiraAnalyzer/backend/internal/repository"
+)
+
+type CommentService struct {
+	repo *repository.Repository
+}
+
+func NewCommentService(repo *repository.Repository) *CommentService {
+	return &CommentService{repo: repo}
+}
+
+func (s *CommentService) GetIssueComments(ctx context.Context, issueKey string) ([]models.Comment, error) {
+	return s.repo.GetIssueComments(ctx, issueKey)
+}
+
+func (s *CommentService) GetCommentStats(ctx context.Context, projectKey string) (models.CommentStats, error) {
+	stats, err := s.repo.GetCommentStats(ctx, projectKey)
+	if err != nil {
+		return models.CommentStats{}, err
+	}
+
+	if stats.TotalIssues > 0 {
+		stats.AverageCommentsPerIssue = float64(stats.TotalComments) / float64(stats.TotalIssues)
+	}
+	return stats, nil
+}
@@ -0,0 +1,323 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"jiraAnalyzer/backend/internal/models"
+	"jiraAnalyzer/backend/internal/repository"
+	"sort"
+)
+
+// BlocksLinkType - стандартный тип связи Jira "A blocks B"
+const BlocksLinkType = "Blocks"
+
+type DependencyService struct {
+	repo *repository.Repository
+}
+
+func NewDependencyService(repo *repository.Repository) *DependencyService {
+	return &DependencyService{repo: repo}
+}
+
+// GetDependencyGraph возвращает подграф связей вокруг задачи: все задачи,
+// достижимые из неё не более чем за depth связей в любом направлении.
+func (s *DependencyService) GetDependencyGraph(ctx context.Context, issueKey string, depth int) (models.DependencyGraph, error) {
+	visited := map[string]bool{issueKey: true}
+	edges := make(map[string]models.IssueLink)
+	frontier := []string{issueKey}
+
+	for level := 0; level < depth && len(frontier) > 0; level++ {
+		links, err := s.repo.GetLinksForIssues(ctx, frontier)
+		if err != nil {
+			return models.DependencyGraph{}, err
+		}
+
+		var next []string
+		for _, link := range links {
+			edges[link.JiraID] = link
+			for _, key := range []string{link.SourceKey, link.TargetKey} {
+				if !visited[key] {
+					visited[key] = true
+					next = append(next, key)
+				}
+			}
+		}
+		frontier = next
+	}
+
+	keys := make([]string, 0, len(visited))
+	for key := range visited {
+		keys = append(keys, key)
+	}
+	nodes, err := s.getNodes(ctx, keys)
+	if err != nil {
+		return models.DependencyGraph{}, err
+	}
+
+	if len(edges) == 0 && !nodes[issueKey].Known {
+		return models.DependencyGraph{}, &models.NotFoundError{Message: fmt.Sprintf("issue %s", issueKey)}
+	}
+
+	graph := models.DependencyGraph{
+		Root:  issueKey,
+		Depth: depth,
+		Nodes: make([]models.GraphNode, 0, len(nodes)),
+		Edges: make([]models.IssueLink, 0, len(edges)),
+	}
+	for _, key := range sortedKeys(nodes) {
+		graph.Nodes = append(graph.Nodes, nodes[key])
+	}
+	for _, link := range edges {
+		graph.Edges = append(graph.Edges, link)
+	}
+	sort.Slice(graph.Edges, func(i, j int) bool { return graph.Edges[i].JiraID < graph.Edges[j].JiraID })
+
+	return graph, nil
+}
+
+// FindCycles ищет циклы в связях задач проекта заданного типа. Каждый цикл -
+// компонента сильной связности графа, ключи в ней отсортированы.
+func (s *DependencyService) FindCycles(ctx context.Context, projectKey, linkType string) ([][]string, error) {
+	links, err := s.repo.GetProjectLinks(ctx, projectKey, linkType)
+	if err != nil {
+		return nil, err
+	}
+
+	cycles := make([][]string, 0)
+	for _, component := range stronglyConnected(buildAdjacency(links)) {
+		if len(component) > 1 || hasSelfLoop(links, component[0]) {
+			cycles = append(cycles, component)
+		}
+	}
+	return cycles, nil
+}
+
+// GetLongestBlockingChain ищет самую длинную цепочку блокировок среди открытых
+// задач. Поиск самого длинного пути в графе с циклами - NP-трудная задача,
+// поэтому связи внутри циклов (см. FindCycles) не учитываются.
+func (s *DependencyService) GetLongestBlockingChain(ctx context.Context, projectKey string) (models.BlockingChain, error) {
+	links, err := s.repo.GetProjectLinks(ctx, projectKey, BlocksLinkType)
+	if err != nil {
+		return models.BlockingChain{}, err
+	}
+
+	var keys []string
+	for _, link := range links {
+		keys = append(keys, link.SourceKey, link.TargetKey)
+	}
+	nodes, err := s.getNodes(ctx, keys)
+	if err != nil {
+		return models.BlockingChain{}, err
+	}
+
+	openLinks := make([]models.IssueLink, 0, len(links))
+	for _, link := range links {
+		if nodes[link.SourceKey].Open && nodes[link.TargetKey].Open {
+			openLinks = append(openLinks, link)
+		}
+	}
+
+	chain := longestPath(openLinks)
+	result := models.BlockingChain{
+		Length: len(chain),
+		Issues: make([]models.GraphNode, 0, len(chain)),
+	}
+	for _, key := range chain {
+		result.Issues = append(result.Issues, nodes[key])
+	}
+	return result, nil
+}
+
+// getNodes возвращает сведения о задачах по ключам, включая отсутствующие в базе
+func (s *DependencyService) getNodes(ctx context.Context, keys []string) (map[string]models.GraphNode, error) {
+	nodes := make(map[string]models.GraphNode, len(keys))
+	for _, key := range keys {
+		nodes[key] = models.GraphNode{Key: key}
+	}
+	if len(keys) == 0 {
+		return nodes, nil
+	}
+
+	known, err := s.repo.GetGraphNodes(ctx, keys)
+	if err != nil {
+		return nil, err
+	}
+	for _, node := range known {
+		node.Known = true
+		nodes[node.Key] = node
+	}
+	return nodes, nil
+}
+
+func buildAdjacency(links []models.IssueLink) map[string][]string {
+	adjacency := make(map[string][]string)
+	for _, link := range links {
+		adjacency[link.SourceKey] = append(adjacency[link.SourceKey], link.TargetKey)
+		if _, ok := adjacency[link.TargetKey]; !ok {
+			adjacency[link.TargetKey] = nil
+		}
+	}
+	for key := range adjacency {
+		sort.Strings(adjacency[key])
+	}
+	return adjacency
+}
+
+func hasSelfLoop(links []models.IssueLink, key string) bool {
+	for _, link := range links {
+		if link.SourceKey == key && link.TargetKey == key {
+			return true
+		}
+	}
+	return false
+}
+
+// stronglyConnected возвращает компоненты сильной связности графа (алгоритм Тарьяна).
+// Обход итеративный, чтобы длинные цепочки не переполняли стек.
+func stronglyConnected(adjacency map[string][]string) [][]string {
+	index := make(map[string]int, len(adjacency))
+	lowlink := make(map[string]int, len(adjacency))
+	onStack := make(map[string]bool, len(adjacency))
+	var stack []string
+	var components [][]string
+	counter := 0
+
+	type frame struct {
+		key  string
+		next int
+	}
+
+	for _, start := range sortedKeys(adjacency) {
+		if _, seen := index[start]; seen {
+			continue
+		}
+
+		callStack := []frame{{key: start}}
+		index[start], lowlink[start] = counter, counter
+		counter++
+		stack = append(stack, start)
+		onStack[start] = true
+
+		for len(callStack) > 0 {
+			top := &callStack[len(callStack)-1]
+			neighbours := adjacency[top.key]
+
+			if top.next < len(neighbours) {
+				next := neighbours[top.next]
+				top.next++
+
+				if _, seen := index[next]; !seen {
+					index[next], lowlink[next] = counter, counter
+					counter++
+					stack = append(stack, next)
+					onStack[next] = true
+					callStack = append(callStack, frame{key: next})
+				} else if onStack[next] {
+					lowlink[top.key] = min(lowlink[top.key], index[next])
+				}
+				continue
+			}
+
+			key := top.key
+			callStack = callStack[:len(callStack)-1]
+			if len(callStack) > 0 {
+				parent := callStack[len(callStack)-1].key
+				lowlink[parent] = min(lowlink[parent], lowlink[key])
+			}
+
+			if lowlink[key] == index[key] {
+				var component []string
+				for {
+					member := stack[len(stack)-1]
+					stack = stack[:len(stack)-1]
+					onStack[member] = false
+					component = append(component, member)
+					if member == key {
+						break
+					}
+				}
+				sort.Strings(component)
+				components = append(components, component)
+			}
+		}
+	}
+
+	return components
+}
+
+// longestPath возвращает самый длинный путь в графе, не учитывая рёбра внутри
+// компонент сильной связности. Оставшийся граф ацикличен.
+func longestPath(links []models.IssueLink) []string {
+	adjacency := buildAdjacency(links)
+	component := make(map[string]int, len(adjacency))
+	for i, members := range stronglyConnected(adjacency) {
+		for _, key := range members {
+			component[key] = i
+		}
+	}
+
+	inDegree := make(map[string]int, len(adjacency))
+	dag := make(map[string][]string, len(adjacency))
+	for _, from := range sortedKeys(adjacency) {
+		for _, to := range adjacency[from] {
+			if component[from] != component[to] {
+				dag[from] = append(dag[from], to)
+				inDegree[to]++
+			}
+		}
+	}
+
+	// Топологический обход: length - число задач в самой длинной цепочке,
+	// заканчивающейся в вершине, prev - предыдущая задача этой цепочки
+	length := make(map[string]int, len(adjacency))
+	prev := make(map[string]string, len(adjacency))
+	var queue []string
+	for _, key := range sortedKeys(adjacency) {
+		length[key] = 1
+		if inDegree[key] == 0 {
+			queue = append(queue, key)
+		}
+	}
+
+	var end string
+	for len(queue) > 0 {
+		key := queue[0]
+		queue = queue[1:]
+		if end == "" || length[key] > length[end] {
+			end = key
+		}
+
+		for _, next := range dag[key] {
+			if length[key]+1 > length[next] {
+				length[next] = length[key] + 1
+				prev[next] = key
+			}
+			inDegree[next]--
+			if inDegree[next] == 0 {
+				queue = append(queue, next)
+			}
+		}
+	}
+
+	if end == "" {
+		return nil
+	}
+
+	chain := []string{end}
+	for key, ok := prev[end]; ok; key, ok = prev[key] {
+		chain = append(chain, key)
+	}
+	for i, j := 0, len(chain)-1; i < j; i, j = i+1, j-1 {
+		chain[i], chain[j] = chain[j], chain[i]
+	}
+	return chain
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
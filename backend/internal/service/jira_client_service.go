@@ -18,6 +18,6 @@ func (c *JiraClientService) GetConnectorProjects(ctx context.Context, page, limi
 	return c.repo.GetConnectorProjects(ctx, page, limit, search)
 }
 
-func (c *JiraClientService) UpdateConnectorProject(ctx context.Context, projectKeys []string) error {
+func (c *JiraClientService) UpdateConnectorProject(ctx context.Context, projectKeys []string) (int, error) {
 	return c.repo.UpdateConnectorProject(ctx, projectKeys)
 }
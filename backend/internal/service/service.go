@@ -5,17 +5,29 @@ import (
 )
 
 type Service struct {
-	Projects   *ProjectService
-	Issues     *IssueService
-	Analytics  *AnalyticsService
-	JiraClient *JiraClientService
+	Projects     *ProjectService
+	Issues       *IssueService
+	Authors      *AuthorService
+	Changes      *ChangeService
+	Comments     *CommentService
+	Worklogs     *WorklogService
+	Dependencies *DependencyService
+	Sprints      *SprintService
+	Analytics    *AnalyticsService
+	JiraClient   *JiraClientService
 }
 
 func NewService(repo *repository.Repository) *Service {
 	return &Service{
-		Projects:   NewProjectService(repo),
-		Issues:     NewIssueService(repo),
-		Analytics:  NewAnalyticsService(repo),
-		JiraClient: NewJiraClientService(repo),
+		Projects:     NewProjectService(repo),
+		Issues:       NewIssueService(repo),
+		Authors:      NewAuthorService(repo),
+		Changes:      NewChangeService(repo),
+		Comments:     NewCommentService(repo),
+		Worklogs:     NewWorklogService(repo),
+		Dependencies: NewDependencyService(repo),
+		Sprints:      NewSprintService(repo),
+		Analytics:    NewAnalyticsService(repo),
+		JiraClient:   NewJiraClientService(repo),
 	}
 }
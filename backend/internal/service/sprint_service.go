@@ -0,0 +1,214 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"jiraAnalyzer/backend/internal/models"
+	"jiraAnalyzer/backend/internal/repository"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// sprintStateClosed - состояние завершённого спринта в Jira Agile
+const sprintStateClosed = "closed"
+
+type SprintService struct {
+	repo *repository.Repository
+}
+
+func NewSprintService(repo *repository.Repository) *SprintService {
+	return &SprintService{repo: repo}
+}
+
+func (s *SprintService) GetProjectSprints(ctx context.Context, projectKey string) ([]models.Sprint, error) {
+	return s.repo.GetProjectSprints(ctx, projectKey)
+}
+
+// GetSprintReport строит отчёт по спринту. Состав спринта в каждый момент
+// восстанавливается по истории поля Sprint, для задач без истории
+// используется текущий состав.
+func (s *SprintService) GetSprintReport(ctx context.Context, sprintID int) (models.SprintReport, error) {
+	sprint, err := s.repo.GetSprint(ctx, sprintID)
+	if err != nil {
+		return models.SprintReport{}, err
+	}
+	if sprint.StartDate == nil {
+		return models.SprintReport{}, &models.InvalidInputError{Message: fmt.Sprintf("sprint %d has not started yet", sprintID)}
+	}
+
+	start := *sprint.StartDate
+	end := time.Now()
+	switch {
+	case sprint.CompleteDate != nil:
+		end = *sprint.CompleteDate
+	case sprint.State == sprintStateClosed && sprint.EndDate != nil:
+		end = *sprint.EndDate
+	}
+
+	currentKeys, err := s.repo.GetSprintIssueKeys(ctx, sprintID)
+	if err != nil {
+		return models.SprintReport{}, err
+	}
+	changes, err := s.repo.GetSprintFieldChanges(ctx, sprintID)
+	if err != nil {
+		return models.SprintReport{}, err
+	}
+
+	history := newSprintHistory(strconv.Itoa(sprintID), currentKeys, changes)
+
+	report := models.SprintReport{
+		Sprint:      sprint,
+		Committed:   []string{},
+		Completed:   []string{},
+		Added:       []string{},
+		Removed:     []string{},
+		CarriedOver: []string{},
+		MovedToNext: []string{},
+	}
+
+	var finalMembers []string
+	for _, key := range history.issueKeys() {
+		committed := history.memberAt(key, start)
+		added := !committed && history.addedBetween(key, start, end)
+		final := history.memberAt(key, end)
+
+		if committed {
+			report.Committed = append(report.Committed, key)
+		}
+		if added {
+			report.Added = append(report.Added, key)
+		}
+		if (committed || added) && !final {
+			report.Removed = append(report.Removed, key)
+		}
+		if final {
+			finalMembers = append(finalMembers, key)
+		}
+	}
+
+	if len(finalMembers) == 0 {
+		return report, nil
+	}
+
+	resolutions, err := s.repo.GetIssueResolutions(ctx, finalMembers)
+	if err != nil {
+		return models.SprintReport{}, err
+	}
+	closedAt := make(map[string]time.Time, len(resolutions))
+	for _, res := range resolutions {
+		if res.Closed != nil {
+			closedAt[res.Key] = *res.Closed
+		}
+	}
+
+	for _, key := range finalMembers {
+		if closed, ok := closedAt[key]; ok && !closed.After(end) {
+			report.Completed = append(report.Completed, key)
+		} else if sprint.State == sprintStateClosed {
+			report.CarriedOver = append(report.CarriedOver, key)
+		}
+	}
+
+	if len(report.CarriedOver) == 0 {
+		return report, nil
+	}
+
+	next, err := s.repo.GetNextSprint(ctx, sprint)
+	if err != nil || next == nil {
+		return report, err
+	}
+	report.NextSprintID = &next.ID
+
+	nextKeys, err := s.repo.GetSprintIssueKeys(ctx, next.ID)
+	if err != nil {
+		return models.SprintReport{}, err
+	}
+	inNext := make(map[string]bool, len(nextKeys))
+	for _, key := range nextKeys {
+		inNext[key] = true
+	}
+	for _, key := range report.CarriedOver {
+		if inNext[key] {
+			report.MovedToNext = append(report.MovedToNext, key)
+		}
+	}
+
+	return report, nil
+}
+
+// sprintHistory восстанавливает, входила ли задача в спринт в заданный момент.
+type sprintHistory struct {
+	sprintID string
+	current  map[string]bool
+	changes  map[string][]models.FieldChange
+}
+
+func newSprintHistory(sprintID string, currentKeys []string, changes []models.FieldChange) *sprintHistory {
+	h := &sprintHistory{
+		sprintID: sprintID,
+		current:  make(map[string]bool, len(currentKeys)),
+		changes:  make(map[string][]models.FieldChange),
+	}
+	for _, key := range currentKeys {
+		h.current[key] = true
+	}
+	// Изменения приходят отсортированными по времени внутри задачи
+	for _, change := range changes {
+		h.changes[change.IssueID] = append(h.changes[change.IssueID], change)
+	}
+	return h
+}
+
+func (h *sprintHistory) issueKeys() []string {
+	keys := make([]string, 0, len(h.current)+len(h.changes))
+	for key := range h.current {
+		keys = append(keys, key)
+	}
+	for key := range h.changes {
+		if !h.current[key] {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (h *sprintHistory) memberAt(key string, t time.Time) bool {
+	changes := h.changes[key]
+
+	// Последнее изменение не позже t определяет состав
+	for i := len(changes) - 1; i >= 0; i-- {
+		if !changes[i].Created.After(t) {
+			return h.contains(changes[i].ToValue)
+		}
+	}
+	// Иначе - значение до первого изменения после t
+	if len(changes) > 0 {
+		return h.contains(changes[0].FromValue)
+	}
+	return h.current[key]
+}
+
+func (h *sprintHistory) addedBetween(key string, from, to time.Time) bool {
+	for _, change := range h.changes[key] {
+		if change.Created.After(from) && !change.Created.After(to) &&
+			h.contains(change.ToValue) && !h.contains(change.FromValue) {
+			return true
+		}
+	}
+	return false
+}
+
+func (h *sprintHistory) contains(value *string) bool {
+	if value == nil {
+		return false
+	}
+	for _, id := range strings.Split(*value, ",") {
+		if strings.TrimSpace(id) == h.sprintID {
+			return true
+		}
+	}
+	return false
+}
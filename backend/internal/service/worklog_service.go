@@ -0,0 +1,24 @@
+package service
+
+import (
+	"context"
+	"jiraAnalyzer/backend/internal/models"
+	"jiraAnalyzer/backend/internal/repository"
+	"time"
+)
+
+type WorklogService struct {
+	repo *repository.Repository
+}
+
+func NewWorklogService(repo *repository.Repository) *WorklogService {
+	return &WorklogService{repo: repo}
+}
+
+func (s *WorklogService) GetWeeklyAuthorHours(ctx context.Context, projectKey string, from, to *time.Time) ([]models.AuthorWeekHours, error) {
+	return s.repo.GetWeeklyAuthorHours(ctx, projectKey, from, to)
+}
+
+func (s *WorklogService) GetIssueTypeHours(ctx context.Context, projectKey string, from, to *time.Time) ([]models.IssueTypeHours, error) {
+	return s.repo.GetIssueTypeHours(ctx, projectKey, from, to)
+}
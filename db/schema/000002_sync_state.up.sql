@@ -0,0 +1,6 @@
+-- sync_state
+CREATE TABLE sync_state (
+    project_key VARCHAR(255) PRIMARY KEY REFERENCES projects(key) ON DELETE CASCADE ON UPDATE CASCADE,
+    last_synced_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,16 @@
+-- sync_jobs
+CREATE TABLE sync_jobs (
+    id SERIAL PRIMARY KEY,
+    project_keys TEXT[] NOT NULL,
+    full_sync BOOLEAN NOT NULL DEFAULT FALSE,
+    status VARCHAR(32) NOT NULL,
+    batches_total INT NOT NULL DEFAULT 0,
+    batches_done INT NOT NULL DEFAULT 0,
+    issues_written INT NOT NULL DEFAULT 0,
+    errors TEXT[] NOT NULL DEFAULT '{}',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    started_at TIMESTAMP,
+    finished_at TIMESTAMP
+);
+
+CREATE INDEX idx_sync_jobs_status ON sync_jobs(status);
@@ -0,0 +1,14 @@
+-- scheduler_runs
+CREATE TABLE scheduler_runs (
+    id SERIAL PRIMARY KEY,
+    schedule_name VARCHAR(255) NOT NULL,
+    project_key VARCHAR(255) NOT NULL,
+    full_sync BOOLEAN NOT NULL DEFAULT FALSE,
+    job_id INT REFERENCES sync_jobs(id) ON DELETE SET NULL,
+    status VARCHAR(32) NOT NULL,
+    message TEXT,
+    scheduled_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_scheduler_runs_schedule ON scheduler_runs(schedule_name, scheduled_at);
@@ -0,0 +1,19 @@
+-- field_changes
+CREATE TABLE field_changes (
+    id SERIAL PRIMARY KEY,
+    issue_id VARCHAR(255) NOT NULL REFERENCES issues(key) ON DELETE CASCADE ON UPDATE CASCADE,
+    author_id INT REFERENCES authors(id) ON DELETE CASCADE ON UPDATE CASCADE,
+    history_id VARCHAR(64) NOT NULL,
+    item_index INT NOT NULL,
+    created TIMESTAMP NOT NULL,
+    field VARCHAR(255) NOT NULL,
+    field_type VARCHAR(64),
+    from_value TEXT,
+    from_string TEXT,
+    to_value TEXT,
+    to_string TEXT,
+    UNIQUE (issue_id, history_id, item_index)
+);
+
+CREATE INDEX idx_field_changes_issue_id ON field_changes(issue_id);
+CREATE INDEX idx_field_changes_field ON field_changes(field, created);
@@ -0,0 +1,12 @@
+-- comments
+CREATE TABLE comments (
+    id SERIAL PRIMARY KEY,
+    jira_id VARCHAR(64) UNIQUE NOT NULL,
+    issue_id VARCHAR(255) NOT NULL REFERENCES issues(key) ON DELETE CASCADE ON UPDATE CASCADE,
+    author_id INT REFERENCES authors(id) ON DELETE CASCADE ON UPDATE CASCADE,
+    created TIMESTAMP NOT NULL,
+    updated TIMESTAMP NOT NULL,
+    body TEXT
+);
+
+CREATE INDEX idx_comments_issue_id ON comments(issue_id, created);
@@ -0,0 +1,15 @@
+-- worklogs
+CREATE TABLE worklogs (
+    id SERIAL PRIMARY KEY,
+    jira_id VARCHAR(64) UNIQUE NOT NULL,
+    issue_id VARCHAR(255) NOT NULL REFERENCES issues(key) ON DELETE CASCADE ON UPDATE CASCADE,
+    author_id INT REFERENCES authors(id) ON DELETE CASCADE ON UPDATE CASCADE,
+    started TIMESTAMP NOT NULL,
+    time_spent_seconds INT NOT NULL,
+    created TIMESTAMP NOT NULL,
+    updated TIMESTAMP NOT NULL,
+    comment TEXT
+);
+
+CREATE INDEX idx_worklogs_issue_id ON worklogs(issue_id);
+CREATE INDEX idx_worklogs_started ON worklogs(started);
@@ -0,0 +1,15 @@
+-- issue_links - ориентированный граф связей задач. Связь хранится в прямом
+-- направлении: source_key <outward> target_key, например "A blocks B".
+-- Связанная задача может быть в другом проекте, поэтому внешних ключей нет.
+CREATE TABLE issue_links (
+    id SERIAL PRIMARY KEY,
+    jira_id VARCHAR(64) UNIQUE NOT NULL,
+    source_key VARCHAR(255) NOT NULL,
+    target_key VARCHAR(255) NOT NULL,
+    link_type VARCHAR(255) NOT NULL,
+    outward VARCHAR(255) NOT NULL,
+    inward VARCHAR(255) NOT NULL
+);
+
+CREATE INDEX idx_issue_links_source_key ON issue_links(source_key);
+CREATE INDEX idx_issue_links_target_key ON issue_links(target_key);
@@ -0,0 +1,23 @@
+-- sprints - спринты досок Jira Agile, id совпадает с id спринта в Jira
+CREATE TABLE sprints (
+    id INT PRIMARY KEY,
+    board_id INT NOT NULL,
+    project_key VARCHAR(255) NOT NULL REFERENCES projects(key) ON DELETE CASCADE ON UPDATE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    state VARCHAR(32) NOT NULL,
+    goal TEXT,
+    start_date TIMESTAMP,
+    end_date TIMESTAMP,
+    complete_date TIMESTAMP
+);
+
+-- sprint_issues - текущий состав спринта. Задача может быть из другого
+-- проекта, поэтому внешнего ключа на issues нет.
+CREATE TABLE sprint_issues (
+    sprint_id INT NOT NULL REFERENCES sprints(id) ON DELETE CASCADE,
+    issue_key VARCHAR(255) NOT NULL,
+    PRIMARY KEY (sprint_id, issue_key)
+);
+
+CREATE INDEX idx_sprints_project_key ON sprints(project_key);
+CREATE INDEX idx_sprint_issues_issue_key ON sprint_issues(issue_key);
@@ -0,0 +1,4 @@
+-- Значения пользовательских полей Jira по логическим именам из настройки CustomFields
+ALTER TABLE issues ADD COLUMN custom_fields JSONB NOT NULL DEFAULT '{}';
+
+CREATE INDEX idx_issues_custom_fields ON issues USING GIN (custom_fields);
@@ -0,0 +1,39 @@
+-- components - компоненты проектов Jira
+CREATE TABLE components (
+    id SERIAL PRIMARY KEY,
+    jira_id VARCHAR(64) UNIQUE NOT NULL,
+    project_key VARCHAR(255) NOT NULL REFERENCES projects(key) ON DELETE CASCADE ON UPDATE CASCADE,
+    name VARCHAR(255) NOT NULL
+);
+
+-- versions - версии проектов Jira
+CREATE TABLE versions (
+    id SERIAL PRIMARY KEY,
+    jira_id VARCHAR(64) UNIQUE NOT NULL,
+    project_key VARCHAR(255) NOT NULL REFERENCES projects(key) ON DELETE CASCADE ON UPDATE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    released BOOLEAN NOT NULL DEFAULT FALSE,
+    release_date DATE
+);
+
+CREATE TABLE issue_labels (
+    issue_id VARCHAR(255) NOT NULL REFERENCES issues(key) ON DELETE CASCADE ON UPDATE CASCADE,
+    label VARCHAR(255) NOT NULL,
+    PRIMARY KEY (issue_id, label)
+);
+
+CREATE TABLE issue_components (
+    issue_id VARCHAR(255) NOT NULL REFERENCES issues(key) ON DELETE CASCADE ON UPDATE CASCADE,
+    component_id INT NOT NULL REFERENCES components(id) ON DELETE CASCADE,
+    PRIMARY KEY (issue_id, component_id)
+);
+
+CREATE TABLE issue_fix_versions (
+    issue_id VARCHAR(255) NOT NULL REFERENCES issues(key) ON DELETE CASCADE ON UPDATE CASCADE,
+    version_id INT NOT NULL REFERENCES versions(id) ON DELETE CASCADE,
+    PRIMARY KEY (issue_id, version_id)
+);
+
+CREATE INDEX idx_issue_labels_label ON issue_labels(label);
+CREATE INDEX idx_issue_components_component_id ON issue_components(component_id);
+CREATE INDEX idx_issue_fix_versions_version_id ON issue_fix_versions(version_id);
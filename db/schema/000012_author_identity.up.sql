@@ -0,0 +1,32 @@
+-- Авторы идентифицируются по accountId (Jira Cloud) или ключу пользователя
+-- (Jira Server), а не по отображаемому имени
+ALTER TABLE authors DROP CONSTRAINT authors_display_name_key;
+
+ALTER TABLE authors
+    ADD COLUMN account_id VARCHAR(255),
+    ADD COLUMN email VARCHAR(255),
+    ADD COLUMN active BOOLEAN NOT NULL DEFAULT TRUE,
+    -- merged_into - автор, с которым объединён дубликат. Строка дубликата
+    -- остаётся, чтобы синхронизация не создала его заново
+    ADD COLUMN merged_into INT REFERENCES authors(id) ON DELETE SET NULL;
+
+-- Идентификатор существующих авторов неизвестен. Такой автор привязывается
+-- к первому пользователю Jira с тем же именем при следующей синхронизации.
+UPDATE authors SET account_id = 'name:' || display_name;
+
+ALTER TABLE authors ALTER COLUMN account_id SET NOT NULL;
+ALTER TABLE authors ADD CONSTRAINT authors_account_id_key UNIQUE (account_id);
+
+-- author_names - история отображаемых имён автора
+CREATE TABLE author_names (
+    author_id INT NOT NULL REFERENCES authors(id) ON DELETE CASCADE,
+    display_name VARCHAR(255) NOT NULL,
+    first_seen TIMESTAMP NOT NULL DEFAULT NOW(),
+    last_seen TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (author_id, display_name)
+);
+
+INSERT INTO author_names (author_id, display_name)
+SELECT id, display_name FROM authors;
+
+CREATE INDEX idx_authors_display_name ON authors(display_name);
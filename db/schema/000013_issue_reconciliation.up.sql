@@ -0,0 +1,13 @@
+-- Задачи, которых не оказалось в Jira при полной синхронизации проекта.
+-- deleted_at - когда задача пропала, moved_to - новый ключ, если задача
+-- перенесена в проект, который не синхронизируется
+ALTER TABLE issues
+    ADD COLUMN deleted_at TIMESTAMP,
+    ADD COLUMN moved_to VARCHAR(255);
+
+CREATE INDEX idx_issues_project_key_active ON issues(project_key) WHERE deleted_at IS NULL;
+
+-- Итоги сверки в задаче синхронизации
+ALTER TABLE sync_jobs
+    ADD COLUMN issues_deleted INT NOT NULL DEFAULT 0,
+    ADD COLUMN issues_moved INT NOT NULL DEFAULT 0;
@@ -0,0 +1,14 @@
+-- Принятые доставки webhook Jira. Jira повторяет доставку с тем же
+-- идентификатором, пока не получит успешный ответ. Доставка без
+-- processed_at ещё обрабатывается или обработчик упал, не закончив её:
+-- после claimed_at + таймаут её можно принять заново
+CREATE TABLE webhook_deliveries (
+    id VARCHAR(255) PRIMARY KEY,
+    event VARCHAR(64) NOT NULL,
+    issue_key VARCHAR(255),
+    received_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    claimed_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    processed_at TIMESTAMP
+);
+
+CREATE INDEX idx_webhook_deliveries_received_at ON webhook_deliveries(received_at);
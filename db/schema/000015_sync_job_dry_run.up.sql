@@ -0,0 +1,5 @@
+-- Пробная синхронизация: задача ничего не записывает, а сохраняет отчёт
+-- о том, что изменилось бы в базе
+ALTER TABLE sync_jobs
+    ADD COLUMN dry_run BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN report JSONB;
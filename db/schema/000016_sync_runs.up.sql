@@ -0,0 +1,28 @@
+-- sync_runs: синхронизация проекта, которую можно продолжить после сбоя.
+-- Каждое возобновление начинает новый проход по той же выборке задач
+-- с первого батча, не сохранённого подряд с её начала.
+CREATE TABLE sync_runs (
+    id SERIAL PRIMARY KEY,
+    project_key VARCHAR(255) NOT NULL,
+    full_sync BOOLEAN NOT NULL DEFAULT FALSE,
+    updated_since TIMESTAMP,
+    -- started_at станет watermark проекта после завершения
+    started_at TIMESTAMP NOT NULL,
+    status VARCHAR(32) NOT NULL,
+    pass INT NOT NULL DEFAULT 1,
+    finished_at TIMESTAMP
+);
+
+-- У проекта не больше одной незавершённой синхронизации
+CREATE UNIQUE INDEX idx_sync_runs_active ON sync_runs(project_key) WHERE finished_at IS NULL;
+
+-- sync_checkpoints: батчи, закоммиченные синхронизацией. start_at -
+-- смещение батча в выборке, упорядоченной по ключу задачи.
+CREATE TABLE sync_checkpoints (
+    run_id INT NOT NULL REFERENCES sync_runs(id) ON DELETE CASCADE,
+    pass INT NOT NULL,
+    start_at INT NOT NULL,
+    issue_keys TEXT[] NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (run_id, pass, start_at)
+);
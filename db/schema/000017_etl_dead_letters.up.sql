@@ -0,0 +1,24 @@
+-- etl_dead_letters: задачи, которые не удалось преобразовать или сохранить.
+-- Остальные задачи батча сохраняются, а задача ждёт исправления и повтора.
+CREATE TABLE etl_dead_letters (
+    id SERIAL PRIMARY KEY,
+    issue_key VARCHAR(255) NOT NULL,
+    project_key VARCHAR(255) NOT NULL,
+    sync_run_id INT REFERENCES sync_runs(id) ON DELETE SET NULL,
+    -- stage - transform или persist
+    stage VARCHAR(32) NOT NULL,
+    error TEXT NOT NULL,
+    -- raw - задача в модели REST API Jira, partial - задача из webhook
+    raw JSONB NOT NULL,
+    partial BOOLEAN NOT NULL DEFAULT FALSE,
+    -- source_raw - задача в формате GitHub или GitLab, из которой собрана raw
+    source_raw JSONB,
+    attempts INT NOT NULL DEFAULT 1,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    resolved_at TIMESTAMP
+);
+
+-- У задачи не больше одной нерешённой записи
+CREATE UNIQUE INDEX idx_etl_dead_letters_open ON etl_dead_letters(issue_key) WHERE resolved_at IS NULL;
+CREATE INDEX idx_etl_dead_letters_project ON etl_dead_letters(project_key, id);
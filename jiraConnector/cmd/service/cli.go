@@ -4,6 +4,12 @@ import "flag"
 
 var (
 	ConfigPathFlag = flag.String("config", "../configs/config.yaml", "Path to YAML config")
+	// ImportPathFlag - импортировать выгрузку Jira вместо запуска сервиса
+	ImportPathFlag     = flag.String("import", "", "Import Jira search JSON pages or XML export from a file or directory and exit")
+	ImportProjectsFlag = flag.String("projects", "", "Comma-separated project keys to import, all projects of the export by default")
+	// RecordDirFlag и ReplayDirFlag переопределяют JiraClient.cassette из конфигурации
+	RecordDirFlag = flag.String("record", "", "Record Jira requests and responses into a cassette directory")
+	ReplayDirFlag = flag.String("replay", "", "Serve Jira responses from a cassette directory instead of the network")
 )
 
 func init() {
@@ -11,6 +11,7 @@ import (
 	"jiraAnalyzer/jiraConnector/internal/repository"
 	"jiraAnalyzer/jiraConnector/internal/repository/database"
 	"jiraAnalyzer/jiraConnector/internal/repository/jira"
+	"jiraAnalyzer/jiraConnector/internal/repository/tracker"
 	"jiraAnalyzer/jiraConnector/internal/service"
 	"log"
 	"net/http"
@@ -22,6 +23,8 @@ import (
 
 type app struct {
 	httpServer *http.Server
+	jobs       *service.JobService
+	scheduler  *service.SchedulerService
 }
 
 func NewApp(cfg config.Config) (*app, *sqlx.DB, error) {
@@ -31,18 +34,46 @@ func NewApp(cfg config.Config) (*app, *sqlx.DB, error) {
 		return nil, nil, fmt.Errorf("failed to create database config: %w", err)
 	}
 
-	clientJira := jira.NewJiraClient(cfg.ClientConfig)
+	clientJira, err := jira.NewJiraClient(cfg.ClientConfig)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create jira client: %w", err)
+	}
+
+	// Проекты из GitHub и GitLab загружаются своими трекерами, остальные - из Jira
+	trackers, err := tracker.NewRouter(clientJira, cfg.Trackers, cfg.ClientConfig.IssueInOneRequest)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to configure trackers: %w", err)
+	}
 
 	log.Printf("create new database repository")
-	dbRepository := repository.NewRepository(db, clientJira)
+	dbRepository := repository.NewRepository(db, trackers)
 
-	etl := service.NewETLService(dbRepository, cfg.ClientConfig.ThreadCount, cfg.ClientConfig.IssueInOneRequest)
+	if err := cfg.CustomFields.Validate(); err != nil {
+		return nil, nil, fmt.Errorf("invalid custom fields config: %w", err)
+	}
+
+	etl := service.NewETLService(dbRepository, cfg.ClientConfig.ThreadCount, cfg.ClientConfig.IssueInOneRequest, cfg.CustomFields)
+
+	jobs := service.NewJobService(dbRepository, etl)
+	if err := jobs.Recover(context.Background()); err != nil {
+		return nil, nil, fmt.Errorf("failed to recover sync jobs: %w", err)
+	}
+
+	scheduler, err := service.NewSchedulerService(dbRepository, jobs, cfg.Scheduler)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create scheduler: %w", err)
+	}
+
+	webhooks, err := service.NewWebhookService(dbRepository, etl, cfg.Webhook)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create webhook service: %w", err)
+	}
 
 	log.Printf("create new http server")
 	r := mux.NewRouter()
 
 	r.Use(handler.LogMiddleware)
-	newHandler := handler.NewHandler(etl, r, cfg.JiraConnector)
+	newHandler := handler.NewHandler(etl, jobs, scheduler, webhooks, r, cfg.JiraConnector)
 
 	server := &http.Server{
 		Addr:         cfg.JiraConnector.BaseUrl,
@@ -53,6 +84,8 @@ func NewApp(cfg config.Config) (*app, *sqlx.DB, error) {
 
 	return &app{
 		httpServer: server,
+		jobs:       jobs,
+		scheduler:  scheduler,
 	}, db, nil
 }
 
@@ -66,12 +99,16 @@ func (s *app) Run() error {
 		}
 	}()
 
+	s.scheduler.Start()
+
 	// Graceful shutdown
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 
 	log.Println("Shutting down server...")
+	s.scheduler.Stop()
+
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
@@ -79,6 +116,10 @@ func (s *app) Run() error {
 		log.Fatalf("Server forced to shutdown: %v", err)
 	}
 
+	if err := s.jobs.Shutdown(ctx); err != nil {
+		log.Printf("Sync jobs forced to stop: %v", err)
+	}
+
 	return nil
 }
 
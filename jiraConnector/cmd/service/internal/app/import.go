@@ -0,0 +1,48 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"jiraAnalyzer/jiraConnector/cmd/service/internal/config"
+	"jiraAnalyzer/jiraConnector/internal/repository"
+	"jiraAnalyzer/jiraConnector/internal/repository/database"
+	"jiraAnalyzer/jiraConnector/internal/repository/offline"
+	"jiraAnalyzer/jiraConnector/internal/service"
+	"log"
+)
+
+// RunImport загружает в базу задачи из выгрузки Jira (файла или каталога)
+// без обращения к Jira. Если projectKeys пуст, импортируются все проекты
+// выгрузки.
+func RunImport(cfg config.Config, path string, projectKeys []string) error {
+	if err := cfg.CustomFields.Validate(); err != nil {
+		return fmt.Errorf("invalid custom fields config: %w", err)
+	}
+
+	export, err := offline.Load(path, cfg.ClientConfig.IssueInOneRequest)
+	if err != nil {
+		return fmt.Errorf("failed to load export: %w", err)
+	}
+	if len(projectKeys) == 0 {
+		projectKeys = export.ProjectKeys()
+	}
+	if len(projectKeys) == 0 {
+		return fmt.Errorf("no issues found in %s", path)
+	}
+
+	db, err := database.NewDBConfig(cfg.DB)
+	if err != nil {
+		return fmt.Errorf("failed to create database config: %w", err)
+	}
+	defer database.CloseDB(db)
+
+	etl := service.NewETLService(repository.NewRepository(db, export), cfg.ClientConfig.ThreadCount, cfg.ClientConfig.IssueInOneRequest, cfg.CustomFields)
+
+	log.Printf("Importing projects %v from %s", projectKeys, path)
+	if err := etl.UpdateProject(context.Background(), projectKeys, service.SyncOptions{Full: true, Offline: true}); err != nil {
+		return fmt.Errorf("failed to import projects: %w", err)
+	}
+
+	log.Printf("Imported projects %v", projectKeys)
+	return nil
+}
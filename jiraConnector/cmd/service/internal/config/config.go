@@ -7,6 +7,8 @@ import (
 	handler "jiraAnalyzer/jiraConnector/internal/handler/http"
 	"jiraAnalyzer/jiraConnector/internal/repository/database"
 	"jiraAnalyzer/jiraConnector/internal/repository/jira"
+	"jiraAnalyzer/jiraConnector/internal/repository/tracker"
+	"jiraAnalyzer/jiraConnector/internal/service"
 	"log"
 	"os"
 )
@@ -20,6 +22,10 @@ type Config struct {
 	DB            database.DBConfig           `yaml:"DBSettings"`
 	ClientConfig  jira.ClientConfig           `yaml:"JiraClient"`
 	JiraConnector handler.JiraConnectorConfig `yaml:"JiraConnector"`
+	Scheduler     service.SchedulerConfig     `yaml:"Scheduler"`
+	CustomFields  service.CustomFieldsConfig  `yaml:"CustomFields"`
+	Webhook       service.WebhookConfig       `yaml:"Webhook"`
+	Trackers      tracker.Config              `yaml:"Trackers"`
 }
 
 func LoadConfig(ConfigPathFlag string) (Config, error) {
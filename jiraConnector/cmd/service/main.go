@@ -4,6 +4,9 @@ import (
 	"jiraAnalyzer/jiraConnector/cmd/service/internal/app"
 	"jiraAnalyzer/jiraConnector/cmd/service/internal/config"
 	"jiraAnalyzer/jiraConnector/internal/repository/database"
+	"jiraAnalyzer/jiraConnector/internal/repository/jira"
+	"log"
+	"strings"
 )
 
 func main() {
@@ -12,6 +15,26 @@ func main() {
 		panic(err)
 	}
 
+	switch {
+	case *RecordDirFlag != "" && *ReplayDirFlag != "":
+		log.Fatal("-record and -replay are mutually exclusive")
+	case *RecordDirFlag != "":
+		cfg.ClientConfig.Cassette = jira.CassetteConfig{Mode: jira.CassetteRecord, Dir: *RecordDirFlag}
+	case *ReplayDirFlag != "":
+		cfg.ClientConfig.Cassette = jira.CassetteConfig{Mode: jira.CassetteReplay, Dir: *ReplayDirFlag}
+	}
+
+	if *ImportPathFlag != "" {
+		var projectKeys []string
+		if *ImportProjectsFlag != "" {
+			projectKeys = strings.Split(*ImportProjectsFlag, ",")
+		}
+		if err := app.RunImport(cfg, *ImportPathFlag, projectKeys); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
 	newApp, db, err := app.NewApp(cfg)
 	if err != nil {
 		panic(err)
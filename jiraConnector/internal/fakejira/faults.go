@@ -0,0 +1,99 @@
+package fakejira
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"time"
+)
+
+// Fault - сбой, который сервер вносит в ответ на один запрос.
+type Fault struct {
+	// Delay - задержка перед ответом
+	Delay time.Duration
+	// Status - код ошибки вместо ответа, 0 - обычный ответ
+	Status int
+	// RetryAfter - значение заголовка Retry-After для ошибки
+	RetryAfter time.Duration
+	// Malformed - ответ обрывается на середине JSON
+	Malformed bool
+}
+
+// TooManyRequests - ответ 429 с Retry-After.
+func TooManyRequests(retryAfter time.Duration) Fault {
+	return Fault{Status: http.StatusTooManyRequests, RetryAfter: retryAfter}
+}
+
+// ServerError - ответ с кодом 5xx.
+func ServerError(status int) Fault {
+	return Fault{Status: status}
+}
+
+// Slow - обычный ответ после задержки.
+func Slow(delay time.Duration) Fault {
+	return Fault{Delay: delay}
+}
+
+// MalformedJSON - ответ 200 с оборванным JSON.
+func MalformedJSON() Fault {
+	return Fault{Malformed: true}
+}
+
+// Inject ставит сбои в очередь эндпоинта: каждый следующий запрос
+// к эндпоинту получает следующий сбой, после очереди ответы обычные.
+func (s *Server) Inject(endpoint string, faults ...Fault) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults[endpoint] = append(s.faults[endpoint], faults...)
+}
+
+// Requests возвращает число запросов к эндпоинту, включая сбойные.
+func (s *Server) Requests(endpoint string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.requests[endpoint]
+}
+
+// route учитывает запрос к эндпоинту и применяет очередной сбой.
+func (s *Server) route(endpoint string, handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		s.requests[endpoint]++
+		var fault Fault
+		if queue := s.faults[endpoint]; len(queue) > 0 {
+			fault, s.faults[endpoint] = queue[0], queue[1:]
+		}
+		s.mu.Unlock()
+
+		if fault.Delay > 0 {
+			timer := time.NewTimer(fault.Delay)
+			defer timer.Stop()
+			select {
+			case <-r.Context().Done():
+				// Клиент не дождался ответа
+				return
+			case <-timer.C:
+			}
+		}
+
+		if fault.Status != 0 {
+			if fault.RetryAfter > 0 {
+				w.Header().Set("Retry-After", strconv.Itoa(int(fault.RetryAfter.Round(time.Second)/time.Second)))
+			}
+			writeError(w, fault.Status, http.StatusText(fault.Status))
+			return
+		}
+
+		if fault.Malformed {
+			rec := httptest.NewRecorder()
+			handler(rec, r)
+			body := rec.Body.Bytes()
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			w.Write(body[:len(body)/2])
+			return
+		}
+
+		handler(w, r)
+	}
+}
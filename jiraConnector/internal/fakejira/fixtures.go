@@ -0,0 +1,157 @@
+package fakejira
+
+import (
+	"encoding/json"
+	"fmt"
+	"jiraAnalyzer/jiraConnector/internal/models"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// issue - задача фикстуры. Задача хранится в исходном JSON, чтобы сервер
+// отдавал поля, которых нет в моделях коннектора.
+type issue struct {
+	key        string
+	projectKey string
+	updated    time.Time
+	raw        map[string]json.RawMessage
+}
+
+// LoadFixtures загружает проекты и задачи из каталога фикстур:
+//   - projects.json - ответ /rest/api/2/project;
+//   - issues/*.json - массивы задач в формате поиска с changelog.
+func (s *Server) LoadFixtures(dir string) error {
+	data, err := os.ReadFile(filepath.Join(dir, "projects.json"))
+	if err != nil {
+		return fmt.Errorf("failed to read projects fixture: %w", err)
+	}
+	var projects []json.RawMessage
+	if err := json.Unmarshal(data, &projects); err != nil {
+		return fmt.Errorf("failed to parse projects fixture: %w", err)
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "issues", "*.json"))
+	if err != nil {
+		return fmt.Errorf("failed to list issue fixtures: %w", err)
+	}
+	sort.Strings(files)
+
+	var issues []json.RawMessage
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return fmt.Errorf("failed to read issue fixture: %w", err)
+		}
+		var page []json.RawMessage
+		if err := json.Unmarshal(data, &page); err != nil {
+			return fmt.Errorf("failed to parse issue fixture %s: %w", filepath.Base(file), err)
+		}
+		issues = append(issues, page...)
+	}
+
+	s.mu.Lock()
+	s.projects = projects
+	s.mu.Unlock()
+
+	for _, raw := range issues {
+		if err := s.PutIssue(raw); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// PutIssue добавляет задачу или заменяет задачу с тем же ключом.
+func (s *Server) PutIssue(raw json.RawMessage) error {
+	parsed, err := parseIssue(raw)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.issues[parsed.key] = parsed
+	return nil
+}
+
+// DeleteIssue удаляет задачу, как если бы её удалили в Jira.
+func (s *Server) DeleteIssue(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.issues, key)
+}
+
+// MoveIssue переносит задачу в другой проект под новым ключом. Запрос
+// задачи по старому ключу, как и в Jira, возвращает задачу с новым ключом.
+func (s *Server) MoveIssue(oldKey, newKey string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	moved, ok := s.issues[oldKey]
+	if !ok {
+		return fmt.Errorf("issue %s not found", oldKey)
+	}
+	projectKey, _, _ := strings.Cut(newKey, "-")
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(moved.raw["fields"], &fields); err != nil {
+		return fmt.Errorf("failed to parse fields of %s: %w", oldKey, err)
+	}
+	fields["project"], _ = json.Marshal(models.JiraProject{Key: projectKey})
+
+	raw := make(map[string]json.RawMessage, len(moved.raw))
+	for name, value := range moved.raw {
+		raw[name] = value
+	}
+	raw["key"], _ = json.Marshal(newKey)
+	raw["fields"], _ = json.Marshal(fields)
+
+	delete(s.issues, oldKey)
+	s.issues[newKey] = &issue{key: newKey, projectKey: projectKey, updated: moved.updated, raw: raw}
+	s.moved[oldKey] = newKey
+	return nil
+}
+
+func parseIssue(data json.RawMessage) (*issue, error) {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse issue: %w", err)
+	}
+
+	var head struct {
+		Key    string `json:"key"`
+		Fields struct {
+			Project models.JiraProject `json:"project"`
+			Updated string             `json:"updated"`
+		} `json:"fields"`
+	}
+	if err := json.Unmarshal(data, &head); err != nil {
+		return nil, fmt.Errorf("failed to parse issue: %w", err)
+	}
+	if head.Key == "" {
+		return nil, fmt.Errorf("issue without key")
+	}
+
+	projectKey := head.Fields.Project.Key
+	if projectKey == "" {
+		projectKey, _, _ = strings.Cut(head.Key, "-")
+	}
+	updated, err := time.Parse(models.JiraTimeLayout, head.Fields.Updated)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse updated of %s: %w", head.Key, err)
+	}
+
+	return &issue{key: head.Key, projectKey: projectKey, updated: updated, raw: raw}, nil
+}
+
+// issueNumber - номер задачи из ключа, по которому задачи упорядочены
+// в выдаче поиска.
+func issueNumber(key string) int {
+	_, number, _ := strings.Cut(key, "-")
+	n, _ := strconv.Atoi(number)
+	return n
+}
@@ -0,0 +1,291 @@
+// Package fakejira - HTTP-сервер, имитирующий REST API Jira для тестов
+// коннектора: проекты, поиск задач с changelog, задачи и их списания,
+// доски Agile API. Данные загружаются из фикстур, сбои (429, 5xx, медленные
+// ответы, оборванный JSON) вносятся по запросу теста.
+package fakejira
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/gorilla/mux"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Эндпоинты сервера, к которым привязываются сбои и счётчики запросов
+const (
+	EndpointProjects = "/rest/api/2/project"
+	EndpointSearch   = "/rest/api/2/search"
+	EndpointIssue    = "/rest/api/2/issue"
+	EndpointWorklog  = "/rest/api/2/issue/worklog"
+	EndpointBoards   = "/rest/agile/1.0/board"
+)
+
+const (
+	defaultMaxResults = 50
+	// searchWorklogLimit - столько списаний Jira отдаёт в задаче из поиска,
+	// остальные доступны через /issue/{key}/worklog
+	searchWorklogLimit = 20
+	// jqlTimeLayout - формат даты в JQL
+	jqlTimeLayout = "2006-01-02 15:04"
+)
+
+var (
+	jqlProjectRe = regexp.MustCompile(`project\s*=\s*"?([^"\s]+)"?`)
+	jqlUpdatedRe = regexp.MustCompile(`updated\s*>=\s*"([^"]+)"`)
+)
+
+// Server - фейковая Jira поверх httptest.Server. Адрес для клиента - URL.
+type Server struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	projects []json.RawMessage
+	issues   map[string]*issue
+	// moved - новый ключ перенесённой задачи по старому
+	moved    map[string]string
+	faults   map[string][]Fault
+	requests map[string]int
+	// maxResults - наибольший размер страницы поиска
+	maxResults int
+}
+
+// NewServer запускает пустой сервер. Данные добавляются через LoadFixtures
+// и PutIssue, сервер останавливается через Close.
+func NewServer() *Server {
+	s := &Server{
+		issues:     make(map[string]*issue),
+		moved:      make(map[string]string),
+		faults:     make(map[string][]Fault),
+		requests:   make(map[string]int),
+		maxResults: 100,
+	}
+
+	r := mux.NewRouter()
+	r.HandleFunc("/rest/api/2/project", s.route(EndpointProjects, s.getProjects)).Methods(http.MethodGet)
+	r.HandleFunc("/rest/api/2/search", s.route(EndpointSearch, s.search)).Methods(http.MethodGet)
+	r.HandleFunc("/rest/api/2/issue/{key}", s.route(EndpointIssue, s.getIssue)).Methods(http.MethodGet)
+	r.HandleFunc("/rest/api/2/issue/{key}/worklog", s.route(EndpointWorklog, s.getWorklogs)).Methods(http.MethodGet)
+	r.HandleFunc("/rest/agile/1.0/board", s.route(EndpointBoards, s.getBoards)).Methods(http.MethodGet)
+
+	s.Server = httptest.NewServer(r)
+	return s
+}
+
+// SetMaxResults ограничивает размер страницы поиска, как это делает Jira
+// независимо от запрошенного maxResults.
+func (s *Server) SetMaxResults(n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.maxResults = n
+}
+
+func (s *Server) getProjects(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	projects := s.projects
+	s.mu.Unlock()
+
+	if projects == nil {
+		projects = []json.RawMessage{}
+	}
+	writeJSON(w, projects)
+}
+
+// search поддерживает JQL вида project=KEY [AND updated >= "yyyy-MM-dd HH:mm"].
+// Задачи упорядочены по номеру, как при ORDER BY key.
+func (s *Server) search(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	jql := query.Get("jql")
+
+	match := jqlProjectRe.FindStringSubmatch(jql)
+	if match == nil {
+		writeError(w, http.StatusBadRequest, "Only project queries are supported")
+		return
+	}
+	projectKey := match[1]
+
+	var updatedSince time.Time
+	if match := jqlUpdatedRe.FindStringSubmatch(jql); match != nil {
+		var err error
+		if updatedSince, err = time.Parse(jqlTimeLayout, match[1]); err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("Date value '%s' for field 'updated' is invalid", match[1]))
+			return
+		}
+	}
+
+	startAt, _ := strconv.Atoi(query.Get("startAt"))
+	maxResults := defaultMaxResults
+	if value := query.Get("maxResults"); value != "" {
+		maxResults, _ = strconv.Atoi(value)
+	}
+	expandChangelog := strings.Contains(query.Get("expand"), "changelog")
+
+	s.mu.Lock()
+	if !s.projectExists(projectKey) {
+		s.mu.Unlock()
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("The value '%s' does not exist for the field 'project'.", projectKey))
+		return
+	}
+	maxResults = min(maxResults, s.maxResults)
+
+	var found []*issue
+	for _, i := range s.issues {
+		// JQL сравнивает даты с точностью до минуты
+		if i.projectKey == projectKey && !i.updated.Truncate(time.Minute).Before(updatedSince) {
+			found = append(found, i)
+		}
+	}
+	s.mu.Unlock()
+
+	sort.Slice(found, func(a, b int) bool { return issueNumber(found[a].key) < issueNumber(found[b].key) })
+
+	page := make([]map[string]json.RawMessage, 0, maxResults)
+	for i := startAt; i < len(found) && len(page) < maxResults; i++ {
+		page = append(page, searchIssue(found[i], expandChangelog))
+	}
+
+	writeJSON(w, map[string]interface{}{
+		"startAt":    startAt,
+		"maxResults": maxResults,
+		"total":      len(found),
+		"issues":     page,
+	})
+}
+
+func (s *Server) projectExists(key string) bool {
+	for _, raw := range s.projects {
+		var project struct {
+			Key string `json:"key"`
+		}
+		if json.Unmarshal(raw, &project) == nil && project.Key == key {
+			return true
+		}
+	}
+	return false
+}
+
+// searchIssue - задача в выдаче поиска: changelog только при expand,
+// списаний не больше searchWorklogLimit.
+func searchIssue(i *issue, expandChangelog bool) map[string]json.RawMessage {
+	out := make(map[string]json.RawMessage, len(i.raw))
+	for name, value := range i.raw {
+		if name == "changelog" && !expandChangelog {
+			continue
+		}
+		out[name] = value
+	}
+
+	var fields map[string]json.RawMessage
+	if json.Unmarshal(i.raw["fields"], &fields) != nil {
+		return out
+	}
+	worklog, ok := fields["worklog"]
+	if !ok {
+		return out
+	}
+
+	var page struct {
+		Worklogs []json.RawMessage `json:"worklogs"`
+	}
+	if json.Unmarshal(worklog, &page) != nil || len(page.Worklogs) <= searchWorklogLimit {
+		return out
+	}
+	fields["worklog"], _ = json.Marshal(map[string]interface{}{
+		"startAt":    0,
+		"maxResults": searchWorklogLimit,
+		"total":      len(page.Worklogs),
+		"worklogs":   page.Worklogs[:searchWorklogLimit],
+	})
+	out["fields"], _ = json.Marshal(fields)
+	return out
+}
+
+// lookup возвращает задачу по ключу с учётом переносов.
+func (s *Server) lookup(key string) *issue {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for {
+		if i, ok := s.issues[key]; ok {
+			return i
+		}
+		newKey, ok := s.moved[key]
+		if !ok {
+			return nil
+		}
+		key = newKey
+	}
+}
+
+func (s *Server) getIssue(w http.ResponseWriter, r *http.Request) {
+	i := s.lookup(mux.Vars(r)["key"])
+	if i == nil {
+		writeError(w, http.StatusNotFound, "Issue does not exist or you do not have permission to see it.")
+		return
+	}
+	writeJSON(w, searchIssue(i, r.URL.Query().Get("expand") == "changelog"))
+}
+
+func (s *Server) getWorklogs(w http.ResponseWriter, r *http.Request) {
+	i := s.lookup(mux.Vars(r)["key"])
+	if i == nil {
+		writeError(w, http.StatusNotFound, "Issue does not exist or you do not have permission to see it.")
+		return
+	}
+
+	var fields struct {
+		Worklog struct {
+			Worklogs []json.RawMessage `json:"worklogs"`
+		} `json:"worklog"`
+	}
+	json.Unmarshal(i.raw["fields"], &fields)
+	worklogs := fields.Worklog.Worklogs
+
+	query := r.URL.Query()
+	startAt, _ := strconv.Atoi(query.Get("startAt"))
+	maxResults, err := strconv.Atoi(query.Get("maxResults"))
+	if err != nil {
+		maxResults = 5000
+	}
+	startAt = min(startAt, len(worklogs))
+	end := min(startAt+maxResults, len(worklogs))
+
+	writeJSON(w, map[string]interface{}{
+		"startAt":    startAt,
+		"maxResults": maxResults,
+		"total":      len(worklogs),
+		"worklogs":   append([]json.RawMessage{}, worklogs[startAt:end]...),
+	})
+}
+
+// getBoards - у проектов фикстур нет досок.
+func (s *Server) getBoards(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, map[string]interface{}{
+		"startAt":    0,
+		"maxResults": defaultMaxResults,
+		"isLast":     true,
+		"values":     []interface{}{},
+	})
+}
+
+func writeJSON(w http.ResponseWriter, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(value)
+}
+
+// writeError отвечает ошибкой в формате Jira.
+func writeError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"errorMessages": []string{message},
+		"errors":        map[string]string{},
+	})
+}
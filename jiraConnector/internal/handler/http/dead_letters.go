@@ -0,0 +1,83 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/gorilla/mux"
+	"jiraAnalyzer/jiraConnector/internal/service"
+	"net/http"
+	"strconv"
+)
+
+func (h *Handler) GetDeadLetters(w http.ResponseWriter, r *http.Request) {
+	limit, err := strconv.Atoi(r.URL.Query().Get("limit"))
+	if err != nil || limit <= 0 {
+		limit = 50 // Значение по умолчанию
+	}
+
+	// По умолчанию возвращаются только нерешённые записи
+	var resolved bool
+	if resolvedParam := r.URL.Query().Get("resolved"); resolvedParam != "" {
+		resolved, err = strconv.ParseBool(resolvedParam)
+		if err != nil {
+			http.Error(w, "Invalid resolved parameter", http.StatusBadRequest)
+			return
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), h.cfg.WriteTimeout)
+	defer cancel()
+
+	letters, err := h.etlService.ListDeadLetters(ctx, r.URL.Query().Get("project"), resolved, limit)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{"deadLetters": letters})
+}
+
+func (h *Handler) RetryDeadLetter(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid dead letter id", http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), h.cfg.WriteTimeout)
+	defer cancel()
+
+	letter, err := h.etlService.RetryDeadLetter(ctx, id)
+	if errors.Is(err, service.ErrDeadLetterNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	} else if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(letter)
+}
+
+func (h *Handler) RetryDeadLetters(w http.ResponseWriter, r *http.Request) {
+	projectKey := r.URL.Query().Get("project")
+	if projectKey == "" {
+		http.Error(w, "Missing project key", http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), h.cfg.WriteTimeout)
+	defer cancel()
+
+	result, err := h.etlService.RetryDeadLetters(ctx, projectKey)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(result)
+}
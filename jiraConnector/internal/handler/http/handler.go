@@ -3,6 +3,8 @@ package handler
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"github.com/gorilla/mux"
 	"jiraAnalyzer/jiraConnector/internal/models"
 	"jiraAnalyzer/jiraConnector/internal/service"
@@ -20,15 +22,27 @@ type JiraConnectorConfig struct {
 }
 
 type Handler struct {
-	etlService *service.ETLService
-	cfg        JiraConnectorConfig
+	etlService       *service.ETLService
+	jobService       *service.JobService
+	schedulerService *service.SchedulerService
+	webhookService   *service.WebhookService
+	cfg              JiraConnectorConfig
 }
 
-func NewHandler(etlService *service.ETLService, r *mux.Router, cfg JiraConnectorConfig) *mux.Router {
-	h := &Handler{etlService: etlService, cfg: cfg}
+func NewHandler(etlService *service.ETLService, jobService *service.JobService, schedulerService *service.SchedulerService, webhookService *service.WebhookService, r *mux.Router, cfg JiraConnectorConfig) *mux.Router {
+	h := &Handler{etlService: etlService, jobService: jobService, schedulerService: schedulerService, webhookService: webhookService, cfg: cfg}
 
 	r.HandleFunc("/updateProject", h.UpdateProject)
 	r.HandleFunc("/projects", h.GetProjects).Methods(http.MethodOptions, http.MethodGet)
+	r.HandleFunc("/jobs", h.GetJobs).Methods(http.MethodOptions, http.MethodGet)
+	r.HandleFunc("/jobs/{id:[0-9]+}", h.GetJob).Methods(http.MethodOptions, http.MethodGet)
+	r.HandleFunc("/jobs/{id:[0-9]+}/cancel", h.CancelJob).Methods(http.MethodOptions, http.MethodPost)
+	r.HandleFunc("/deadLetters", h.GetDeadLetters).Methods(http.MethodOptions, http.MethodGet)
+	r.HandleFunc("/deadLetters/retry", h.RetryDeadLetters).Methods(http.MethodOptions, http.MethodPost)
+	r.HandleFunc("/deadLetters/{id:[0-9]+}/retry", h.RetryDeadLetter).Methods(http.MethodOptions, http.MethodPost)
+	r.HandleFunc("/scheduler/runs", h.GetScheduleRuns).Methods(http.MethodOptions, http.MethodGet)
+	r.HandleFunc("/metrics/rateLimiter", h.GetRateLimiterMetrics).Methods(http.MethodOptions, http.MethodGet)
+	r.HandleFunc("/webhooks/jira", h.JiraWebhook).Methods(http.MethodPost)
 
 	return r
 }
@@ -43,17 +57,47 @@ func (h *Handler) UpdateProject(w http.ResponseWriter, r *http.Request) {
 	// Разделяем строку с ключами проектов по запятой
 	projectKeys := strings.Split(projectKeysParam, ",")
 
+	// По умолчанию загружаются только изменения с прошлой синхронизации,
+	// full=true принудительно перезагружает проект целиком
+	var opts service.SyncOptions
+	if fullParam := r.URL.Query().Get("full"); fullParam != "" {
+		full, err := strconv.ParseBool(fullParam)
+		if err != nil {
+			http.Error(w, "Invalid full parameter", http.StatusBadRequest)
+			return
+		}
+		opts.Full = full
+	}
+
+	// dryRun=true только сравнивает задачи Jira с базой, отчёт
+	// сохраняется в задаче синхронизации
+	if dryRunParam := r.URL.Query().Get("dryRun"); dryRunParam != "" {
+		dryRun, err := strconv.ParseBool(dryRunParam)
+		if err != nil {
+			http.Error(w, "Invalid dryRun parameter", http.StatusBadRequest)
+			return
+		}
+		if dryRun {
+			opts.DryRun = service.NewDryRunReport()
+		}
+	}
+
 	ctx, cancel := context.WithTimeout(r.Context(), h.cfg.ReadTimeout)
 	defer cancel()
 
-	err := h.etlService.UpdateProject(ctx, projectKeys)
-	if err != nil {
+	// Синхронизация выполняется в фоне, клиент следит за ней через /jobs/{id}
+	jobID, err := h.jobService.Enqueue(ctx, projectKeys, opts)
+	if errors.Is(err, service.ErrProjectBusy) {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	} else if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(map[string]string{"status": "success"})
+	w.Header().Set("Location", fmt.Sprintf("/jobs/%d", jobID))
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(map[string]interface{}{"status": models.JobStatusQueued, "jobId": jobID})
 }
 
 func (h *Handler) GetProjects(w http.ResponseWriter, r *http.Request) {
@@ -93,3 +137,9 @@ func (h *Handler) GetProjects(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(response)
 }
+
+// GetRateLimiterMetrics отдаёт состояние лимитеров запросов к Jira, GitHub и GitLab.
+func (h *Handler) GetRateLimiterMetrics(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(h.etlService.RateLimiterMetrics())
+}
@@ -0,0 +1,53 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"jiraAnalyzer/jiraConnector/internal/service"
+	"log"
+	"net/http"
+)
+
+// maxWebhookBodySize ограничивает размер тела доставки webhook
+const maxWebhookBodySize = 10 << 20
+
+// JiraWebhook принимает события webhook Jira. Ответ не 2xx заставляет
+// Jira повторить доставку.
+func (h *Handler) JiraWebhook(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxWebhookBodySize))
+	if err != nil {
+		http.Error(w, "Failed to read request body", http.StatusBadRequest)
+		return
+	}
+
+	err = h.webhookService.Verify(body, r.Header.Get("X-Hub-Signature"), r.URL.Query().Get("secret"))
+	if errors.Is(err, service.ErrWebhookDisabled) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	} else if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), h.cfg.WriteTimeout)
+	defer cancel()
+
+	status, err := h.webhookService.Handle(ctx, r.Header.Get("X-Atlassian-Webhook-Identifier"), body)
+	if errors.Is(err, service.ErrInvalidWebhookBody) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	} else if errors.Is(err, service.ErrWebhookInProgress) {
+		// Jira повторит доставку, когда первая попытка закончится
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	} else if err != nil {
+		log.Printf("Failed to handle jira webhook: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]string{"status": status})
+}
@@ -1,6 +1,10 @@
 package models
 
-import "time"
+import (
+	"encoding/json"
+	"github.com/lib/pq"
+	"time"
+)
 
 type PageInfo struct {
 	CurrentPage int `json:"currentPage"`
@@ -32,6 +36,8 @@ type DBIssue struct {
 	TimeSpent      int        `db:"time_spent"`
 	CreatorID      int        `db:"creator_id"`
 	AssigneeID     *int       `db:"assignee_id"`
+	// CustomFields - JSON-объект значений пользовательских полей по логическим именам
+	CustomFields []byte `db:"custom_fields"`
 }
 
 type DBChangelog struct {
@@ -43,7 +49,214 @@ type DBChangelog struct {
 	ToStatus   string    `db:"to_status"`
 }
 
+// DBFieldChange - изменение произвольного поля задачи из changelog Jira.
+type DBFieldChange struct {
+	ID         int       `db:"id"`
+	IssueID    string    `db:"issue_id"`
+	AuthorID   int       `db:"author_id"`
+	HistoryID  string    `db:"history_id"`
+	ItemIndex  int       `db:"item_index"`
+	Created    time.Time `db:"created"`
+	Field      string    `db:"field"`
+	FieldType  string    `db:"field_type"`
+	FromValue  *string   `db:"from_value"`
+	FromString *string   `db:"from_string"`
+	ToValue    *string   `db:"to_value"`
+	ToString   *string   `db:"to_string"`
+}
+
+type DBComment struct {
+	ID       int       `db:"id"`
+	JiraID   string    `db:"jira_id"`
+	IssueID  string    `db:"issue_id"`
+	AuthorID int       `db:"author_id"`
+	Created  time.Time `db:"created"`
+	Updated  time.Time `db:"updated"`
+	Body     string    `db:"body"`
+}
+
+type DBWorklog struct {
+	ID               int       `db:"id"`
+	JiraID           string    `db:"jira_id"`
+	IssueID          string    `db:"issue_id"`
+	AuthorID         int       `db:"author_id"`
+	Started          time.Time `db:"started"`
+	TimeSpentSeconds int       `db:"time_spent_seconds"`
+	Created          time.Time `db:"created"`
+	Updated          time.Time `db:"updated"`
+	Comment          *string   `db:"comment"`
+}
+
+type DBIssueLink struct {
+	ID        int    `db:"id"`
+	JiraID    string `db:"jira_id"`
+	SourceKey string `db:"source_key"`
+	TargetKey string `db:"target_key"`
+	LinkType  string `db:"link_type"`
+	Outward   string `db:"outward"`
+	Inward    string `db:"inward"`
+}
+
+// Состояния спринта в Jira Agile
+const (
+	SprintStateFuture = "future"
+	SprintStateActive = "active"
+	SprintStateClosed = "closed"
+)
+
+type DBSprint struct {
+	ID           int        `db:"id"`
+	BoardID      int        `db:"board_id"`
+	ProjectKey   string     `db:"project_key"`
+	Name         string     `db:"name"`
+	State        string     `db:"state"`
+	Goal         *string    `db:"goal"`
+	StartDate    *time.Time `db:"start_date"`
+	EndDate      *time.Time `db:"end_date"`
+	CompleteDate *time.Time `db:"complete_date"`
+}
+
+type DBComponent struct {
+	JiraID     string `db:"jira_id"`
+	ProjectKey string `db:"project_key"`
+	Name       string `db:"name"`
+}
+
+type DBVersion struct {
+	JiraID      string     `db:"jira_id"`
+	ProjectKey  string     `db:"project_key"`
+	Name        string     `db:"name"`
+	Released    bool       `db:"released"`
+	ReleaseDate *time.Time `db:"release_date"`
+}
+
+// DBIssueDimensions - метки, компоненты и fix-версии задачи.
+type DBIssueDimensions struct {
+	IssueKey    string
+	Labels      []string
+	Components  []DBComponent
+	FixVersions []DBVersion
+}
+
 type DBAuthor struct {
-	ID          int    `db:"id"`
-	DisplayName string `db:"display_name"`
+	ID          int     `db:"id"`
+	AccountID   string  `db:"account_id"`
+	DisplayName string  `db:"display_name"`
+	Email       *string `db:"email"`
+	Active      bool    `db:"active"`
+}
+
+// Статусы фоновых задач синхронизации
+const (
+	JobStatusQueued      = "queued"
+	JobStatusRunning     = "running"
+	JobStatusSucceeded   = "succeeded"
+	JobStatusFailed      = "failed"
+	JobStatusCancelled   = "cancelled"
+	JobStatusInterrupted = "interrupted"
+)
+
+type DBSyncJob struct {
+	ID            int            `db:"id" json:"id"`
+	ProjectKeys   pq.StringArray `db:"project_keys" json:"projectKeys"`
+	Full          bool           `db:"full_sync" json:"full"`
+	Status        string         `db:"status" json:"status"`
+	BatchesTotal  int            `db:"batches_total" json:"batchesTotal"`
+	BatchesDone   int            `db:"batches_done" json:"batchesDone"`
+	IssuesWritten int            `db:"issues_written" json:"issuesWritten"`
+	// IssuesDeleted и IssuesMoved - итоги сверки после полной синхронизации
+	IssuesDeleted int            `db:"issues_deleted" json:"issuesDeleted"`
+	IssuesMoved   int            `db:"issues_moved" json:"issuesMoved"`
+	Errors        pq.StringArray `db:"errors" json:"errors"`
+	CreatedAt     time.Time      `db:"created_at" json:"createdAt"`
+	StartedAt     *time.Time     `db:"started_at" json:"startedAt"`
+	FinishedAt    *time.Time     `db:"finished_at" json:"finishedAt"`
+	// DryRun - пробная синхронизация, Report - её отчёт
+	DryRun bool             `db:"dry_run" json:"dryRun"`
+	Report *json.RawMessage `db:"report" json:"report,omitempty"`
+}
+
+// Статусы синхронизаций проектов
+const (
+	SyncRunRunning   = "running"
+	SyncRunSucceeded = "succeeded"
+	// SyncRunAbandoned - синхронизацию заменила новая, не продолжая её
+	SyncRunAbandoned = "abandoned"
+)
+
+// DBSyncRun - синхронизация проекта, которую можно продолжить после сбоя.
+type DBSyncRun struct {
+	ID           int        `db:"id"`
+	ProjectKey   string     `db:"project_key"`
+	Full         bool       `db:"full_sync"`
+	UpdatedSince *time.Time `db:"updated_since"`
+	StartedAt    time.Time  `db:"started_at"`
+	Status       string     `db:"status"`
+	// Pass - номер прохода, каждое возобновление начинает новый
+	Pass       int        `db:"pass"`
+	FinishedAt *time.Time `db:"finished_at"`
+}
+
+// DBSyncCheckpoint - батч, закоммиченный синхронизацией.
+type DBSyncCheckpoint struct {
+	RunID     int            `db:"run_id"`
+	Pass      int            `db:"pass"`
+	StartAt   int            `db:"start_at"`
+	IssueKeys pq.StringArray `db:"issue_keys"`
+}
+
+// Этапы ETL, на которых задача попадает в dead letters
+const (
+	DeadLetterTransform = "transform"
+	DeadLetterPersist   = "persist"
+)
+
+// DBDeadLetter - задача, которую не удалось преобразовать или сохранить.
+type DBDeadLetter struct {
+	ID         int    `db:"id" json:"id"`
+	IssueKey   string `db:"issue_key" json:"issueKey"`
+	ProjectKey string `db:"project_key" json:"projectKey"`
+	SyncRunID  *int   `db:"sync_run_id" json:"syncRunId,omitempty"`
+	Stage      string `db:"stage" json:"stage"`
+	Error      string `db:"error" json:"error"`
+	// Raw - задача в модели REST API Jira, Partial - задача из webhook
+	// с неполными комментариями, списаниями и связями
+	Raw        json.RawMessage `db:"raw" json:"raw"`
+	Partial    bool            `db:"partial" json:"partial"`
+	Attempts   int             `db:"attempts" json:"attempts"`
+	CreatedAt  time.Time       `db:"created_at" json:"createdAt"`
+	UpdatedAt  time.Time       `db:"updated_at" json:"updatedAt"`
+	ResolvedAt *time.Time      `db:"resolved_at" json:"resolvedAt,omitempty"`
+	// SourceRaw - задача в формате GitHub или GitLab, из которой собрана Raw
+	SourceRaw json.RawMessage `db:"source_raw" json:"sourceRaw,omitempty"`
+}
+
+// Результаты попытки принять доставку webhook к обработке
+const (
+	// WebhookDeliveryClaimed - доставка принята, её обрабатывает этот запрос
+	WebhookDeliveryClaimed = "claimed"
+	// WebhookDeliveryPending - доставку обрабатывает другой запрос
+	WebhookDeliveryPending = "pending"
+	// WebhookDeliveryProcessed - доставка уже обработана
+	WebhookDeliveryProcessed = "processed"
+)
+
+// Статусы запусков планировщика
+const (
+	ScheduleRunStarted = "started"
+	ScheduleRunSkipped = "skipped"
+	ScheduleRunFailed  = "failed"
+)
+
+type DBScheduleRun struct {
+	ID           int       `db:"id" json:"id"`
+	ScheduleName string    `db:"schedule_name" json:"scheduleName"`
+	ProjectKey   string    `db:"project_key" json:"projectKey"`
+	Full         bool      `db:"full_sync" json:"full"`
+	JobID        *int      `db:"job_id" json:"jobId,omitempty"`
+	JobStatus    *string   `db:"job_status" json:"jobStatus,omitempty"`
+	Status       string    `db:"status" json:"status"`
+	Message      *string   `db:"message" json:"message,omitempty"`
+	ScheduledAt  time.Time `db:"scheduled_at" json:"scheduledAt"`
+	CreatedAt    time.Time `db:"created_at" json:"createdAt"`
 }
@@ -0,0 +1,71 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Ответы REST API GitHub Issues.
+
+type GitHubRepository struct {
+	FullName string `json:"full_name"`
+	Name     string `json:"name"`
+	HTMLURL  string `json:"html_url"`
+}
+
+type GitHubUser struct {
+	ID    int64  `json:"id"`
+	Login string `json:"login"`
+}
+
+type GitHubLabel struct {
+	Name string `json:"name"`
+}
+
+// GitHubIssue - задача GitHub. Список задач репозитория содержит
+// и pull request'ы, у них заполнено PullRequest.
+type GitHubIssue struct {
+	Number        int              `json:"number"`
+	Title         string           `json:"title"`
+	Body          string           `json:"body"`
+	State         string           `json:"state"`
+	User          GitHubUser       `json:"user"`
+	Assignee      *GitHubUser      `json:"assignee"`
+	Labels        []GitHubLabel    `json:"labels"`
+	Comments      int              `json:"comments"`
+	CreatedAt     time.Time        `json:"created_at"`
+	UpdatedAt     time.Time        `json:"updated_at"`
+	ClosedAt      *time.Time       `json:"closed_at"`
+	RepositoryURL string           `json:"repository_url"`
+	PullRequest   *json.RawMessage `json:"pull_request"`
+	Type          *GitHubIssueType `json:"type"`
+	// Raw - задача в том виде, в каком её прислал GitHub
+	Raw json.RawMessage `json:"-"`
+}
+
+func (i *GitHubIssue) UnmarshalJSON(data []byte) error {
+	type plain GitHubIssue
+	if err := json.Unmarshal(data, (*plain)(i)); err != nil {
+		return err
+	}
+	i.Raw = append(json.RawMessage(nil), data...)
+	return nil
+}
+
+type GitHubIssueType struct {
+	Name string `json:"name"`
+}
+
+// GitHubEvent - событие в хронологии задачи: commented, closed, reopened,
+// labeled, assigned и т.д. У комментария заполнены User, Body и UpdatedAt.
+type GitHubEvent struct {
+	ID        int64        `json:"id"`
+	Event     string       `json:"event"`
+	Actor     *GitHubUser  `json:"actor"`
+	CreatedAt time.Time    `json:"created_at"`
+	UpdatedAt time.Time    `json:"updated_at"`
+	Label     *GitHubLabel `json:"label"`
+	Assignee  *GitHubUser  `json:"assignee"`
+	User      *GitHubUser  `json:"user"`
+	Body      string       `json:"body"`
+}
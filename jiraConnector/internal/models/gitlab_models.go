@@ -0,0 +1,81 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Ответы REST API GitLab Issues.
+
+type GitLabProject struct {
+	PathWithNamespace string `json:"path_with_namespace"`
+	Name              string `json:"name"`
+	WebURL            string `json:"web_url"`
+}
+
+type GitLabUser struct {
+	ID       int64  `json:"id"`
+	Username string `json:"username"`
+	Name     string `json:"name"`
+	State    string `json:"state"`
+}
+
+type GitLabIssue struct {
+	IID            int          `json:"iid"`
+	Title          string       `json:"title"`
+	Description    string       `json:"description"`
+	State          string       `json:"state"`
+	IssueType      string       `json:"issue_type"`
+	Author         GitLabUser   `json:"author"`
+	Assignees      []GitLabUser `json:"assignees"`
+	Labels         []string     `json:"labels"`
+	UserNotesCount int          `json:"user_notes_count"`
+	CreatedAt      time.Time    `json:"created_at"`
+	UpdatedAt      time.Time    `json:"updated_at"`
+	ClosedAt       *time.Time   `json:"closed_at"`
+	TimeStats      struct {
+		// TotalTimeSpent - списанное время в секундах
+		TotalTimeSpent int `json:"total_time_spent"`
+	} `json:"time_stats"`
+	// Raw - задача в том виде, в каком её прислал GitLab
+	Raw json.RawMessage `json:"-"`
+}
+
+func (i *GitLabIssue) UnmarshalJSON(data []byte) error {
+	type plain GitLabIssue
+	if err := json.Unmarshal(data, (*plain)(i)); err != nil {
+		return err
+	}
+	i.Raw = append(json.RawMessage(nil), data...)
+	return nil
+}
+
+// GitLabNote - комментарий задачи. System-заметки GitLab создаёт сам
+// при изменениях задачи.
+type GitLabNote struct {
+	ID        int64      `json:"id"`
+	Body      string     `json:"body"`
+	Author    GitLabUser `json:"author"`
+	System    bool       `json:"system"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+// GitLabStateEvent - закрытие или переоткрытие задачи.
+type GitLabStateEvent struct {
+	ID        int64       `json:"id"`
+	User      *GitLabUser `json:"user"`
+	CreatedAt time.Time   `json:"created_at"`
+	State     string      `json:"state"`
+}
+
+// GitLabLabelEvent - добавление (add) или снятие (remove) метки.
+type GitLabLabelEvent struct {
+	ID        int64       `json:"id"`
+	User      *GitLabUser `json:"user"`
+	CreatedAt time.Time   `json:"created_at"`
+	Action    string      `json:"action"`
+	Label     *struct {
+		Name string `json:"name"`
+	} `json:"label"`
+}
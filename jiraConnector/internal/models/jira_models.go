@@ -1,5 +1,14 @@
 package models
 
+import (
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+// JiraTimeLayout - формат дат REST API Jira.
+const JiraTimeLayout = "2006-01-02T15:04:05.000-0700"
+
 type JiraProject struct {
 	Key  string `json:"key"`
 	Name string `json:"name"`
@@ -19,9 +28,28 @@ type JiraIssue struct {
 	Key       string        `json:"key"`
 	Fields    JiraFields    `json:"fields"`
 	Changelog JiraChangelog `json:"changelog"`
+	// LinksPartial - связи восстановлены не из Jira (выгрузка XML) и не
+	// заменяют сохранённые. Jira это поле не присылает.
+	LinksPartial bool `json:"linksPartial,omitempty"`
+	// Raw - задача в том виде, в каком её прислала Jira. Пусто у задач,
+	// собранных не из JSON Jira (GitHub, GitLab, выгрузка XML).
+	Raw json.RawMessage `json:"-"`
+	// SourceRaw - задача в формате GitHub или GitLab, из которой собрана
+	// эта. Нужна для разбора dead letters.
+	SourceRaw json.RawMessage `json:"-"`
+}
+
+func (i *JiraIssue) UnmarshalJSON(data []byte) error {
+	type plain JiraIssue
+	if err := json.Unmarshal(data, (*plain)(i)); err != nil {
+		return err
+	}
+	i.Raw = append(json.RawMessage(nil), data...)
+	return nil
 }
 
 type JiraFields struct {
+	Project     JiraProject  `json:"project"`
 	Created     string       `json:"created"`
 	Updated     string       `json:"updated"`
 	Summary     string       `json:"summary"`
@@ -32,6 +60,56 @@ type JiraFields struct {
 	TimeSpent   int          `json:"timespent"`
 	Creator     JiraAuthor   `json:"creator"`
 	Assignee    *JiraAuthor  `json:"assignee"`
+	Comment     JiraComments `json:"comment"`
+	// Worklog - nil, если источник не передаёт списания времени
+	Worklog     *JiraWorklogs   `json:"worklog,omitempty"`
+	IssueLinks  []JiraLink      `json:"issuelinks"`
+	Labels      []string        `json:"labels"`
+	Components  []JiraComponent `json:"components"`
+	FixVersions []JiraVersion   `json:"fixVersions"`
+	// Resolved - дата решения задачи, нужна источникам без истории изменений
+	Resolved string `json:"resolutiondate,omitempty"`
+	// Custom - значения полей customfield_*, разбираются по настройке CustomFields
+	Custom map[string]json.RawMessage `json:"-"`
+}
+
+func (f *JiraFields) UnmarshalJSON(data []byte) error {
+	type plain JiraFields
+	if err := json.Unmarshal(data, (*plain)(f)); err != nil {
+		return err
+	}
+
+	var all map[string]json.RawMessage
+	if err := json.Unmarshal(data, &all); err != nil {
+		return err
+	}
+
+	f.Custom = make(map[string]json.RawMessage)
+	for id, value := range all {
+		if strings.HasPrefix(id, "customfield_") {
+			f.Custom[id] = value
+		}
+	}
+	return nil
+}
+
+// MarshalJSON возвращает поля в формате Jira вместе с customfield_*,
+// чтобы сохранённую задачу можно было разобрать повторно.
+func (f JiraFields) MarshalJSON() ([]byte, error) {
+	type plain JiraFields
+	data, err := json.Marshal(plain(f))
+	if err != nil || len(f.Custom) == 0 {
+		return data, err
+	}
+
+	var all map[string]json.RawMessage
+	if err := json.Unmarshal(data, &all); err != nil {
+		return nil, err
+	}
+	for id, value := range f.Custom {
+		all[id] = value
+	}
+	return json.Marshal(all)
 }
 
 type JiraResolution struct {
@@ -50,8 +128,107 @@ type JiraStatus struct {
 	Name string `json:"name"`
 }
 
+// JiraAuthor - пользователь Jira. Jira Cloud идентифицирует пользователей
+// по accountId, Jira Server - по key и name.
 type JiraAuthor struct {
-	DisplayName string `json:"displayName"`
+	AccountID    string `json:"accountId"`
+	Key          string `json:"key"`
+	Name         string `json:"name"`
+	DisplayName  string `json:"displayName"`
+	EmailAddress string `json:"emailAddress"`
+	Active       bool   `json:"active"`
+}
+
+// Identity возвращает устойчивый идентификатор пользователя. Если Jira
+// не вернула ни одного идентификатора, используется отображаемое имя.
+func (a JiraAuthor) Identity() string {
+	switch {
+	case a.AccountID != "":
+		return a.AccountID
+	case a.Key != "":
+		return a.Key
+	case a.Name != "":
+		return a.Name
+	default:
+		return LegacyAuthorID(a.DisplayName)
+	}
+}
+
+// LegacyAuthorID - идентификатор автора, для которого известно только имя.
+func LegacyAuthorID(displayName string) string {
+	return "name:" + displayName
+}
+
+type JiraComponent struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type JiraVersion struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Released    bool   `json:"released"`
+	ReleaseDate string `json:"releaseDate"`
+}
+
+// JiraComments - комментарии задачи. Если Total больше длины Comments,
+// в ответ попала только часть комментариев.
+type JiraComments struct {
+	StartAt    int           `json:"startAt"`
+	MaxResults int           `json:"maxResults"`
+	Total      int           `json:"total"`
+	Comments   []JiraComment `json:"comments"`
+}
+
+type JiraComment struct {
+	ID      string     `json:"id"`
+	Author  JiraAuthor `json:"author"`
+	Body    string     `json:"body"`
+	Created string     `json:"created"`
+	Updated string     `json:"updated"`
+}
+
+// JiraWorklogs - списания времени по задаче. В поиске Jira отдаёт только
+// первые записи, остальные загружаются через /issue/{key}/worklog.
+type JiraWorklogs struct {
+	StartAt    int           `json:"startAt"`
+	MaxResults int           `json:"maxResults"`
+	Total      int           `json:"total"`
+	Worklogs   []JiraWorklog `json:"worklogs"`
+}
+
+// Truncated сообщает, что в ответ попала только часть списаний.
+func (w JiraWorklogs) Truncated() bool {
+	return w.StartAt > 0 || len(w.Worklogs) < w.Total
+}
+
+type JiraWorklog struct {
+	ID               string     `json:"id"`
+	Author           JiraAuthor `json:"author"`
+	Comment          string     `json:"comment"`
+	Started          string     `json:"started"`
+	Created          string     `json:"created"`
+	Updated          string     `json:"updated"`
+	TimeSpentSeconds int        `json:"timeSpentSeconds"`
+}
+
+// JiraLink - связь задачи. Заполнено одно из полей InwardIssue и OutwardIssue:
+// OutwardIssue - задача, к которой связь направлена от текущей.
+type JiraLink struct {
+	ID           string           `json:"id"`
+	Type         JiraLinkType     `json:"type"`
+	InwardIssue  *JiraLinkedIssue `json:"inwardIssue"`
+	OutwardIssue *JiraLinkedIssue `json:"outwardIssue"`
+}
+
+type JiraLinkType struct {
+	Name    string `json:"name"`
+	Inward  string `json:"inward"`
+	Outward string `json:"outward"`
+}
+
+type JiraLinkedIssue struct {
+	Key string `json:"key"`
 }
 
 type JiraChangelog struct {
@@ -59,13 +236,111 @@ type JiraChangelog struct {
 }
 
 type JiraHistory struct {
+	ID      string            `json:"id"`
 	Created string            `json:"created"`
 	Author  JiraAuthor        `json:"author"`
 	Items   []JiraHistoryItem `json:"items"`
 }
 
 type JiraHistoryItem struct {
-	Field      string `json:"field"`
-	FromString string `json:"fromString"`
-	ToString   string `json:"toString"`
+	Field      string  `json:"field"`
+	FieldType  string  `json:"fieldtype"`
+	From       *string `json:"from"`
+	FromString string  `json:"fromString"`
+	To         *string `json:"to"`
+	ToString   string  `json:"toString"`
+}
+
+// JiraAgilePage - общие поля постраничных ответов Agile REST API.
+type JiraAgilePage struct {
+	StartAt    int  `json:"startAt"`
+	MaxResults int  `json:"maxResults"`
+	Total      int  `json:"total"`
+	IsLast     bool `json:"isLast"`
+}
+
+type JiraBoardPage struct {
+	JiraAgilePage
+	Values []JiraBoard `json:"values"`
+}
+
+type JiraBoard struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+type JiraSprintPage struct {
+	JiraAgilePage
+	Values []JiraSprint `json:"values"`
+}
+
+type JiraSprint struct {
+	ID            int    `json:"id"`
+	Name          string `json:"name"`
+	State         string `json:"state"`
+	Goal          string `json:"goal"`
+	StartDate     string `json:"startDate"`
+	EndDate       string `json:"endDate"`
+	CompleteDate  string `json:"completeDate"`
+	OriginBoardID int    `json:"originBoardId"`
+}
+
+type JiraSprintIssuesPage struct {
+	JiraAgilePage
+	Issues []JiraLinkedIssue `json:"issues"`
+}
+
+// RateLimiterMetrics - состояние общего лимитера запросов к трекеру.
+type RateLimiterMetrics struct {
+	ConfiguredRate float64 `json:"configuredRate"`
+	CurrentRate    float64 `json:"currentRate"`
+	Burst          int     `json:"burst"`
+	Requests       int64   `json:"requests"`
+	// Throttled - количество ответов 429
+	Throttled      int64 `json:"throttled"`
+	RateReductions int64 `json:"rateReductions"`
+	// WaitSeconds - суммарное время ожидания запросов в лимитере
+	WaitSeconds float64 `json:"waitSeconds"`
+	// PausedUntil - до какого момента запросы приостановлены по Retry-After или X-RateLimit-Reset
+	PausedUntil        *time.Time `json:"pausedUntil,omitempty"`
+	RateLimitLimit     *int       `json:"rateLimitLimit,omitempty"`
+	RateLimitRemaining *int       `json:"rateLimitRemaining,omitempty"`
+	// Trackers - лимитеры GitHub и GitLab по источнику, верхний уровень - Jira
+	Trackers map[string]RateLimiterMetrics `json:"trackers,omitempty"`
+}
+
+// JiraIssueRef - текущий ключ и проект задачи. Для перенесённой задачи
+// Jira по старому ключу возвращает задачу с новым ключом.
+type JiraIssueRef struct {
+	Key    string `json:"key"`
+	Fields struct {
+		Project JiraProject `json:"project"`
+	} `json:"fields"`
+}
+
+// Типы событий webhook Jira
+const (
+	WebhookIssueCreated   = "jira:issue_created"
+	WebhookIssueUpdated   = "jira:issue_updated"
+	WebhookIssueDeleted   = "jira:issue_deleted"
+	WebhookCommentCreated = "comment_created"
+	WebhookCommentUpdated = "comment_updated"
+	WebhookCommentDeleted = "comment_deleted"
+)
+
+// JiraWebhookEvent - тело запроса webhook Jira. Changelog содержит только
+// изменение, вызвавшее событие, его автор - User, время - Timestamp.
+type JiraWebhookEvent struct {
+	Timestamp    int64                 `json:"timestamp"`
+	WebhookEvent string                `json:"webhookEvent"`
+	User         *JiraAuthor           `json:"user"`
+	Issue        *JiraIssue            `json:"issue"`
+	Changelog    *JiraWebhookChangelog `json:"changelog"`
+	Comment      *JiraComment          `json:"comment"`
+}
+
+type JiraWebhookChangelog struct {
+	ID    string            `json:"id"`
+	Items []JiraHistoryItem `json:"items"`
 }
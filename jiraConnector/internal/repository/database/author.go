@@ -1,24 +1,111 @@
 package database
 
 import (
+	"context"
 	"database/sql"
-	"errors"
 	"fmt"
+	"github.com/lib/pq"
+	"jiraAnalyzer/jiraConnector/internal/models"
+	"sort"
 )
 
-func (r *JiraPostgres) GetOrCreateAuthor(displayName string) (int, error) {
-	var authorID int
+// GetAuthors возвращает всех известных авторов. Для автора, объединённого
+// с другим, id - это id основного автора.
+func (r *JiraPostgres) GetAuthors(ctx context.Context) ([]models.DBAuthor, error) {
+	query := `
+        SELECT COALESCE(merged_into, id) AS id, account_id, display_name, email, active
+        FROM authors
+    `
 
-	createAuthorQuery := "INSERT INTO authors (display_name) VALUES ($1) ON CONFLICT (display_name) DO NOTHING RETURNING id"
-	err := r.db.QueryRow(createAuthorQuery, displayName).Scan(&authorID)
-	if errors.Is(err, sql.ErrNoRows) {
-		err = r.db.QueryRow("SELECT id FROM authors WHERE display_name = $1", displayName).Scan(&authorID)
-		if err != nil {
-			return 0, fmt.Errorf("failed to get author ID: %w", err)
+	var authors []models.DBAuthor
+	if err := r.db.SelectContext(ctx, &authors, query); err != nil {
+		return nil, fmt.Errorf("failed to get authors: %w", err)
+	}
+
+	return authors, nil
+}
+
+// SaveAuthorsTx сохраняет авторов одним запросом и возвращает их id по
+// идентификатору Jira. Имя, email и активность обновляются, прежние имена
+// остаются в author_names.
+func (r *JiraPostgres) SaveAuthorsTx(tx *sql.Tx, authors []models.DBAuthor) (map[string]int, error) {
+	ids := make(map[string]int, len(authors))
+	if len(authors) == 0 {
+		return ids, nil
+	}
+
+	// Одинаковый порядок блокировок строк в конкурентных батчах
+	sort.Slice(authors, func(i, j int) bool { return authors[i].AccountID < authors[j].AccountID })
+
+	accountIDs := make([]string, len(authors))
+	names := make([]string, len(authors))
+	emails := make([]string, len(authors))
+	active := make([]bool, len(authors))
+	for i, a := range authors {
+		accountIDs[i] = a.AccountID
+		names[i] = a.DisplayName
+		if a.Email != nil {
+			emails[i] = *a.Email
+		}
+		active[i] = a.Active
+	}
+	params := []interface{}{pq.Array(accountIDs), pq.Array(names), pq.Array(emails), pq.Array(active)}
+
+	// Автор, сохранённый до появления идентификаторов, привязывается
+	// к пользователю с тем же именем. Если в батче несколько пользователей
+	// с этим именем, выбрать нельзя: такой автор объединяется вручную через
+	// /api/v1/authors/{id}/merge
+	claimQuery := `
+        UPDATE authors a SET account_id = i.account_id
+        FROM (
+            SELECT min(account_id) AS account_id, display_name
+            FROM unnest($1::text[], $2::text[]) AS u(account_id, display_name)
+            GROUP BY display_name
+            HAVING count(*) = 1
+        ) i
+        WHERE a.account_id = 'name:' || i.display_name
+          AND a.account_id <> i.account_id
+          AND NOT EXISTS (SELECT 1 FROM authors x WHERE x.account_id = i.account_id)
+    `
+	if _, err := tx.Exec(claimQuery, params[0], params[1]); err != nil {
+		return nil, fmt.Errorf("failed to claim legacy authors: %w", err)
+	}
+
+	query := `
+        WITH upserted AS (
+            INSERT INTO authors (account_id, display_name, email, active)
+            SELECT account_id, display_name, NULLIF(email, ''), active
+            FROM unnest($1::text[], $2::text[], $3::text[], $4::bool[]) AS i(account_id, display_name, email, active)
+            ON CONFLICT (account_id) DO UPDATE SET
+                display_name = EXCLUDED.display_name,
+                email = COALESCE(EXCLUDED.email, authors.email),
+                active = EXCLUDED.active
+            RETURNING account_id, display_name, COALESCE(merged_into, id) AS id
+        ), names AS (
+            INSERT INTO author_names (author_id, display_name)
+            SELECT DISTINCT id, display_name FROM upserted
+            ON CONFLICT (author_id, display_name) DO UPDATE SET last_seen = NOW()
+        )
+        SELECT account_id, id FROM upserted
+    `
+
+	rows, err := tx.Query(query, params...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save authors: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var accountID string
+		var id int
+		if err := rows.Scan(&accountID, &id); err != nil {
+			return nil, fmt.Errorf("failed to scan author id: %w", err)
 		}
-	} else if err != nil {
-		return 0, fmt.Errorf("failed to insert author: %w", err)
+		ids[accountID] = id
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to save authors: %w", err)
 	}
 
-	return authorID, nil
+	return ids, nil
 }
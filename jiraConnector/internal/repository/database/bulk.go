@@ -0,0 +1,76 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"github.com/lib/pq"
+	"strings"
+)
+
+// bulkUpsert загружает строки во временную таблицу через COPY и переносит
+// их в table одним INSERT ... ON CONFLICT. Это на порядки быстрее, чем
+// построчный INSERT: вместо запроса на строку выполняется четыре запроса
+// на весь батч.
+//
+// conflictColumns - уникальный ключ table. Из дубликатов ключа внутри
+// батча сохраняется последняя строка rows, иначе ON CONFLICT DO UPDATE
+// завершится ошибкой.
+// onConflict - действие при конфликте: "DO NOTHING" или "DO UPDATE SET ...".
+func bulkUpsert(tx *sql.Tx, table string, columns, conflictColumns []string, onConflict string, rows [][]interface{}) error {
+	if len(rows) == 0 {
+		return nil
+	}
+
+	stage := "stage_" + table
+	columnList := strings.Join(columns, ", ")
+	conflictList := strings.Join(conflictColumns, ", ")
+
+	// Временная таблица живёт до конца транзакции. Если метод вызывается
+	// повторно в той же транзакции, таблица уже есть и её нужно очистить.
+	_, err := tx.Exec(fmt.Sprintf(
+		"CREATE TEMP TABLE IF NOT EXISTS %s ON COMMIT DROP AS SELECT %s FROM %s WITH NO DATA",
+		stage, columnList, table,
+	))
+	if err != nil {
+		return fmt.Errorf("failed to create staging table for %s: %w", table, err)
+	}
+	// Порядковый номер строки во временной таблице: COPY заполняет его
+	// по порядку rows, и DISTINCT ON оставляет последний дубликат.
+	_, err = tx.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN IF NOT EXISTS stage_position bigserial", stage))
+	if err != nil {
+		return fmt.Errorf("failed to number staging table for %s: %w", table, err)
+	}
+	if _, err := tx.Exec("TRUNCATE " + stage); err != nil {
+		return fmt.Errorf("failed to truncate staging table for %s: %w", table, err)
+	}
+
+	stmt, err := tx.Prepare(pq.CopyIn(stage, columns...))
+	if err != nil {
+		return fmt.Errorf("failed to prepare copy into %s: %w", stage, err)
+	}
+	for _, row := range rows {
+		if _, err := stmt.Exec(row...); err != nil {
+			stmt.Close()
+			return fmt.Errorf("failed to copy row into %s: %w", stage, err)
+		}
+	}
+	// Exec без аргументов отправляет накопленные строки на сервер
+	if _, err := stmt.Exec(); err != nil {
+		stmt.Close()
+		return fmt.Errorf("failed to flush copy into %s: %w", stage, err)
+	}
+	if err := stmt.Close(); err != nil {
+		return fmt.Errorf("failed to finish copy into %s: %w", stage, err)
+	}
+
+	_, err = tx.Exec(fmt.Sprintf(`
+        INSERT INTO %s (%s)
+        SELECT DISTINCT ON (%s) %s FROM %s ORDER BY %s, stage_position DESC
+        ON CONFLICT (%s) %s
+    `, table, columnList, conflictList, columnList, stage, conflictList, conflictList, onConflict))
+	if err != nil {
+		return fmt.Errorf("failed to merge staged rows into %s: %w", table, err)
+	}
+
+	return nil
+}
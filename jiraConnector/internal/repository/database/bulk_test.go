@@ -0,0 +1,242 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"github.com/jmoiron/sqlx"
+	"jiraAnalyzer/jiraConnector/internal/models"
+	"os"
+	"testing"
+	"time"
+)
+
+// Тесты и бенчмарки пишут в БД со схемой из db/schema. Строка подключения задаётся
+// в JIRA_CONNECTOR_TEST_DSN, все изменения откатываются:
+//
+//	JIRA_CONNECTOR_TEST_DSN="host=localhost user=pguser password=pgpwd dbname=testdb sslmode=disable" \
+//	    go test -run '^$' -bench SaveIssues ./jiraConnector/internal/repository/database/
+//
+// Без JIRA_CONNECTOR_TEST_DSN они пропускаются.
+const testDSNEnv = "JIRA_CONNECTOR_TEST_DSN"
+
+const benchProjectKey = "BENCH"
+
+// changelogsPerIssue - переходов статуса на задачу в тестовых данных
+const changelogsPerIssue = 4
+
+func openTestDB(b testing.TB) *JiraPostgres {
+	dsn := os.Getenv(testDSNEnv)
+	if dsn == "" {
+		b.Skipf("%s is not set", testDSNEnv)
+	}
+
+	db, err := sqlx.Open("postgres", dsn)
+	if err != nil {
+		b.Fatalf("failed to open database: %v", err)
+	}
+	if err := db.Ping(); err != nil {
+		b.Fatalf("failed to connect to database: %v", err)
+	}
+	b.Cleanup(func() { db.Close() })
+
+	return NewJiraPostgres(db)
+}
+
+// beginBenchTx открывает транзакцию с проектом и автором для тестовых задач.
+func beginBenchTx(b testing.TB, r *JiraPostgres) (*sql.Tx, int) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		b.Fatalf("failed to begin transaction: %v", err)
+	}
+
+	_, err = tx.Exec("INSERT INTO projects (key, name, url) VALUES ($1, $1, '') ON CONFLICT (key) DO NOTHING", benchProjectKey)
+	if err != nil {
+		b.Fatalf("failed to create project: %v", err)
+	}
+
+	var authorID int
+	err = tx.QueryRow(`
+        INSERT INTO authors (account_id, display_name) VALUES ('bench', 'Bench')
+        ON CONFLICT (account_id) DO UPDATE SET display_name = EXCLUDED.display_name
+        RETURNING id
+    `).Scan(&authorID)
+	if err != nil {
+		b.Fatalf("failed to create author: %v", err)
+	}
+
+	return tx, authorID
+}
+
+func benchData(n, authorID int) ([]models.DBIssue, []models.DBChangelog) {
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	statuses := []string{"Open", "In Progress", "In Review", "Resolved", "Closed"}
+
+	issues := make([]models.DBIssue, n)
+	changelogs := make([]models.DBChangelog, 0, n*changelogsPerIssue)
+	for i := range issues {
+		created := start.Add(time.Duration(i) * time.Minute)
+		issues[i] = models.DBIssue{
+			Key:          fmt.Sprintf("%s-%d", benchProjectKey, i+1),
+			ProjectKey:   benchProjectKey,
+			Created:      created,
+			Updated:      created.Add(time.Hour),
+			Summary:      fmt.Sprintf("Issue %d", i+1),
+			Type:         "Bug",
+			Priority:     "Major",
+			Status:       statuses[len(statuses)-1],
+			CreatorID:    authorID,
+			AssigneeID:   &authorID,
+			CustomFields: []byte("{}"),
+		}
+
+		for j := 0; j < changelogsPerIssue; j++ {
+			changelogs = append(changelogs, models.DBChangelog{
+				IssueID:    issues[i].Key,
+				AuthorID:   authorID,
+				Created:    created.Add(time.Duration(j+1) * time.Minute),
+				FromStatus: statuses[j],
+				ToStatus:   statuses[j+1],
+			})
+		}
+	}
+
+	return issues, changelogs
+}
+
+type saveFunc func(r *JiraPostgres, tx *sql.Tx, issues []models.DBIssue, changelogs []models.DBChangelog) error
+
+func benchmarkSave(b *testing.B, save saveFunc) {
+	r := openTestDB(b)
+
+	for _, n := range []int{100, 1000, 10000} {
+		b.Run(fmt.Sprintf("issues=%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				b.StopTimer()
+				tx, authorID := beginBenchTx(b, r)
+				issues, changelogs := benchData(n, authorID)
+				b.StartTimer()
+
+				if err := save(r, tx, issues, changelogs); err != nil {
+					b.Fatal(err)
+				}
+
+				b.StopTimer()
+				tx.Rollback()
+				b.StartTimer()
+			}
+			b.ReportMetric(float64(n*b.N)/b.Elapsed().Seconds(), "issues/s")
+		})
+	}
+}
+
+func TestSaveIssuesKeepsLastDuplicate(t *testing.T) {
+	r := openTestDB(t)
+	tx, authorID := beginBenchTx(t, r)
+	defer tx.Rollback()
+
+	issues, _ := benchData(2, authorID)
+	// Задача попала в батч дважды, например при сдвиге страниц поиска
+	duplicate := issues[0]
+	duplicate.Summary = "Updated summary"
+	duplicate.Status = "Reopened"
+	issues = append(issues, duplicate)
+
+	if err := r.SaveIssuesTx(tx, issues); err != nil {
+		t.Fatal(err)
+	}
+	// Повторное сохранение в той же транзакции обновляет задачу
+	renamed := issues[1]
+	renamed.Summary = "Renamed"
+	if err := r.SaveIssuesTx(tx, []models.DBIssue{renamed}); err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]string{
+		issues[0].Key: "Updated summary",
+		issues[1].Key: "Renamed",
+	}
+	for key, summary := range want {
+		var got, status string
+		err := tx.QueryRow("SELECT summary, status FROM issues WHERE key = $1", key).Scan(&got, &status)
+		if err != nil {
+			t.Fatalf("failed to read %s: %v", key, err)
+		}
+		if got != summary {
+			t.Errorf("%s summary = %q, want %q", key, got, summary)
+		}
+		if key == duplicate.Key && status != duplicate.Status {
+			t.Errorf("%s status = %q, want %q", key, status, duplicate.Status)
+		}
+	}
+}
+
+func BenchmarkSaveIssuesBulk(b *testing.B) {
+	benchmarkSave(b, func(r *JiraPostgres, tx *sql.Tx, issues []models.DBIssue, changelogs []models.DBChangelog) error {
+		if err := r.SaveIssuesTx(tx, issues); err != nil {
+			return err
+		}
+		return r.SaveChangelogTx(tx, changelogs)
+	})
+}
+
+func BenchmarkSaveIssuesRowByRow(b *testing.B) {
+	benchmarkSave(b, func(_ *JiraPostgres, tx *sql.Tx, issues []models.DBIssue, changelogs []models.DBChangelog) error {
+		if err := saveIssuesRowByRow(tx, issues); err != nil {
+			return err
+		}
+		return saveChangelogRowByRow(tx, changelogs)
+	})
+}
+
+// saveIssuesRowByRow - прежняя запись задач построчным INSERT, база для сравнения.
+func saveIssuesRowByRow(tx *sql.Tx, issues []models.DBIssue) error {
+	stmt, err := tx.Prepare(`
+        INSERT INTO issues (
+            key, project_key, created, updated, closed,
+            summary, description, issue_type, priority, status,
+            time_spent, creator_id, assignee_id, custom_fields
+        ) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+        ON CONFLICT (key) DO UPDATE SET
+            updated = EXCLUDED.updated,
+            status = EXCLUDED.status,
+            custom_fields = EXCLUDED.custom_fields
+    `)
+	if err != nil {
+		return fmt.Errorf("failed to prepare statement: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, issue := range issues {
+		_, err := stmt.Exec(
+			issue.Key, issue.ProjectKey, issue.Created, issue.Updated, issue.Closed,
+			issue.Summary, issue.Description, issue.Type, issue.Priority, issue.Status,
+			issue.TimeSpent, issue.CreatorID, issue.AssigneeID, string(issue.CustomFields),
+		)
+		if err != nil {
+			return fmt.Errorf("failed to execute statement: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// saveChangelogRowByRow - прежняя запись переходов статусов построчным INSERT.
+func saveChangelogRowByRow(tx *sql.Tx, changelogs []models.DBChangelog) error {
+	stmt, err := tx.Prepare(`
+        INSERT INTO status_changes (issue_id, author_id, created, from_status, to_status)
+        VALUES ($1, $2, $3, $4, $5)
+        ON CONFLICT (issue_id, created) DO NOTHING
+    `)
+	if err != nil {
+		return fmt.Errorf("failed to prepare statement: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, cl := range changelogs {
+		if _, err := stmt.Exec(cl.IssueID, cl.AuthorID, cl.Created, cl.FromStatus, cl.ToStatus); err != nil {
+			return fmt.Errorf("failed to execute statement: %w", err)
+		}
+	}
+
+	return nil
+}
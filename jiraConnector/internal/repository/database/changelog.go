@@ -1,28 +1,43 @@
 package database
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
+	"github.com/lib/pq"
 	"jiraAnalyzer/jiraConnector/internal/models"
 )
 
 func (r *JiraPostgres) SaveChangelogTx(tx *sql.Tx, changelogs []models.DBChangelog) error {
-	stmt, err := tx.Prepare(`
-        INSERT INTO status_changes (issue_id, author_id, created, from_status, to_status)
-        VALUES ($1, $2, $3, $4, $5)
-        ON CONFLICT (issue_id, created) DO NOTHING
-    `)
-	if err != nil {
-		return fmt.Errorf("failed to prepare statement: %w", err)
+	rows := make([][]interface{}, len(changelogs))
+	for i, cl := range changelogs {
+		rows[i] = []interface{}{cl.IssueID, cl.AuthorID, cl.Created, cl.FromStatus, cl.ToStatus}
+	}
+
+	return bulkUpsert(tx, "status_changes",
+		[]string{"issue_id", "author_id", "created", "from_status", "to_status"},
+		[]string{"issue_id", "created"},
+		"DO NOTHING",
+		rows,
+	)
+}
+
+// GetStatusChanges возвращает сохранённые переходы статусов задач.
+func (r *JiraPostgres) GetStatusChanges(ctx context.Context, issueKeys []string) ([]models.DBChangelog, error) {
+	if len(issueKeys) == 0 {
+		return nil, nil
 	}
-	defer stmt.Close()
 
-	for _, cl := range changelogs {
-		_, err := stmt.Exec(cl.IssueID, cl.AuthorID, cl.Created, cl.FromStatus, cl.ToStatus)
-		if err != nil {
-			return fmt.Errorf("failed to execute statement: %w", err)
-		}
+	var changes []models.DBChangelog
+	err := r.db.SelectContext(ctx, &changes, `
+        SELECT id, issue_id, COALESCE(author_id, 0) AS author_id, created,
+               COALESCE(from_status, '') AS from_status, COALESCE(to_status, '') AS to_status
+        FROM status_changes
+        WHERE issue_id = ANY($1)
+    `, pq.Array(issueKeys))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get status changes: %w", err)
 	}
 
-	return nil
+	return changes, nil
 }
@@ -0,0 +1,57 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/lib/pq"
+	"jiraAnalyzer/jiraConnector/internal/models"
+)
+
+func (r *JiraPostgres) SaveCommentsTx(tx *sql.Tx, comments []models.DBComment) error {
+	rows := make([][]interface{}, len(comments))
+	for i, c := range comments {
+		rows[i] = []interface{}{c.JiraID, c.IssueID, c.AuthorID, c.Created, c.Updated, c.Body}
+	}
+
+	return bulkUpsert(tx, "comments",
+		[]string{"jira_id", "issue_id", "author_id", "created", "updated", "body"},
+		[]string{"jira_id"},
+		`DO UPDATE SET
+            issue_id = EXCLUDED.issue_id,
+            updated = EXCLUDED.updated,
+            body = EXCLUDED.body`,
+		rows,
+	)
+}
+
+// DeleteStaleCommentsTx удаляет комментарии задач, которых больше нет в Jira.
+// keepIDs - идентификаторы комментариев, полученные при синхронизации.
+func (r *JiraPostgres) DeleteStaleCommentsTx(tx *sql.Tx, issueKeys []string, keepIDs []string) error {
+	if len(issueKeys) == 0 {
+		return nil
+	}
+	// nil-срез передаётся как NULL, и условие ниже не удалило бы ни одной строки
+	if keepIDs == nil {
+		keepIDs = []string{}
+	}
+
+	_, err := tx.Exec(`
+        DELETE FROM comments
+        WHERE issue_id = ANY($1) AND NOT (jira_id = ANY($2))
+    `, pq.Array(issueKeys), pq.Array(keepIDs))
+	if err != nil {
+		return fmt.Errorf("failed to delete stale comments: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteComment удаляет комментарий, удалённый в Jira.
+func (r *JiraPostgres) DeleteComment(ctx context.Context, jiraID string) error {
+	_, err := r.db.ExecContext(ctx, "DELETE FROM comments WHERE jira_id = $1", jiraID)
+	if err != nil {
+		return fmt.Errorf("failed to delete comment: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/lib/pq"
+	"jiraAnalyzer/jiraConnector/internal/models"
+)
+
+const deadLetterColumns = `
+    id, issue_key, project_key, sync_run_id, stage, error, raw, partial,
+    source_raw, attempts, created_at, updated_at, resolved_at
+`
+
+// IsDataError сообщает, что PostgreSQL отклонил сами данные: строка
+// длиннее поля, неверная дата, нарушение ограничения. Повтор с теми же
+// данными снова упадёт, в отличие от ошибок соединения и транзакции.
+func IsDataError(err error) bool {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return false
+	}
+	switch pqErr.Code.Class() {
+	case "22", "23": // data_exception, integrity_constraint_violation
+		return true
+	default:
+		return false
+	}
+}
+
+// SaveDeadLettersTx сохраняет задачи, не прошедшие ETL. Если у задачи уже
+// есть нерешённая запись, она обновляется и число попыток растёт.
+func (r *JiraPostgres) SaveDeadLettersTx(tx *sql.Tx, letters []models.DBDeadLetter) error {
+	for _, l := range letters {
+		_, err := tx.Exec(`
+            INSERT INTO etl_dead_letters (issue_key, project_key, sync_run_id, stage, error, raw, partial, source_raw)
+            VALUES ($1, $2, $3, $4, $5, $6, $7, NULLIF($8, '')::jsonb)
+            ON CONFLICT (issue_key) WHERE resolved_at IS NULL DO UPDATE SET
+                project_key = EXCLUDED.project_key,
+                sync_run_id = COALESCE(EXCLUDED.sync_run_id, etl_dead_letters.sync_run_id),
+                stage = EXCLUDED.stage,
+                error = EXCLUDED.error,
+                raw = EXCLUDED.raw,
+                partial = EXCLUDED.partial,
+                source_raw = EXCLUDED.source_raw,
+                attempts = etl_dead_letters.attempts + 1,
+                updated_at = CURRENT_TIMESTAMP
+        `, l.IssueKey, l.ProjectKey, l.SyncRunID, l.Stage, l.Error, string(l.Raw), l.Partial, string(l.SourceRaw))
+		if err != nil {
+			return fmt.Errorf("failed to save dead letter for %s: %w", l.IssueKey, err)
+		}
+	}
+
+	return nil
+}
+
+// ResolveDeadLettersTx закрывает записи задач, которые удалось сохранить.
+func (r *JiraPostgres) ResolveDeadLettersTx(tx *sql.Tx, issueKeys []string) error {
+	if len(issueKeys) == 0 {
+		return nil
+	}
+
+	_, err := tx.Exec(`
+        UPDATE etl_dead_letters SET resolved_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+        WHERE issue_key = ANY($1) AND resolved_at IS NULL
+    `, pq.Array(issueKeys))
+	if err != nil {
+		return fmt.Errorf("failed to resolve dead letters: %w", err)
+	}
+
+	return nil
+}
+
+func (r *JiraPostgres) GetDeadLetter(ctx context.Context, id int) (models.DBDeadLetter, error) {
+	var letter models.DBDeadLetter
+	err := r.db.GetContext(ctx, &letter, "SELECT"+deadLetterColumns+"FROM etl_dead_letters WHERE id = $1", id)
+	if err != nil {
+		return models.DBDeadLetter{}, fmt.Errorf("failed to get dead letter: %w", err)
+	}
+
+	return letter, nil
+}
+
+// ListDeadLetters возвращает последние записи, нерешённые или все. Пустой
+// projectKey - записи всех проектов.
+func (r *JiraPostgres) ListDeadLetters(ctx context.Context, projectKey string, resolved bool, limit int) ([]models.DBDeadLetter, error) {
+	var letters []models.DBDeadLetter
+	err := r.db.SelectContext(ctx, &letters, "SELECT"+deadLetterColumns+`
+        FROM etl_dead_letters
+        WHERE ($1 = '' OR project_key = $1) AND ($2 OR resolved_at IS NULL)
+        ORDER BY id DESC
+        LIMIT $3
+    `, projectKey, resolved, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list dead letters: %w", err)
+	}
+
+	return letters, nil
+}
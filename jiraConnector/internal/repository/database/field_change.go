@@ -0,0 +1,58 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/lib/pq"
+	"jiraAnalyzer/jiraConnector/internal/models"
+)
+
+func (r *JiraPostgres) SaveFieldChangesTx(tx *sql.Tx, changes []models.DBFieldChange) error {
+	rows := make([][]interface{}, len(changes))
+	for i, ch := range changes {
+		rows[i] = []interface{}{
+			ch.IssueID,
+			ch.AuthorID,
+			ch.HistoryID,
+			ch.ItemIndex,
+			ch.Created,
+			ch.Field,
+			ch.FieldType,
+			ch.FromValue,
+			ch.FromString,
+			ch.ToValue,
+			ch.ToString,
+		}
+	}
+
+	return bulkUpsert(tx, "field_changes",
+		[]string{
+			"issue_id", "author_id", "history_id", "item_index", "created", "field",
+			"field_type", "from_value", "from_string", "to_value", "to_string",
+		},
+		[]string{"issue_id", "history_id", "item_index"},
+		"DO NOTHING",
+		rows,
+	)
+}
+
+// GetFieldChanges возвращает сохранённые изменения полей задач.
+func (r *JiraPostgres) GetFieldChanges(ctx context.Context, issueKeys []string) ([]models.DBFieldChange, error) {
+	if len(issueKeys) == 0 {
+		return nil, nil
+	}
+
+	var changes []models.DBFieldChange
+	err := r.db.SelectContext(ctx, &changes, `
+        SELECT id, issue_id, COALESCE(author_id, 0) AS author_id, history_id, item_index, created,
+               field, COALESCE(field_type, '') AS field_type, from_value, from_string, to_value, to_string
+        FROM field_changes
+        WHERE issue_id = ANY($1)
+    `, pq.Array(issueKeys))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get field changes: %w", err)
+	}
+
+	return changes, nil
+}
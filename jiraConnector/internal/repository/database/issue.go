@@ -1,29 +1,17 @@
 package database
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
+	"github.com/lib/pq"
 	"jiraAnalyzer/jiraConnector/internal/models"
 )
 
 func (r *JiraPostgres) SaveIssuesTx(tx *sql.Tx, issues []models.DBIssue) error {
-	stmt, err := tx.Prepare(`
-        INSERT INTO issues (
-            key, project_key, created, updated, closed,
-            summary, description, issue_type, priority, status,
-            time_spent, creator_id, assignee_id
-        ) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
-        ON CONFLICT (key) DO UPDATE SET
-            updated = EXCLUDED.updated,
-            status = EXCLUDED.status
-    `)
-	if err != nil {
-		return fmt.Errorf("failed to prepare statement: %w", err)
-	}
-	defer stmt.Close()
-
-	for _, issue := range issues {
-		_, err := stmt.Exec(
+	rows := make([][]interface{}, len(issues))
+	for i, issue := range issues {
+		rows[i] = []interface{}{
 			issue.Key,
 			issue.ProjectKey,
 			issue.Created,
@@ -37,11 +25,73 @@ func (r *JiraPostgres) SaveIssuesTx(tx *sql.Tx, issues []models.DBIssue) error {
 			issue.TimeSpent,
 			issue.CreatorID,
 			issue.AssigneeID,
-		)
-		if err != nil {
-			return fmt.Errorf("failed to execute statement: %w", err)
+			string(issue.CustomFields),
 		}
 	}
 
-	return nil
+	return bulkUpsert(tx, "issues",
+		[]string{
+			"key", "project_key", "created", "updated", "closed",
+			"summary", "description", "issue_type", "priority", "status",
+			"time_spent", "creator_id", "assignee_id", "custom_fields",
+		},
+		[]string{"key"},
+		// Задача могла быть перенесена или восстановлена в Jira, поэтому
+		// обновляются все поля и снимается пометка об удалении. Время
+		// закрытия берётся из истории, а у задачи из webhook история
+		// неполная, поэтому известное время закрытия не сбрасывается.
+		// Доставки webhook могут прийти не по порядку, поэтому более старая
+		// версия задачи не затирает сохранённую.
+		`DO UPDATE SET
+            project_key = EXCLUDED.project_key,
+            created = EXCLUDED.created,
+            updated = EXCLUDED.updated,
+            closed = COALESCE(EXCLUDED.closed, issues.closed),
+            summary = EXCLUDED.summary,
+            description = EXCLUDED.description,
+            issue_type = EXCLUDED.issue_type,
+            priority = EXCLUDED.priority,
+            status = EXCLUDED.status,
+            time_spent = EXCLUDED.time_spent,
+            creator_id = EXCLUDED.creator_id,
+            assignee_id = EXCLUDED.assignee_id,
+            custom_fields = EXCLUDED.custom_fields,
+            deleted_at = NULL,
+            moved_to = NULL
+        WHERE issues.updated <= EXCLUDED.updated`,
+		rows,
+	)
+}
+
+// CheckIssueExists сообщает, загружена ли задача.
+func (r *JiraPostgres) CheckIssueExists(ctx context.Context, issueKey string) (bool, error) {
+	var exists bool
+	err := r.db.QueryRowxContext(ctx,
+		"SELECT EXISTS(SELECT 1 FROM issues WHERE key = $1)",
+		issueKey).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("failed to check issue exists: %w", err)
+	}
+	return exists, nil
+}
+
+// GetIssuesByKeys возвращает сохранённые задачи с заданными ключами.
+func (r *JiraPostgres) GetIssuesByKeys(ctx context.Context, keys []string) ([]models.DBIssue, error) {
+	if len(keys) == 0 {
+		return nil, nil
+	}
+
+	var issues []models.DBIssue
+	err := r.db.SelectContext(ctx, &issues, `
+        SELECT id, key, project_key, created, updated, closed, summary,
+               COALESCE(description, '') AS description, issue_type, priority, status,
+               COALESCE(time_spent, 0) AS time_spent, creator_id, assignee_id, custom_fields
+        FROM issues
+        WHERE key = ANY($1)
+    `, pq.Array(keys))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get issues: %w", err)
+	}
+
+	return issues, nil
 }
@@ -0,0 +1,90 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"github.com/lib/pq"
+	"jiraAnalyzer/jiraConnector/internal/models"
+)
+
+// SaveIssueDimensionsTx заменяет метки, компоненты и fix-версии задач.
+// Сами компоненты и версии обновляются по jira_id.
+func (r *JiraPostgres) SaveIssueDimensionsTx(tx *sql.Tx, dimensions []models.DBIssueDimensions) error {
+	if len(dimensions) == 0 {
+		return nil
+	}
+
+	issueKeys := make([]string, len(dimensions))
+	for i, d := range dimensions {
+		issueKeys[i] = d.IssueKey
+	}
+
+	for _, table := range []string{"issue_labels", "issue_components", "issue_fix_versions"} {
+		if _, err := tx.Exec("DELETE FROM "+table+" WHERE issue_id = ANY($1)", pq.Array(issueKeys)); err != nil {
+			return fmt.Errorf("failed to clear %s: %w", table, err)
+		}
+	}
+
+	labelStmt, err := tx.Prepare("INSERT INTO issue_labels (issue_id, label) VALUES ($1, $2) ON CONFLICT DO NOTHING")
+	if err != nil {
+		return fmt.Errorf("failed to prepare statement: %w", err)
+	}
+	defer labelStmt.Close()
+
+	componentStmt, err := tx.Prepare(`
+        WITH component AS (
+            INSERT INTO components (jira_id, project_key, name)
+            VALUES ($2, $3, $4)
+            ON CONFLICT (jira_id) DO UPDATE SET name = EXCLUDED.name
+            RETURNING id
+        )
+        INSERT INTO issue_components (issue_id, component_id)
+        SELECT $1, id FROM component
+        ON CONFLICT DO NOTHING
+    `)
+	if err != nil {
+		return fmt.Errorf("failed to prepare statement: %w", err)
+	}
+	defer componentStmt.Close()
+
+	versionStmt, err := tx.Prepare(`
+        WITH version AS (
+            INSERT INTO versions (jira_id, project_key, name, released, release_date)
+            VALUES ($2, $3, $4, $5, $6)
+            ON CONFLICT (jira_id) DO UPDATE SET
+                name = EXCLUDED.name,
+                released = EXCLUDED.released,
+                release_date = EXCLUDED.release_date
+            RETURNING id
+        )
+        INSERT INTO issue_fix_versions (issue_id, version_id)
+        SELECT $1, id FROM version
+        ON CONFLICT DO NOTHING
+    `)
+	if err != nil {
+		return fmt.Errorf("failed to prepare statement: %w", err)
+	}
+	defer versionStmt.Close()
+
+	for _, d := range dimensions {
+		for _, label := range d.Labels {
+			if _, err := labelStmt.Exec(d.IssueKey, label); err != nil {
+				return fmt.Errorf("failed to save issue label: %w", err)
+			}
+		}
+
+		for _, c := range d.Components {
+			if _, err := componentStmt.Exec(d.IssueKey, c.JiraID, c.ProjectKey, c.Name); err != nil {
+				return fmt.Errorf("failed to save issue component: %w", err)
+			}
+		}
+
+		for _, v := range d.FixVersions {
+			if _, err := versionStmt.Exec(d.IssueKey, v.JiraID, v.ProjectKey, v.Name, v.Released, v.ReleaseDate); err != nil {
+				return fmt.Errorf("failed to save issue fix version: %w", err)
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,57 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"github.com/lib/pq"
+	"jiraAnalyzer/jiraConnector/internal/models"
+)
+
+func (r *JiraPostgres) SaveIssueLinksTx(tx *sql.Tx, links []models.DBIssueLink) error {
+	stmt, err := tx.Prepare(`
+        INSERT INTO issue_links (jira_id, source_key, target_key, link_type, outward, inward)
+        VALUES ($1, $2, $3, $4, $5, $6)
+        ON CONFLICT (jira_id) DO UPDATE SET
+            source_key = EXCLUDED.source_key,
+            target_key = EXCLUDED.target_key,
+            link_type = EXCLUDED.link_type,
+            outward = EXCLUDED.outward,
+            inward = EXCLUDED.inward
+    `)
+	if err != nil {
+		return fmt.Errorf("failed to prepare statement: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, l := range links {
+		_, err := stmt.Exec(l.JiraID, l.SourceKey, l.TargetKey, l.LinkType, l.Outward, l.Inward)
+		if err != nil {
+			return fmt.Errorf("failed to execute statement: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// DeleteStaleIssueLinksTx удаляет связи задач, которых больше нет в Jira.
+// Jira всегда отдаёт связи задачи целиком, поэтому удаляются связи с любой
+// стороны, не попавшие в keepIDs.
+func (r *JiraPostgres) DeleteStaleIssueLinksTx(tx *sql.Tx, issueKeys []string, keepIDs []string) error {
+	if len(issueKeys) == 0 {
+		return nil
+	}
+	// nil-срез передаётся как NULL, и условие ниже не удалило бы ни одной строки
+	if keepIDs == nil {
+		keepIDs = []string{}
+	}
+
+	_, err := tx.Exec(`
+        DELETE FROM issue_links
+        WHERE (source_key = ANY($1) OR target_key = ANY($1)) AND NOT (jira_id = ANY($2))
+    `, pq.Array(issueKeys), pq.Array(keepIDs))
+	if err != nil {
+		return fmt.Errorf("failed to delete stale issue links: %w", err)
+	}
+
+	return nil
+}
@@ -3,6 +3,7 @@ package database
 import (
 	"context"
 	"database/sql"
+	"fmt"
 	"github.com/jmoiron/sqlx"
 )
 
@@ -17,3 +18,27 @@ func NewJiraPostgres(db *sqlx.DB) *JiraPostgres {
 func (r *JiraPostgres) BeginTx(ctx context.Context) (*sql.Tx, error) {
 	return r.db.BeginTx(ctx, nil)
 }
+
+// Savepoint'ы позволяют откатить часть транзакции, например одну задачу
+// батча, не теряя остальные записи. Имена задаёт вызывающий код.
+
+func (r *JiraPostgres) SavepointTx(tx *sql.Tx, name string) error {
+	if _, err := tx.Exec("SAVEPOINT " + name); err != nil {
+		return fmt.Errorf("failed to create savepoint: %w", err)
+	}
+	return nil
+}
+
+func (r *JiraPostgres) RollbackToSavepointTx(tx *sql.Tx, name string) error {
+	if _, err := tx.Exec("ROLLBACK TO SAVEPOINT " + name); err != nil {
+		return fmt.Errorf("failed to roll back to savepoint: %w", err)
+	}
+	return nil
+}
+
+func (r *JiraPostgres) ReleaseSavepointTx(tx *sql.Tx, name string) error {
+	if _, err := tx.Exec("RELEASE SAVEPOINT " + name); err != nil {
+		return fmt.Errorf("failed to release savepoint: %w", err)
+	}
+	return nil
+}
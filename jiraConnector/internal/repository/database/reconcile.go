@@ -0,0 +1,90 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"github.com/lib/pq"
+)
+
+// GetActiveIssueKeys возвращает ключи задач проекта, не помеченных удалёнными.
+func (r *JiraPostgres) GetActiveIssueKeys(ctx context.Context, projectKey string) ([]string, error) {
+	var keys []string
+	err := r.db.SelectContext(ctx, &keys, "SELECT key FROM issues WHERE project_key = $1 AND deleted_at IS NULL", projectKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get active issue keys: %w", err)
+	}
+
+	return keys, nil
+}
+
+// MarkIssuesDeleted помечает задачи удалёнными в Jira.
+func (r *JiraPostgres) MarkIssuesDeleted(ctx context.Context, keys []string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	_, err := r.db.ExecContext(ctx,
+		"UPDATE issues SET deleted_at = NOW() WHERE key = ANY($1) AND deleted_at IS NULL",
+		pq.Array(keys),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to mark issues deleted: %w", err)
+	}
+
+	return nil
+}
+
+// MoveIssue переносит задачу под новый ключ и в новый проект вместе со всеми
+// связанными данными и возвращает true. Если новый проект не синхронизируется
+// или задача с новым ключом уже загружена, старая задача помечается
+// удалённой с новым ключом в moved_to, и возвращается false.
+func (r *JiraPostgres) MoveIssue(ctx context.Context, oldKey, newKey, newProjectKey string) (bool, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Комментарии, списания и история переезжают через ON UPDATE CASCADE
+	res, err := tx.ExecContext(ctx, `
+        UPDATE issues SET key = $2, project_key = $3, deleted_at = NULL, moved_to = NULL
+        WHERE key = $1
+          AND EXISTS (SELECT 1 FROM projects WHERE key = $3)
+          AND NOT EXISTS (SELECT 1 FROM issues WHERE key = $2)
+    `, oldKey, newKey, newProjectKey)
+	if err != nil {
+		return false, fmt.Errorf("failed to reassign issue %s: %w", oldKey, err)
+	}
+	reassigned, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to reassign issue %s: %w", oldKey, err)
+	}
+
+	if reassigned > 0 {
+		// Связи и состав спринтов хранят ключи без внешних ключей
+		queries := []string{
+			"UPDATE issue_links SET source_key = $2 WHERE source_key = $1",
+			"UPDATE issue_links SET target_key = $2 WHERE target_key = $1",
+			`UPDATE sprint_issues s SET issue_key = $2 WHERE issue_key = $1
+                AND NOT EXISTS (SELECT 1 FROM sprint_issues x WHERE x.sprint_id = s.sprint_id AND x.issue_key = $2)`,
+		}
+		for _, query := range queries {
+			if _, err := tx.ExecContext(ctx, query, oldKey, newKey); err != nil {
+				return false, fmt.Errorf("failed to rekey issue %s: %w", oldKey, err)
+			}
+		}
+	} else {
+		_, err := tx.ExecContext(ctx,
+			"UPDATE issues SET deleted_at = COALESCE(deleted_at, NOW()), moved_to = $2 WHERE key = $1",
+			oldKey, newKey,
+		)
+		if err != nil {
+			return false, fmt.Errorf("failed to mark issue %s moved: %w", oldKey, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("failed to commit issue move: %w", err)
+	}
+	return reassigned > 0, nil
+}
@@ -0,0 +1,37 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"jiraAnalyzer/jiraConnector/internal/models"
+)
+
+func (r *JiraPostgres) SaveScheduleRun(ctx context.Context, run models.DBScheduleRun) error {
+	_, err := r.db.ExecContext(ctx, `
+        INSERT INTO scheduler_runs (schedule_name, project_key, full_sync, job_id, status, message, scheduled_at)
+        VALUES ($1, $2, $3, $4, $5, $6, $7)
+    `, run.ScheduleName, run.ProjectKey, run.Full, run.JobID, run.Status, run.Message, run.ScheduledAt)
+	if err != nil {
+		return fmt.Errorf("failed to save schedule run: %w", err)
+	}
+
+	return nil
+}
+
+func (r *JiraPostgres) ListScheduleRuns(ctx context.Context, scheduleName string, limit int) ([]models.DBScheduleRun, error) {
+	var runs []models.DBScheduleRun
+	err := r.db.SelectContext(ctx, &runs, `
+        SELECT r.id, r.schedule_name, r.project_key, r.full_sync, r.job_id, j.status AS job_status,
+               r.status, r.message, r.scheduled_at, r.created_at
+        FROM scheduler_runs r
+        LEFT JOIN sync_jobs j ON j.id = r.job_id
+        WHERE ($1 = '' OR r.schedule_name = $1)
+        ORDER BY r.id DESC
+        LIMIT $2
+    `, scheduleName, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list schedule runs: %w", err)
+	}
+
+	return runs, nil
+}
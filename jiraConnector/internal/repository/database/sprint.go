@@ -0,0 +1,69 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"jiraAnalyzer/jiraConnector/internal/models"
+)
+
+// GetClosedSprintIDs возвращает id спринтов проекта, закрытых на момент
+// прошлой синхронизации. Состав закрытого спринта больше не меняется.
+func (r *JiraPostgres) GetClosedSprintIDs(ctx context.Context, projectKey string) (map[int]bool, error) {
+	var ids []int
+	err := r.db.SelectContext(ctx, &ids,
+		"SELECT id FROM sprints WHERE project_key = $1 AND state = $2",
+		projectKey, models.SprintStateClosed)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get closed sprints: %w", err)
+	}
+
+	closed := make(map[int]bool, len(ids))
+	for _, id := range ids {
+		closed[id] = true
+	}
+	return closed, nil
+}
+
+func (r *JiraPostgres) SaveSprintTx(tx *sql.Tx, sprint models.DBSprint) error {
+	_, err := tx.Exec(`
+        INSERT INTO sprints (id, board_id, project_key, name, state, goal, start_date, end_date, complete_date)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+        ON CONFLICT (id) DO UPDATE SET
+            board_id = EXCLUDED.board_id,
+            project_key = EXCLUDED.project_key,
+            name = EXCLUDED.name,
+            state = EXCLUDED.state,
+            goal = EXCLUDED.goal,
+            start_date = EXCLUDED.start_date,
+            end_date = EXCLUDED.end_date,
+            complete_date = EXCLUDED.complete_date
+    `, sprint.ID, sprint.BoardID, sprint.ProjectKey, sprint.Name, sprint.State, sprint.Goal,
+		sprint.StartDate, sprint.EndDate, sprint.CompleteDate)
+	if err != nil {
+		return fmt.Errorf("failed to save sprint: %w", err)
+	}
+
+	return nil
+}
+
+// ReplaceSprintIssuesTx заменяет состав спринта на переданный список задач.
+func (r *JiraPostgres) ReplaceSprintIssuesTx(tx *sql.Tx, sprintID int, issueKeys []string) error {
+	if _, err := tx.Exec("DELETE FROM sprint_issues WHERE sprint_id = $1", sprintID); err != nil {
+		return fmt.Errorf("failed to clear sprint issues: %w", err)
+	}
+
+	stmt, err := tx.Prepare("INSERT INTO sprint_issues (sprint_id, issue_key) VALUES ($1, $2) ON CONFLICT DO NOTHING")
+	if err != nil {
+		return fmt.Errorf("failed to prepare statement: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, key := range issueKeys {
+		if _, err := stmt.Exec(sprintID, key); err != nil {
+			return fmt.Errorf("failed to execute statement: %w", err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,144 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"github.com/lib/pq"
+	"jiraAnalyzer/jiraConnector/internal/models"
+)
+
+const syncJobColumns = `
+    id, project_keys, full_sync, status, batches_total, batches_done,
+    issues_written, issues_deleted, issues_moved, errors, created_at, started_at, finished_at,
+    dry_run, report
+`
+
+func (r *JiraPostgres) CreateSyncJob(ctx context.Context, projectKeys []string, full, dryRun bool) (int, error) {
+	var jobID int
+	err := r.db.QueryRowxContext(ctx,
+		"INSERT INTO sync_jobs (project_keys, full_sync, dry_run, status) VALUES ($1, $2, $3, $4) RETURNING id",
+		pq.Array(projectKeys), full, dryRun, models.JobStatusQueued,
+	).Scan(&jobID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create sync job: %w", err)
+	}
+
+	return jobID, nil
+}
+
+func (r *JiraPostgres) GetSyncJob(ctx context.Context, jobID int) (models.DBSyncJob, error) {
+	var job models.DBSyncJob
+	err := r.db.GetContext(ctx, &job, "SELECT"+syncJobColumns+"FROM sync_jobs WHERE id = $1", jobID)
+	if err != nil {
+		return models.DBSyncJob{}, fmt.Errorf("failed to get sync job: %w", err)
+	}
+
+	return job, nil
+}
+
+func (r *JiraPostgres) ListSyncJobs(ctx context.Context, limit int) ([]models.DBSyncJob, error) {
+	var jobs []models.DBSyncJob
+	err := r.db.SelectContext(ctx, &jobs, "SELECT"+syncJobColumns+"FROM sync_jobs ORDER BY id DESC LIMIT $1", limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sync jobs: %w", err)
+	}
+
+	return jobs, nil
+}
+
+func (r *JiraPostgres) StartSyncJob(ctx context.Context, jobID int) error {
+	_, err := r.db.ExecContext(ctx,
+		"UPDATE sync_jobs SET status = $2, started_at = NOW() WHERE id = $1",
+		jobID, models.JobStatusRunning,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to start sync job: %w", err)
+	}
+
+	return nil
+}
+
+func (r *JiraPostgres) AddSyncJobBatches(ctx context.Context, jobID int, batches int) error {
+	_, err := r.db.ExecContext(ctx,
+		"UPDATE sync_jobs SET batches_total = batches_total + $2 WHERE id = $1",
+		jobID, batches,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update sync job batches: %w", err)
+	}
+
+	return nil
+}
+
+func (r *JiraPostgres) AddSyncJobProgress(ctx context.Context, jobID int, issues int) error {
+	_, err := r.db.ExecContext(ctx,
+		"UPDATE sync_jobs SET batches_done = batches_done + 1, issues_written = issues_written + $2 WHERE id = $1",
+		jobID, issues,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update sync job progress: %w", err)
+	}
+
+	return nil
+}
+
+func (r *JiraPostgres) AddSyncJobError(ctx context.Context, jobID int, message string) error {
+	_, err := r.db.ExecContext(ctx,
+		"UPDATE sync_jobs SET errors = array_append(errors, $2) WHERE id = $1",
+		jobID, message,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save sync job error: %w", err)
+	}
+
+	return nil
+}
+
+func (r *JiraPostgres) AddSyncJobReconciliation(ctx context.Context, jobID int, deleted, moved int) error {
+	_, err := r.db.ExecContext(ctx,
+		"UPDATE sync_jobs SET issues_deleted = issues_deleted + $2, issues_moved = issues_moved + $3 WHERE id = $1",
+		jobID, deleted, moved,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update sync job reconciliation: %w", err)
+	}
+
+	return nil
+}
+
+func (r *JiraPostgres) FinishSyncJob(ctx context.Context, jobID int, status string) error {
+	_, err := r.db.ExecContext(ctx,
+		"UPDATE sync_jobs SET status = $2, finished_at = NOW() WHERE id = $1",
+		jobID, status,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to finish sync job: %w", err)
+	}
+
+	return nil
+}
+
+// SaveSyncJobReport сохраняет отчёт пробной синхронизации.
+func (r *JiraPostgres) SaveSyncJobReport(ctx context.Context, jobID int, report []byte) error {
+	_, err := r.db.ExecContext(ctx, "UPDATE sync_jobs SET report = $2 WHERE id = $1", jobID, string(report))
+	if err != nil {
+		return fmt.Errorf("failed to save sync job report: %w", err)
+	}
+
+	return nil
+}
+
+// InterruptActiveSyncJobs помечает прерванными задачи, которые остались
+// незавершёнными после остановки коннектора.
+func (r *JiraPostgres) InterruptActiveSyncJobs(ctx context.Context, reason string) (int64, error) {
+	res, err := r.db.ExecContext(ctx, `
+        UPDATE sync_jobs
+        SET status = $1, finished_at = NOW(), errors = array_append(errors, $2)
+        WHERE status IN ($3, $4)
+    `, models.JobStatusInterrupted, reason, models.JobStatusQueued, models.JobStatusRunning)
+	if err != nil {
+		return 0, fmt.Errorf("failed to interrupt active sync jobs: %w", err)
+	}
+
+	return res.RowsAffected()
+}
@@ -0,0 +1,141 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"jiraAnalyzer/jiraConnector/internal/models"
+)
+
+const syncRunColumns = `
+    id, project_key, full_sync, updated_since, started_at, status,
+    pass, finished_at
+`
+
+// GetActiveSyncRun возвращает незавершённую синхронизацию проекта или nil.
+func (r *JiraPostgres) GetActiveSyncRun(ctx context.Context, projectKey string) (*models.DBSyncRun, error) {
+	var run models.DBSyncRun
+	err := r.db.GetContext(ctx, &run,
+		"SELECT"+syncRunColumns+"FROM sync_runs WHERE project_key = $1 AND finished_at IS NULL",
+		projectKey,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to get active sync run: %w", err)
+	}
+
+	return &run, nil
+}
+
+// CreateSyncRun создаёт синхронизацию проекта. Незавершённая синхронизация
+// проекта, если она есть, отменяется вместе со своими батчами.
+func (r *JiraPostgres) CreateSyncRun(ctx context.Context, run models.DBSyncRun) (int, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `
+        DELETE FROM sync_checkpoints c USING sync_runs s
+        WHERE c.run_id = s.id AND s.project_key = $1 AND s.finished_at IS NULL
+    `, run.ProjectKey)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete abandoned checkpoints: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx,
+		"UPDATE sync_runs SET status = $2, finished_at = NOW() WHERE project_key = $1 AND finished_at IS NULL",
+		run.ProjectKey, models.SyncRunAbandoned,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to abandon sync run: %w", err)
+	}
+
+	var runID int
+	err = tx.QueryRowxContext(ctx, `
+        INSERT INTO sync_runs (project_key, full_sync, updated_since, started_at, status, pass)
+        VALUES ($1, $2, $3, $4, $5, $6)
+        RETURNING id
+    `, run.ProjectKey, run.Full, run.UpdatedSince, run.StartedAt, models.SyncRunRunning, run.Pass,
+	).Scan(&runID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create sync run: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit sync run: %w", err)
+	}
+	return runID, nil
+}
+
+// StartSyncRunPass начинает новый проход синхронизации.
+func (r *JiraPostgres) StartSyncRunPass(ctx context.Context, runID, pass int) error {
+	_, err := r.db.ExecContext(ctx,
+		"UPDATE sync_runs SET pass = $2 WHERE id = $1",
+		runID, pass,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to start sync run pass: %w", err)
+	}
+
+	return nil
+}
+
+func (r *JiraPostgres) GetSyncCheckpoints(ctx context.Context, runID int) ([]models.DBSyncCheckpoint, error) {
+	var checkpoints []models.DBSyncCheckpoint
+	err := r.db.SelectContext(ctx, &checkpoints, `
+        SELECT run_id, pass, start_at, issue_keys FROM sync_checkpoints
+        WHERE run_id = $1
+        ORDER BY pass, start_at
+    `, runID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sync checkpoints: %w", err)
+	}
+
+	return checkpoints, nil
+}
+
+// SaveSyncCheckpointTx отмечает батч сохранённым в транзакции батча.
+func (r *JiraPostgres) SaveSyncCheckpointTx(tx *sql.Tx, checkpoint models.DBSyncCheckpoint) error {
+	_, err := tx.Exec(`
+        INSERT INTO sync_checkpoints (run_id, pass, start_at, issue_keys)
+        VALUES ($1, $2, $3, $4)
+        ON CONFLICT (run_id, pass, start_at) DO UPDATE SET
+            issue_keys = EXCLUDED.issue_keys,
+            created_at = CURRENT_TIMESTAMP
+    `, checkpoint.RunID, checkpoint.Pass, checkpoint.StartAt, checkpoint.IssueKeys)
+	if err != nil {
+		return fmt.Errorf("failed to save sync checkpoint: %w", err)
+	}
+
+	return nil
+}
+
+// FinishSyncRun завершает синхронизацию, её батчи больше не нужны.
+func (r *JiraPostgres) FinishSyncRun(ctx context.Context, runID int) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM sync_checkpoints WHERE run_id = $1", runID); err != nil {
+		return fmt.Errorf("failed to delete sync checkpoints: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx,
+		"UPDATE sync_runs SET status = $2, finished_at = NOW() WHERE id = $1",
+		runID, models.SyncRunSucceeded,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to finish sync run: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit sync run: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// GetSyncWatermark возвращает время последней успешной синхронизации проекта
+// или nil, если проект ещё ни разу не синхронизировался.
+func (r *JiraPostgres) GetSyncWatermark(ctx context.Context, projectKey string) (*time.Time, error) {
+	var lastSyncedAt time.Time
+	err := r.db.QueryRowxContext(ctx,
+		"SELECT last_synced_at FROM sync_state WHERE project_key = $1",
+		projectKey).Scan(&lastSyncedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to get sync watermark: %w", err)
+	}
+
+	return &lastSyncedAt, nil
+}
+
+func (r *JiraPostgres) SaveSyncWatermark(ctx context.Context, projectKey string, syncedAt time.Time) error {
+	_, err := r.db.ExecContext(ctx, `
+        INSERT INTO sync_state (project_key, last_synced_at, updated_at)
+        VALUES ($1, $2, NOW())
+        ON CONFLICT (project_key) DO UPDATE SET
+            last_synced_at = EXCLUDED.last_synced_at,
+            updated_at = EXCLUDED.updated_at
+    `, projectKey, syncedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save sync watermark: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,81 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"jiraAnalyzer/jiraConnector/internal/models"
+	"time"
+)
+
+// ClaimWebhookDelivery принимает доставку webhook к обработке. Доставку,
+// которая не обработана за claimTimeout, можно принять повторно: её
+// обработчик упал, не сняв регистрацию. Возвращает WebhookDeliveryPending,
+// если доставку ещё обрабатывает другой запрос, и WebhookDeliveryProcessed,
+// если она уже обработана.
+func (r *JiraPostgres) ClaimWebhookDelivery(ctx context.Context, deliveryID, event, issueKey string, claimTimeout time.Duration) (string, error) {
+	var id string
+	err := r.db.QueryRowxContext(ctx, `
+        INSERT INTO webhook_deliveries (id, event, issue_key)
+        VALUES ($1, $2, NULLIF($3, ''))
+        ON CONFLICT (id) DO UPDATE SET claimed_at = NOW()
+        WHERE webhook_deliveries.processed_at IS NULL
+            AND webhook_deliveries.claimed_at < NOW() - $4 * INTERVAL '1 second'
+        RETURNING id
+    `, deliveryID, event, issueKey, claimTimeout.Seconds()).Scan(&id)
+	if err == nil {
+		return models.WebhookDeliveryClaimed, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return "", fmt.Errorf("failed to claim webhook delivery: %w", err)
+	}
+
+	var processed bool
+	err = r.db.QueryRowxContext(ctx,
+		"SELECT processed_at IS NOT NULL FROM webhook_deliveries WHERE id = $1", deliveryID,
+	).Scan(&processed)
+	// Регистрацию сняли после неудачной обработки, Jira повторит доставку
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.WebhookDeliveryPending, nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get webhook delivery: %w", err)
+	}
+	if processed {
+		return models.WebhookDeliveryProcessed, nil
+	}
+	return models.WebhookDeliveryPending, nil
+}
+
+// CompleteWebhookDelivery отмечает доставку обработанной.
+func (r *JiraPostgres) CompleteWebhookDelivery(ctx context.Context, deliveryID string) error {
+	_, err := r.db.ExecContext(ctx, "UPDATE webhook_deliveries SET processed_at = NOW() WHERE id = $1", deliveryID)
+	if err != nil {
+		return fmt.Errorf("failed to complete webhook delivery: %w", err)
+	}
+	return nil
+}
+
+// ReleaseWebhookDelivery снимает регистрацию доставки, которую не удалось
+// обработать, чтобы повтор от Jira был обработан заново.
+func (r *JiraPostgres) ReleaseWebhookDelivery(ctx context.Context, deliveryID string) error {
+	_, err := r.db.ExecContext(ctx, "DELETE FROM webhook_deliveries WHERE id = $1 AND processed_at IS NULL", deliveryID)
+	if err != nil {
+		return fmt.Errorf("failed to release webhook delivery: %w", err)
+	}
+	return nil
+}
+
+// PruneWebhookDeliveries удаляет доставки старше olderThan. Время считается
+// в БД, как и received_at.
+func (r *JiraPostgres) PruneWebhookDeliveries(ctx context.Context, olderThan time.Duration) (int64, error) {
+	res, err := r.db.ExecContext(ctx,
+		"DELETE FROM webhook_deliveries WHERE received_at < NOW() - $1 * INTERVAL '1 second'",
+		olderThan.Seconds(),
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to prune webhook deliveries: %w", err)
+	}
+	return res.RowsAffected()
+}
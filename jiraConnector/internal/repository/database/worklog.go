@@ -0,0 +1,50 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"github.com/lib/pq"
+	"jiraAnalyzer/jiraConnector/internal/models"
+)
+
+func (r *JiraPostgres) SaveWorklogsTx(tx *sql.Tx, worklogs []models.DBWorklog) error {
+	rows := make([][]interface{}, len(worklogs))
+	for i, w := range worklogs {
+		rows[i] = []interface{}{w.JiraID, w.IssueID, w.AuthorID, w.Started, w.TimeSpentSeconds, w.Created, w.Updated, w.Comment}
+	}
+
+	return bulkUpsert(tx, "worklogs",
+		[]string{"jira_id", "issue_id", "author_id", "started", "time_spent_seconds", "created", "updated", "comment"},
+		[]string{"jira_id"},
+		`DO UPDATE SET
+            issue_id = EXCLUDED.issue_id,
+            author_id = EXCLUDED.author_id,
+            started = EXCLUDED.started,
+            time_spent_seconds = EXCLUDED.time_spent_seconds,
+            updated = EXCLUDED.updated,
+            comment = EXCLUDED.comment`,
+		rows,
+	)
+}
+
+// DeleteStaleWorklogsTx удаляет списания задач, которых больше нет в Jira.
+// keepIDs - идентификаторы списаний, полученные при синхронизации.
+func (r *JiraPostgres) DeleteStaleWorklogsTx(tx *sql.Tx, issueKeys []string, keepIDs []string) error {
+	if len(issueKeys) == 0 {
+		return nil
+	}
+	// nil-срез передаётся как NULL, и условие ниже не удалило бы ни одной строки
+	if keepIDs == nil {
+		keepIDs = []string{}
+	}
+
+	_, err := tx.Exec(`
+        DELETE FROM worklogs
+        WHERE issue_id = ANY($1) AND NOT (jira_id = ANY($2))
+    `, pq.Array(issueKeys), pq.Array(keepIDs))
+	if err != nil {
+		return fmt.Errorf("failed to delete stale worklogs: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,97 @@
+package jira
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"jiraAnalyzer/jiraConnector/internal/models"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// Agile REST API (Jira Software): доски, спринты и задачи спринтов.
+
+// BoardTypeScrum - тип доски, у которой есть спринты. Kanban-доски
+// на запрос спринтов отвечают ошибкой.
+const BoardTypeScrum = "scrum"
+
+// GetProjectBoards возвращает доски проекта. Без Jira Software Agile API
+// отвечает 404: у такого проекта нет досок и спринтов.
+func (c *Jira) GetProjectBoards(ctx context.Context, projectKey string) ([]models.JiraBoard, error) {
+	var boards []models.JiraBoard
+
+	for startAt := 0; ; {
+		params := c.agilePageParams(startAt)
+		params.Set("projectKeyOrId", projectKey)
+		boardURL := fmt.Sprintf("%s/rest/agile/1.0/board?%s", c.cfg.JiraUrl, params.Encode())
+
+		var page models.JiraBoardPage
+		if err := c.doRequestWithRetry(boardURL, &page, ctx); err != nil {
+			var reqErr *RequestError
+			if errors.As(err, &reqErr) && reqErr.StatusCode == http.StatusNotFound {
+				log.Printf("Jira Agile API is not available, skipping boards of project %s: %v", projectKey, err)
+				return nil, nil
+			}
+			return nil, fmt.Errorf("failed to fetch boards for project %s: %w", projectKey, err)
+		}
+
+		boards = append(boards, page.Values...)
+		if page.IsLast || len(page.Values) == 0 {
+			return boards, nil
+		}
+		startAt += len(page.Values)
+	}
+}
+
+func (c *Jira) GetBoardSprints(ctx context.Context, boardID int) ([]models.JiraSprint, error) {
+	var sprints []models.JiraSprint
+
+	for startAt := 0; ; {
+		params := c.agilePageParams(startAt)
+		sprintURL := fmt.Sprintf("%s/rest/agile/1.0/board/%d/sprint?%s", c.cfg.JiraUrl, boardID, params.Encode())
+
+		var page models.JiraSprintPage
+		if err := c.doRequestWithRetry(sprintURL, &page, ctx); err != nil {
+			return nil, fmt.Errorf("failed to fetch sprints for board %d: %w", boardID, err)
+		}
+
+		sprints = append(sprints, page.Values...)
+		if page.IsLast || len(page.Values) == 0 {
+			return sprints, nil
+		}
+		startAt += len(page.Values)
+	}
+}
+
+// GetSprintIssueKeys возвращает ключи задач, которые сейчас входят в спринт.
+func (c *Jira) GetSprintIssueKeys(ctx context.Context, sprintID int) ([]string, error) {
+	var keys []string
+
+	for {
+		params := c.agilePageParams(len(keys))
+		// Поля задач не нужны, достаточно ключей
+		params.Set("fields", "status")
+		issueURL := fmt.Sprintf("%s/rest/agile/1.0/sprint/%d/issue?%s", c.cfg.JiraUrl, sprintID, params.Encode())
+
+		var page models.JiraSprintIssuesPage
+		if err := c.doRequestWithRetry(issueURL, &page, ctx); err != nil {
+			return nil, fmt.Errorf("failed to fetch issues of sprint %d: %w", sprintID, err)
+		}
+
+		for _, issue := range page.Issues {
+			keys = append(keys, issue.Key)
+		}
+		if len(page.Issues) == 0 || len(keys) >= page.Total {
+			return keys, nil
+		}
+	}
+}
+
+func (c *Jira) agilePageParams(startAt int) url.Values {
+	params := url.Values{}
+	params.Set("startAt", strconv.Itoa(startAt))
+	params.Set("maxResults", strconv.Itoa(c.cfg.IssueInOneRequest))
+	return params
+}
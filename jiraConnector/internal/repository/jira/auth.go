@@ -0,0 +1,278 @@
+package jira
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Способы аутентификации в Jira
+const (
+	AuthNone   = "none"
+	AuthBasic  = "basic"
+	AuthBearer = "bearer"
+	AuthOAuth2 = "oauth2"
+)
+
+const (
+	defaultOAuth2TokenURL = "https://auth.atlassian.com/oauth/token"
+	// tokenExpiryMargin - запас, с которым access token обновляется заранее
+	tokenExpiryMargin = time.Minute
+)
+
+// AuthConfig задаёт аутентификацию запросов к Jira:
+//   - basic: email и API token (Jira Cloud);
+//   - bearer: personal access token (Jira Data Center);
+//   - oauth2: OAuth 2.0 (3LO), access token получается по refresh token.
+//
+// Секреты не хранятся в YAML, а читаются из переменных окружения или файлов.
+type AuthConfig struct {
+	Type     string       `yaml:"type"`
+	Email    string       `yaml:"email"`
+	APIToken Secret       `yaml:"apiToken"`
+	Token    Secret       `yaml:"token"`
+	OAuth2   OAuth2Config `yaml:"oauth2"`
+}
+
+type OAuth2Config struct {
+	ClientID     string `yaml:"clientId"`
+	ClientSecret Secret `yaml:"clientSecret"`
+	RefreshToken Secret `yaml:"refreshToken"`
+	TokenURL     string `yaml:"tokenUrl"`
+}
+
+// Secret - ссылка на секрет: имя переменной окружения или путь к файлу.
+type Secret struct {
+	Env  string `yaml:"env"`
+	File string `yaml:"file"`
+}
+
+func (s Secret) IsSet() bool {
+	return s.Env != "" || s.File != ""
+}
+
+// Resolve читает значение секрета. Если заданы оба источника, приоритет
+// у переменной окружения.
+func (s Secret) Resolve() (string, error) {
+	if s.Env != "" {
+		if value, ok := os.LookupEnv(s.Env); ok && value != "" {
+			return strings.TrimSpace(value), nil
+		}
+		if s.File == "" {
+			return "", fmt.Errorf("environment variable %s is not set", s.Env)
+		}
+	}
+
+	if s.File != "" {
+		data, err := os.ReadFile(s.File)
+		if err != nil {
+			return "", fmt.Errorf("failed to read secret file: %w", err)
+		}
+		value := strings.TrimSpace(string(data))
+		if value == "" {
+			return "", fmt.Errorf("secret file %s is empty", s.File)
+		}
+		return value, nil
+	}
+
+	return "", errors.New("secret source is not configured")
+}
+
+// authenticator добавляет учётные данные к запросу.
+type authenticator interface {
+	authorize(ctx context.Context, req *http.Request) error
+	// invalidate сообщает, что Jira отклонила учётные данные (401).
+	// Возвращает true, если запрос имеет смысл повторить.
+	invalidate() bool
+}
+
+func newAuthenticator(cfg AuthConfig, client *http.Client) (authenticator, error) {
+	switch cfg.Type {
+	case "", AuthNone:
+		return noAuth{}, nil
+	case AuthBasic:
+		if cfg.Email == "" {
+			return nil, errors.New("basic auth: email is required")
+		}
+		token, err := cfg.APIToken.Resolve()
+		if err != nil {
+			return nil, fmt.Errorf("basic auth: api token: %w", err)
+		}
+		return basicAuth{email: cfg.Email, token: token}, nil
+	case AuthBearer:
+		token, err := cfg.Token.Resolve()
+		if err != nil {
+			return nil, fmt.Errorf("bearer auth: token: %w", err)
+		}
+		return bearerAuth{token: token}, nil
+	case AuthOAuth2:
+		return newOAuth2Auth(cfg.OAuth2, client)
+	default:
+		return nil, fmt.Errorf("unknown auth type %q", cfg.Type)
+	}
+}
+
+type noAuth struct{}
+
+func (noAuth) authorize(context.Context, *http.Request) error { return nil }
+func (noAuth) invalidate() bool                               { return false }
+
+type basicAuth struct {
+	email, token string
+}
+
+func (a basicAuth) authorize(_ context.Context, req *http.Request) error {
+	req.SetBasicAuth(a.email, a.token)
+	return nil
+}
+
+func (basicAuth) invalidate() bool { return false }
+
+type bearerAuth struct {
+	token string
+}
+
+func (a bearerAuth) authorize(_ context.Context, req *http.Request) error {
+	req.Header.Set("Authorization", "Bearer "+a.token)
+	return nil
+}
+
+func (bearerAuth) invalidate() bool { return false }
+
+// oauth2Auth получает access token по refresh token и обновляет его
+// по истечении срока или после ответа 401. Atlassian выдаёт новый refresh
+// token при каждом обновлении, поэтому, если он читался из файла, новый
+// токен записывается обратно в файл.
+type oauth2Auth struct {
+	cfg    OAuth2Config
+	client *http.Client
+
+	// persist - refresh token прочитан из файла и записывается туда после обновления
+	persist bool
+
+	mu           sync.Mutex
+	clientSecret string
+	refreshToken string
+	accessToken  string
+	expiresAt    time.Time
+}
+
+func newOAuth2Auth(cfg OAuth2Config, client *http.Client) (*oauth2Auth, error) {
+	if cfg.ClientID == "" {
+		return nil, errors.New("oauth2: clientId is required")
+	}
+	if cfg.TokenURL == "" {
+		cfg.TokenURL = defaultOAuth2TokenURL
+	}
+
+	clientSecret, err := cfg.ClientSecret.Resolve()
+	if err != nil {
+		return nil, fmt.Errorf("oauth2: client secret: %w", err)
+	}
+	refreshToken, err := cfg.RefreshToken.Resolve()
+	if err != nil {
+		return nil, fmt.Errorf("oauth2: refresh token: %w", err)
+	}
+
+	return &oauth2Auth{
+		cfg:          cfg,
+		client:       client,
+		persist:      cfg.RefreshToken.File != "" && os.Getenv(cfg.RefreshToken.Env) == "",
+		clientSecret: clientSecret,
+		refreshToken: refreshToken,
+	}, nil
+}
+
+func (a *oauth2Auth) authorize(ctx context.Context, req *http.Request) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.accessToken == "" || time.Now().Add(tokenExpiryMargin).After(a.expiresAt) {
+		if err := a.refresh(ctx); err != nil {
+			return err
+		}
+	}
+
+	req.Header.Set("Authorization", "Bearer "+a.accessToken)
+	return nil
+}
+
+func (a *oauth2Auth) invalidate() bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.accessToken = ""
+	return true
+}
+
+// refresh обменивает refresh token на новый access token. Вызывается под a.mu.
+func (a *oauth2Auth) refresh(ctx context.Context) error {
+	body, err := json.Marshal(map[string]string{
+		"grant_type":    "refresh_token",
+		"client_id":     a.cfg.ClientID,
+		"client_secret": a.clientSecret,
+		"refresh_token": a.refreshToken,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal token request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.cfg.TokenURL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create token request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to refresh oauth2 token: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("failed to refresh oauth2 token: status: %d, body: %s", resp.StatusCode, respBody)
+	}
+
+	var token struct {
+		AccessToken  string `json:"access_token"`
+		RefreshToken string `json:"refresh_token"`
+		ExpiresIn    int    `json:"expires_in"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return fmt.Errorf("failed to decode oauth2 token: %w", err)
+	}
+	if token.AccessToken == "" {
+		return errors.New("oauth2 token response has no access token")
+	}
+
+	a.accessToken = token.AccessToken
+	a.expiresAt = time.Now().Add(time.Duration(token.ExpiresIn) * time.Second)
+
+	if token.RefreshToken != "" && token.RefreshToken != a.refreshToken {
+		a.refreshToken = token.RefreshToken
+		a.persistRefreshToken()
+	}
+
+	return nil
+}
+
+func (a *oauth2Auth) persistRefreshToken() {
+	if !a.persist {
+		log.Printf("OAuth2 refresh token was rotated; it is kept in memory only and will be lost on restart")
+		return
+	}
+
+	if err := os.WriteFile(a.cfg.RefreshToken.File, []byte(a.refreshToken+"\n"), 0o600); err != nil {
+		log.Printf("Failed to persist rotated OAuth2 refresh token: %v", err)
+	}
+}
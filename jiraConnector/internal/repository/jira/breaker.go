@@ -0,0 +1,117 @@
+package jira
+
+import (
+	"log"
+	"sync"
+	"time"
+)
+
+const (
+	defaultFailureThreshold = 5
+	defaultOpenTimeout      = 30 * time.Second
+)
+
+type CircuitBreakerConfig struct {
+	// FailureThreshold - сколько временных ошибок подряд размыкают цепь
+	FailureThreshold int `yaml:"failureThreshold"`
+	// OpenTimeout - через сколько после размыкания пропускается пробный запрос
+	OpenTimeout time.Duration `yaml:"openTimeout"`
+}
+
+const (
+	circuitClosed   = "closed"
+	circuitOpen     = "open"
+	circuitHalfOpen = "half-open"
+)
+
+// circuitBreaker перестаёт пропускать запросы к Jira после серии временных
+// ошибок. По истечении OpenTimeout пропускается один пробный запрос: при
+// успехе цепь замыкается, при ошибке снова размыкается.
+type circuitBreaker struct {
+	mu        sync.Mutex
+	threshold int
+	timeout   time.Duration
+	state     string
+	failures  int
+	openedAt  time.Time
+	// probing - пробный запрос в полуоткрытом состоянии уже выполняется
+	probing bool
+}
+
+func newCircuitBreaker(cfg CircuitBreakerConfig) *circuitBreaker {
+	threshold := cfg.FailureThreshold
+	if threshold <= 0 {
+		threshold = defaultFailureThreshold
+	}
+	timeout := cfg.OpenTimeout
+	if timeout <= 0 {
+		timeout = defaultOpenTimeout
+	}
+
+	return &circuitBreaker{threshold: threshold, timeout: timeout, state: circuitClosed}
+}
+
+// allow сообщает, можно ли выполнить запрос.
+func (b *circuitBreaker) allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case circuitOpen:
+		if time.Since(b.openedAt) < b.timeout {
+			return ErrCircuitOpen
+		}
+		b.state = circuitHalfOpen
+		b.probing = true
+		log.Printf("Jira circuit breaker is half-open, sending probe request")
+		return nil
+	case circuitHalfOpen:
+		if b.probing {
+			return ErrCircuitOpen
+		}
+		b.probing = true
+		return nil
+	default:
+		return nil
+	}
+}
+
+// success фиксирует ответ Jira, не являющийся временной ошибкой.
+func (b *circuitBreaker) success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state != circuitClosed {
+		log.Printf("Jira circuit breaker is closed")
+	}
+	b.state = circuitClosed
+	b.failures = 0
+	b.probing = false
+}
+
+// failure фиксирует временную ошибку: 5xx или отсутствие ответа.
+func (b *circuitBreaker) failure() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures++
+	if b.state == circuitHalfOpen || b.failures >= b.threshold {
+		if b.state != circuitOpen {
+			log.Printf("Jira circuit breaker is open after %d failures", b.failures)
+		}
+		b.state = circuitOpen
+		b.openedAt = time.Now()
+		b.probing = false
+	}
+}
+
+// release снимает отметку пробного запроса, если он завершился без ответа
+// по причине, не связанной с Jira (например, отмена контекста).
+func (b *circuitBreaker) release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == circuitHalfOpen {
+		b.probing = false
+	}
+}
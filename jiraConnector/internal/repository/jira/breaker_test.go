@@ -0,0 +1,59 @@
+package jira
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	// События: f - временная ошибка, s - ответ Jira, r - запрос без ответа,
+	// e - истёк OpenTimeout, a - запрос пропущен, x - запрос отклонён
+	tests := []struct {
+		name   string
+		events string
+		state  string
+	}{
+		{"opens after threshold", "fafafx", circuitOpen},
+		{"success resets failures", "ffsffa", circuitClosed},
+		{"probe after timeout", "fffxea", circuitHalfOpen},
+		{"single probe at a time", "fffeax", circuitHalfOpen},
+		{"successful probe closes", "fffeasaa", circuitClosed},
+		{"failed probe reopens", "fffeafx", circuitOpen},
+		{"released probe lets another through", "fffearax", circuitHalfOpen},
+	}
+
+	for _, tt := range tests {
+		b := newCircuitBreaker(CircuitBreakerConfig{FailureThreshold: 3, OpenTimeout: time.Hour})
+		for i, event := range tt.events {
+			switch event {
+			case 'f':
+				b.failure()
+			case 's':
+				b.success()
+			case 'r':
+				b.release()
+			case 'e':
+				b.openedAt = b.openedAt.Add(-time.Hour)
+			case 'a':
+				if err := b.allow(); err != nil {
+					t.Fatalf("%s: event %d: allow() = %v, want nil", tt.name, i, err)
+				}
+			case 'x':
+				if err := b.allow(); !errors.Is(err, ErrCircuitOpen) {
+					t.Fatalf("%s: event %d: allow() = %v, want ErrCircuitOpen", tt.name, i, err)
+				}
+			}
+		}
+		if b.state != tt.state {
+			t.Errorf("%s: state = %s, want %s", tt.name, b.state, tt.state)
+		}
+	}
+}
+
+func TestCircuitBreakerDefaults(t *testing.T) {
+	b := newCircuitBreaker(CircuitBreakerConfig{})
+	if b.threshold != defaultFailureThreshold || b.timeout != defaultOpenTimeout {
+		t.Errorf("threshold %d, timeout %s, want %d, %s", b.threshold, b.timeout, defaultFailureThreshold, defaultOpenTimeout)
+	}
+}
@@ -0,0 +1,284 @@
+package jira
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Режимы кассеты запросов к Jira
+const (
+	CassetteRecord = "record"
+	CassetteReplay = "replay"
+)
+
+// ErrNotRecorded - в кассете нет ответа на запрос. Повтор запроса
+// не поможет, поэтому ошибка постоянная.
+var ErrNotRecorded = errors.New("request is not recorded in cassette")
+
+// scrubbedHeaders - заголовки с учётными данными, которые не попадают
+// в кассету.
+var scrubbedHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie"}
+
+// CassetteConfig включает запись запросов к Jira в каталог или
+// воспроизведение записанных ответов вместо обращения к сети.
+type CassetteConfig struct {
+	// Mode - record, replay или пусто (обычная работа)
+	Mode string `yaml:"mode"`
+	Dir  string `yaml:"dir"`
+}
+
+// interaction - запрос к Jira и ответ на него. Тело ответа хранится
+// рядом в файле BodyFile без изменений. Если ответ не получен, вместо
+// него записывается ошибка.
+type interaction struct {
+	Seq        int               `json:"seq"`
+	RecordedAt time.Time         `json:"recordedAt"`
+	Request    recordedRequest   `json:"request"`
+	Response   *recordedResponse `json:"response,omitempty"`
+	Error      string            `json:"error,omitempty"`
+}
+
+type recordedRequest struct {
+	Method string `json:"method"`
+	// URL - путь и параметры запроса без адреса Jira, чтобы кассету
+	// можно было воспроизвести с любым jiraUrl
+	URL    string      `json:"url"`
+	Header http.Header `json:"header"`
+}
+
+type recordedResponse struct {
+	StatusCode int         `json:"statusCode"`
+	Header     http.Header `json:"header"`
+	BodyFile   string      `json:"bodyFile"`
+}
+
+func (i interaction) key() string {
+	return i.Request.Method + " " + i.Request.URL
+}
+
+// newCassetteTransport возвращает транспорт для режима кассеты или nil,
+// если режим не задан.
+func newCassetteTransport(cfg CassetteConfig, baseURL string, next http.RoundTripper) (http.RoundTripper, error) {
+	switch cfg.Mode {
+	case "":
+		return nil, nil
+	case CassetteRecord, CassetteReplay:
+	default:
+		return nil, fmt.Errorf("unknown cassette mode %q", cfg.Mode)
+	}
+	if cfg.Dir == "" {
+		return nil, errors.New("cassette dir is required")
+	}
+
+	if cfg.Mode == CassetteRecord {
+		if err := os.MkdirAll(cfg.Dir, 0o755); err != nil {
+			return nil, fmt.Errorf("failed to create cassette dir: %w", err)
+		}
+		// Запись продолжает кассету, если в каталоге уже есть запросы
+		files, err := filepath.Glob(filepath.Join(cfg.Dir, "*.json"))
+		if err != nil {
+			return nil, fmt.Errorf("failed to list cassette: %w", err)
+		}
+		seq := 0
+		for _, file := range files {
+			var n int
+			if _, err := fmt.Sscanf(filepath.Base(file), "%06d.json", &n); err == nil {
+				seq = max(seq, n)
+			}
+		}
+		return &cassetteRecorder{dir: cfg.Dir, baseURL: baseURL, next: next, seq: seq}, nil
+	}
+	return loadCassette(cfg.Dir, baseURL)
+}
+
+// relativeURL отрезает от адреса запроса адрес Jira.
+func relativeURL(baseURL string, u *url.URL) string {
+	full := u.String()
+	if base, err := url.Parse(baseURL); err == nil {
+		base.User = nil
+		if rel := strings.TrimPrefix(full, strings.TrimSuffix(base.String(), "/")); rel != full {
+			return rel
+		}
+	}
+	// Запрос не к Jira: в кассету не попадают логин и пароль из адреса
+	clean := *u
+	clean.User = nil
+	return clean.String()
+}
+
+func scrubHeader(header http.Header) http.Header {
+	scrubbed := header.Clone()
+	for _, name := range scrubbedHeaders {
+		if _, ok := scrubbed[name]; ok {
+			scrubbed.Set(name, "[scrubbed]")
+		}
+	}
+	return scrubbed
+}
+
+// cassetteRecorder выполняет запросы через next и записывает каждый
+// запрос с ответом в отдельный файл. Номера файлов задают порядок
+// запросов, в том числе повторов одного и того же запроса.
+type cassetteRecorder struct {
+	dir     string
+	baseURL string
+	next    http.RoundTripper
+
+	mu  sync.Mutex
+	seq int
+}
+
+func (r *cassetteRecorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := r.next.RoundTrip(req)
+
+	r.mu.Lock()
+	r.seq++
+	seq := r.seq
+	r.mu.Unlock()
+
+	record := interaction{
+		Seq:        seq,
+		RecordedAt: time.Now().UTC(),
+		Request: recordedRequest{
+			Method: req.Method,
+			URL:    relativeURL(r.baseURL, req.URL),
+			Header: scrubHeader(req.Header),
+		},
+	}
+
+	if err != nil {
+		record.Error = err.Error()
+		if saveErr := r.save(record, nil); saveErr != nil {
+			return nil, saveErr
+		}
+		return nil, err
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		record.Error = err.Error()
+		if saveErr := r.save(record, nil); saveErr != nil {
+			return nil, saveErr
+		}
+		return nil, err
+	}
+
+	record.Response = &recordedResponse{
+		StatusCode: resp.StatusCode,
+		Header:     scrubHeader(resp.Header),
+		BodyFile:   fmt.Sprintf("%06d.body", seq),
+	}
+	if err := r.save(record, body); err != nil {
+		return nil, err
+	}
+
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	return resp, nil
+}
+
+func (r *cassetteRecorder) save(record interaction, body []byte) error {
+	if record.Response != nil {
+		if err := os.WriteFile(filepath.Join(r.dir, record.Response.BodyFile), body, 0o644); err != nil {
+			return fmt.Errorf("failed to record response body: %w", err)
+		}
+	}
+
+	data, err := json.MarshalIndent(record, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode interaction: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(r.dir, fmt.Sprintf("%06d.json", record.Seq)), data, 0o644); err != nil {
+		return fmt.Errorf("failed to record interaction: %w", err)
+	}
+	return nil
+}
+
+// cassettePlayer отвечает на запросы из кассеты. Одинаковые запросы
+// получают записанные ответы по порядку, так воспроизводятся повторы
+// после 429 и 5xx. Запросы сопоставляются по методу и URL, поэтому
+// инкрементальная синхронизация воспроизводится только с тем же
+// watermark, что и при записи.
+type cassettePlayer struct {
+	dir     string
+	baseURL string
+
+	mu      sync.Mutex
+	pending map[string][]interaction
+}
+
+func loadCassette(dir, baseURL string) (*cassettePlayer, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list cassette: %w", err)
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("cassette %s is empty", dir)
+	}
+
+	var records []interaction
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read cassette: %w", err)
+		}
+		var record interaction
+		if err := json.Unmarshal(data, &record); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", filepath.Base(file), err)
+		}
+		records = append(records, record)
+	}
+	sort.Slice(records, func(i, j int) bool { return records[i].Seq < records[j].Seq })
+
+	p := &cassettePlayer{dir: dir, baseURL: baseURL, pending: make(map[string][]interaction)}
+	for _, record := range records {
+		p.pending[record.key()] = append(p.pending[record.key()], record)
+	}
+	return p, nil
+}
+
+func (p *cassettePlayer) RoundTrip(req *http.Request) (*http.Response, error) {
+	key := req.Method + " " + relativeURL(p.baseURL, req.URL)
+
+	p.mu.Lock()
+	queue := p.pending[key]
+	if len(queue) == 0 {
+		p.mu.Unlock()
+		return nil, fmt.Errorf("%w: %s", ErrNotRecorded, key)
+	}
+	record := queue[0]
+	p.pending[key] = queue[1:]
+	p.mu.Unlock()
+
+	if record.Response == nil {
+		return nil, errors.New(record.Error)
+	}
+
+	body, err := os.ReadFile(filepath.Join(p.dir, record.Response.BodyFile))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read recorded body: %w", err)
+	}
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", record.Response.StatusCode, http.StatusText(record.Response.StatusCode)),
+		StatusCode:    record.Response.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        record.Response.Header.Clone(),
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
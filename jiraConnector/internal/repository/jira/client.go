@@ -3,6 +3,7 @@ package jira
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"jiraAnalyzer/jiraConnector/internal/models"
@@ -10,10 +11,23 @@ import (
 	"math"
 	"math/rand"
 	"net/http"
+	"net/url"
 	"strconv"
 	"time"
 )
 
+const (
+	// jqlTimeLayout - формат даты, который Jira принимает в JQL.
+	jqlTimeLayout = "2006-01-02 15:04"
+	// issueFields - поля задачи, запрашиваемые при поиске. Комментарии
+	// не входят в *navigable, поэтому запрашиваются явно.
+	issueFields = "*navigable,comment,worklog,issuelinks"
+	// worklogPageSize - размер страницы при загрузке списаний задачи.
+	worklogPageSize = 1000
+	// defaultRequestTimeout - таймаут запроса к Jira, если он не задан
+	defaultRequestTimeout = 60 * time.Second
+)
+
 type ClientConfig struct {
 	JiraUrl           string        `yaml:"jiraUrl"`
 	IssueInOneRequest int           `yaml:"issueInOneRequest"`
@@ -21,23 +35,63 @@ type ClientConfig struct {
 	MaxAttempts       int           `yaml:"maxAttempts"`
 	MaxTimeSleep      time.Duration `yaml:"maxTimeSleep"`
 	MinTimeSleep      time.Duration `yaml:"minTimeSleep"`
+	Auth              AuthConfig    `yaml:"auth"`
+	// RequestsPerSecond - ограничение скорости запросов к Jira, общее
+	// для всех потоков и одновременных синхронизаций проектов
+	RequestsPerSecond float64 `yaml:"requestsPerSecond"`
+	// Burst - сколько запросов можно выполнить подряд без ожидания
+	Burst int `yaml:"burst"`
+	// Timeout - таймаут одного запроса, включая чтение ответа
+	Timeout        time.Duration        `yaml:"timeout"`
+	CircuitBreaker CircuitBreakerConfig `yaml:"circuitBreaker"`
+	// Cassette - запись запросов к Jira или их воспроизведение без сети
+	Cassette CassetteConfig `yaml:"cassette"`
 }
 
 type Jira struct {
-	cfg        ClientConfig
-	clientPool []*http.Client
+	cfg     ClientConfig
+	client  *http.Client
+	auth    authenticator
+	limiter *RateLimiter
+	breaker *circuitBreaker
 }
 
-func NewJiraClient(cfg ClientConfig) *Jira {
-	clientPool := make([]*http.Client, cfg.ThreadCount)
-	for i := 0; i < cfg.ThreadCount; i++ {
-		clientPool[i] = &http.Client{Timeout: 60 * time.Second} //стоит убрать магическое число
+func NewJiraClient(cfg ClientConfig) (*Jira, error) {
+	// http.Client безопасен для конкурентного использования, пул соединений
+	// общий для всех потоков
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = defaultRequestTimeout
 	}
+	client := &http.Client{Timeout: timeout}
 
-	return &Jira{
-		cfg:        cfg,
-		clientPool: clientPool,
+	// Запросы за токеном OAuth идут мимо кассеты, чтобы токены
+	// не попадали в запись
+	requestClient := client
+	transport, err := newCassetteTransport(cfg.Cassette, cfg.JiraUrl, http.DefaultTransport)
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure jira cassette: %w", err)
+	}
+	if transport != nil {
+		requestClient = &http.Client{Timeout: timeout, Transport: transport}
+		log.Printf("Jira cassette mode %s, dir %s", cfg.Cassette.Mode, cfg.Cassette.Dir)
+	}
+
+	var auth authenticator = noAuth{}
+	// При воспроизведении Jira не нужна, как и учётные данные
+	if cfg.Cassette.Mode != CassetteReplay {
+		if auth, err = newAuthenticator(cfg.Auth, client); err != nil {
+			return nil, fmt.Errorf("failed to configure jira auth: %w", err)
+		}
 	}
+
+	return &Jira{
+		cfg:     cfg,
+		client:  requestClient,
+		auth:    auth,
+		limiter: NewRateLimiter(cfg.RequestsPerSecond, cfg.Burst),
+		breaker: newCircuitBreaker(cfg.CircuitBreaker),
+	}, nil
 }
 
 func (c *Jira) GetAllProjects(ctx context.Context) ([]models.JiraProject, error) {
@@ -47,12 +101,17 @@ func (c *Jira) GetAllProjects(ctx context.Context) ([]models.JiraProject, error)
 	return projects, err
 }
 
-func (c *Jira) GetProjectIssues(ctx context.Context, projectKey string, startAt int) ([]models.JiraIssue, error) {
-	url := fmt.Sprintf("%s/rest/api/2/search?jql=project=%s&startAt=%d&maxResults=%d&expand=changelog",
-		c.cfg.JiraUrl, projectKey, startAt, c.cfg.IssueInOneRequest)
+func (c *Jira) GetProjectIssues(ctx context.Context, projectKey string, updatedSince *time.Time, startAt int) ([]models.JiraIssue, error) {
+	params := url.Values{}
+	params.Set("jql", projectJQL(projectKey, updatedSince))
+	params.Set("startAt", strconv.Itoa(startAt))
+	params.Set("maxResults", strconv.Itoa(c.cfg.IssueInOneRequest))
+	params.Set("expand", "changelog")
+	params.Set("fields", issueFields)
+	searchURL := fmt.Sprintf("%s/rest/api/2/search?%s", c.cfg.JiraUrl, params.Encode())
 
 	var response models.JiraSearchResponse
-	err := c.doRequestWithRetry(url, &response, ctx)
+	err := c.doRequestWithRetry(searchURL, &response, ctx)
 	if err != nil {
 		log.Printf("Failed to fetch issues for project %s: %v", projectKey, err)
 		return nil, fmt.Errorf("failed to fetch issues: %w", err)
@@ -60,65 +119,211 @@ func (c *Jira) GetProjectIssues(ctx context.Context, projectKey string, startAt
 	return response.Issues, nil
 }
 
-func (c *Jira) GetIssueCount(ctx context.Context, projectKey string) (int, error) {
-	url := fmt.Sprintf("%s/rest/api/2/search?jql=project=%s&maxResults=0", c.cfg.JiraUrl, projectKey)
+func (c *Jira) GetIssueCount(ctx context.Context, projectKey string, updatedSince *time.Time) (int, error) {
+	params := url.Values{}
+	params.Set("jql", projectJQL(projectKey, updatedSince))
+	params.Set("maxResults", "0")
+	searchURL := fmt.Sprintf("%s/rest/api/2/search?%s", c.cfg.JiraUrl, params.Encode())
+
 	var response struct {
 		Total int `json:"total"`
 	}
 
-	if err := c.doRequestWithRetry(url, &response, ctx); err != nil {
+	if err := c.doRequestWithRetry(searchURL, &response, ctx); err != nil {
 		return 0, fmt.Errorf("failed to get issue count for project %s: %w", projectKey, err)
 	}
 
 	return response.Total, nil
 }
 
+// GetIssueWorklogs загружает все списания времени по задаче постранично.
+func (c *Jira) GetIssueWorklogs(ctx context.Context, issueKey string) ([]models.JiraWorklog, error) {
+	var worklogs []models.JiraWorklog
+
+	for {
+		params := url.Values{}
+		params.Set("startAt", strconv.Itoa(len(worklogs)))
+		params.Set("maxResults", strconv.Itoa(worklogPageSize))
+		worklogURL := fmt.Sprintf("%s/rest/api/2/issue/%s/worklog?%s", c.cfg.JiraUrl, url.PathEscape(issueKey), params.Encode())
+
+		var page models.JiraWorklogs
+		if err := c.doRequestWithRetry(worklogURL, &page, ctx); err != nil {
+			return nil, fmt.Errorf("failed to fetch worklogs for issue %s: %w", issueKey, err)
+		}
+
+		worklogs = append(worklogs, page.Worklogs...)
+		if len(page.Worklogs) == 0 || len(worklogs) >= page.Total {
+			return worklogs, nil
+		}
+	}
+}
+
+// projectJQL строит запрос задач проекта. Если задан updatedSince,
+// выбираются только задачи, изменённые начиная с этого момента. Задачи
+// упорядочены по ключу: изменение задачи во время синхронизации не
+// переставляет её, а новые задачи попадают в конец выборки.
+func projectJQL(projectKey string, updatedSince *time.Time) string {
+	jql := fmt.Sprintf("project=%s", projectKey)
+	if updatedSince != nil {
+		jql += fmt.Sprintf(" AND updated >= \"%s\"", updatedSince.Format(jqlTimeLayout))
+	}
+	return jql + " ORDER BY key ASC"
+}
+
+// doRequestWithRetry выполняет GET-запрос и декодирует ответ. Временные
+// ошибки (5xx, сетевые, 429) повторяются до MaxAttempts раз, постоянные
+// и ошибки авторизации возвращаются сразу как *RequestError.
 func (c *Jira) doRequestWithRetry(url string, response interface{}, ctx context.Context) error {
 	attempt := 0
+	reauthorized := false
 
 	for {
-		select {
-		case <-ctx.Done():
-			return ctx.Err()
-		default:
+		// Токен лимитера берём на каждую попытку, включая повторы после 429
+		if err := c.limiter.Wait(ctx); err != nil {
+			return err
+		}
+		if err := c.breaker.allow(); err != nil {
+			return err
 		}
 
-		clientIndex := rand.Intn(len(c.clientPool))
 		req, _ := http.NewRequestWithContext(ctx, "GET", url, nil)
-		resp, err := c.clientPool[clientIndex].Do(req)
+		if err := c.auth.authorize(ctx, req); err != nil {
+			c.breaker.release()
+			return &RequestError{Kind: ErrorAuth, URL: url, Err: fmt.Errorf("failed to authorize request: %w", err)}
+		}
+		resp, err := c.client.Do(req)
 		if err != nil {
-			sleepTime := c.cfg.MinTimeSleep * time.Duration(math.Pow(2, float64(attempt)))
-			if sleepTime > c.cfg.MaxTimeSleep || attempt >= c.cfg.MaxAttempts {
-				return fmt.Errorf("max retries exceeded: %w", err)
+			// Отмена контекста не говорит о доступности Jira
+			if ctx.Err() != nil {
+				c.breaker.release()
+				return ctx.Err()
+			}
+			if errors.Is(err, ErrNotRecorded) {
+				c.breaker.release()
+				return &RequestError{Kind: ErrorPermanent, URL: url, Err: err}
+			}
+			c.breaker.failure()
+
+			if attempt >= c.cfg.MaxAttempts {
+				return &RequestError{Kind: ErrorRetryable, URL: url, Err: fmt.Errorf("max retries exceeded: %w", err)}
+			}
+			if err := sleepContext(ctx, c.backoff(attempt)); err != nil {
+				return err
 			}
-			time.Sleep(sleepTime)
 			attempt++
 			continue
 		}
-		defer resp.Body.Close()
+		c.limiter.Observe(resp)
 
-		if resp.StatusCode == http.StatusTooManyRequests {
-			retryAfter := resp.Header.Get("Retry-After")
-			if retry, err := strconv.Atoi(retryAfter); err == nil {
-				time.Sleep(time.Duration(retry) * time.Second)
-				continue
+		if resp.StatusCode == http.StatusOK {
+			c.breaker.success()
+			err = json.NewDecoder(resp.Body).Decode(response)
+			resp.Body.Close()
+			if err != nil {
+				return &RequestError{Kind: ErrorMalformed, StatusCode: resp.StatusCode, URL: url,
+					Err: fmt.Errorf("failed to decode response: %w", err)}
 			}
+			return nil
+		}
 
-			sleepTime := c.cfg.MinTimeSleep * time.Duration(math.Pow(2, float64(attempt)))
-			if sleepTime > c.cfg.MaxTimeSleep {
-				return fmt.Errorf("rate limit exceeded: %v", err)
-			}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		reqErr := &RequestError{Kind: ClassifyStatus(resp.StatusCode), StatusCode: resp.StatusCode, URL: url, Body: string(body)}
 
-			time.Sleep(sleepTime)
-			attempt++
+		if resp.StatusCode >= http.StatusInternalServerError {
+			c.breaker.failure()
+		} else {
+			// Jira ответила - она доступна, даже если запрос отклонён
+			c.breaker.success()
+		}
+
+		// Просроченный OAuth-токен обновляем и повторяем запрос один раз
+		if resp.StatusCode == http.StatusUnauthorized && !reauthorized && c.auth.invalidate() {
+			reauthorized = true
 			continue
 		}
 
-		if resp.StatusCode != http.StatusOK {
-			body, _ := io.ReadAll(resp.Body)
-			return fmt.Errorf("status: %d, body: %s", resp.StatusCode, body)
+		if reqErr.Kind != ErrorRetryable {
+			return reqErr
+		}
+		if attempt >= c.cfg.MaxAttempts {
+			return fmt.Errorf("max retries exceeded: %w", reqErr)
+		}
+
+		// Ожидание по Retry-After после 429 выполняет лимитер
+		if resp.StatusCode != http.StatusTooManyRequests {
+			if err := sleepContext(ctx, c.backoff(attempt)); err != nil {
+				return err
+			}
 		}
+		attempt++
+	}
+}
+
+// backoff - экспоненциальная задержка перед повтором со случайным
+// разбросом, чтобы потоки не повторяли запросы одновременно.
+func (c *Jira) backoff(attempt int) time.Duration {
+	sleepTime := c.cfg.MinTimeSleep * time.Duration(math.Pow(2, float64(attempt)))
+	if sleepTime > c.cfg.MaxTimeSleep {
+		sleepTime = c.cfg.MaxTimeSleep
+	}
+	if sleepTime <= 0 {
+		return 0
+	}
+	// Половина задержки фиксирована, вторая половина случайна
+	half := sleepTime / 2
+	return half + time.Duration(rand.Int63n(int64(sleepTime-half)+1))
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// RateLimiterMetrics возвращает текущее состояние общего лимитера запросов.
+func (c *Jira) RateLimiterMetrics() models.RateLimiterMetrics {
+	return c.limiter.Metrics()
+}
 
-		return json.NewDecoder(resp.Body).Decode(response)
+// GetIssueRef возвращает текущий ключ и проект задачи или nil, если задача
+// удалена или недоступна.
+func (c *Jira) GetIssueRef(ctx context.Context, issueKey string) (*models.JiraIssueRef, error) {
+	issueURL := fmt.Sprintf("%s/rest/api/2/issue/%s?fields=project", c.cfg.JiraUrl, url.PathEscape(issueKey))
+
+	var ref models.JiraIssueRef
+	err := c.doRequestWithRetry(issueURL, &ref, ctx)
+	var reqErr *RequestError
+	if errors.As(err, &reqErr) && reqErr.StatusCode == http.StatusNotFound {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to fetch issue %s: %w", issueKey, err)
 	}
+
+	return &ref, nil
+}
+
+// GetIssue загружает задачу с теми же полями, что и поиск, или возвращает
+// nil, если задача удалена или недоступна.
+func (c *Jira) GetIssue(ctx context.Context, issueKey string) (*models.JiraIssue, error) {
+	params := url.Values{}
+	params.Set("expand", "changelog")
+	params.Set("fields", issueFields)
+	issueURL := fmt.Sprintf("%s/rest/api/2/issue/%s?%s", c.cfg.JiraUrl, url.PathEscape(issueKey), params.Encode())
+
+	var issue models.JiraIssue
+	err := c.doRequestWithRetry(issueURL, &issue, ctx)
+	var reqErr *RequestError
+	if errors.As(err, &reqErr) && reqErr.StatusCode == http.StatusNotFound {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to fetch issue %s: %w", issueKey, err)
+	}
+
+	return &issue, nil
 }
@@ -0,0 +1,80 @@
+package jira
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// ErrorKind - класс ошибки запроса к Jira, от которого зависит,
+// имеет ли смысл повторять запрос.
+type ErrorKind string
+
+const (
+	// ErrorRetryable - временная ошибка: 5xx, таймаут, обрыв соединения
+	ErrorRetryable ErrorKind = "retryable"
+	// ErrorPermanent - запрос некорректен (400, 404), повтор не поможет
+	ErrorPermanent ErrorKind = "permanent"
+	// ErrorAuth - учётные данные отклонены или недостаточно прав (401, 403)
+	ErrorAuth ErrorKind = "auth"
+	// ErrorMalformed - Jira ответила 200, но тело не разбирается как JSON
+	ErrorMalformed ErrorKind = "malformed"
+)
+
+// ErrCircuitOpen возвращается без обращения к Jira, пока circuit breaker
+// считает её недоступной.
+var ErrCircuitOpen = errors.New("jira is unavailable: circuit breaker is open")
+
+// RequestError - ошибка запроса к Jira с классом и статусом ответа.
+// StatusCode равен 0, если ответ не получен.
+type RequestError struct {
+	// Source - трекер, которому отправлен запрос, по умолчанию jira
+	Source     string
+	Kind       ErrorKind
+	StatusCode int
+	URL        string
+	Body       string
+	Err        error
+}
+
+func (e *RequestError) Error() string {
+	source := e.Source
+	if source == "" {
+		source = "jira"
+	}
+
+	switch {
+	case e.StatusCode != 0 && e.Body != "":
+		return fmt.Sprintf("%s %s error: status %d: %s", source, e.Kind, e.StatusCode, e.Body)
+	case e.StatusCode != 0 && e.Err != nil:
+		return fmt.Sprintf("%s %s error: status %d: %v", source, e.Kind, e.StatusCode, e.Err)
+	case e.StatusCode != 0:
+		return fmt.Sprintf("%s %s error: status %d", source, e.Kind, e.StatusCode)
+	default:
+		return fmt.Sprintf("%s %s error: %v", source, e.Kind, e.Err)
+	}
+}
+
+func (e *RequestError) Unwrap() error {
+	return e.Err
+}
+
+// IsKind проверяет, что в цепочке err есть RequestError класса kind.
+func IsKind(err error, kind ErrorKind) bool {
+	var reqErr *RequestError
+	return errors.As(err, &reqErr) && reqErr.Kind == kind
+}
+
+// ClassifyStatus определяет класс ошибки по статусу ответа.
+func ClassifyStatus(status int) ErrorKind {
+	switch {
+	case status == http.StatusUnauthorized || status == http.StatusForbidden:
+		return ErrorAuth
+	case status >= http.StatusInternalServerError,
+		status == http.StatusRequestTimeout,
+		status == http.StatusTooManyRequests:
+		return ErrorRetryable
+	default:
+		return ErrorPermanent
+	}
+}
@@ -0,0 +1,234 @@
+package jira
+
+import (
+	"context"
+	"jiraAnalyzer/jiraConnector/internal/models"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// rateLimitResetLayouts - форматы X-RateLimit-Reset. Jira Cloud отдаёт
+// ISO 8601 без секунд: 2021-05-26T00:00Z, GitHub и GitLab - Unix-время
+// в секундах.
+var rateLimitResetLayouts = []string{time.RFC3339, "2006-01-02T15:04Z07:00"}
+
+const (
+	defaultRequestsPerSecond = 10
+	// minRateFraction - ниже этой доли настроенной скорости лимитер не опускается
+	minRateFraction = 0.1
+	// rateRecoveryFraction - на сколько скорость растёт после каждого ответа
+	// без признаков приближения к лимиту
+	rateRecoveryFraction = 0.05
+	// nearLimitFraction - остаток квоты, при котором скорость снижается заранее
+	nearLimitFraction = 0.1
+	// defaultThrottlePause - пауза после 429 без Retry-After
+	defaultThrottlePause = time.Second
+	// rateDecreaseInterval - не чаще одного снижения скорости за интервал,
+	// чтобы одновременные 429 не обрушили скорость до минимума
+	rateDecreaseInterval = time.Second
+)
+
+// RateLimiter - token bucket, общий для всех запросов клиента трекера.
+// Скорость адаптируется к ответам: после 429 и при приближении к лимиту
+// (X-RateLimit-* в Jira и GitHub, RateLimit-* в GitLab) она снижается
+// вдвое, затем постепенно восстанавливается. Retry-After и исчерпанная
+// квота приостанавливают все запросы.
+type RateLimiter struct {
+	mu           sync.Mutex
+	baseRate     float64
+	rate         float64
+	burst        float64
+	tokens       float64
+	last         time.Time
+	pausedUntil  time.Time
+	lastDecrease time.Time
+
+	requests       int64
+	throttled      int64
+	rateReductions int64
+	waitTotal      time.Duration
+	remaining      *int
+	limit          *int
+}
+
+func NewRateLimiter(requestsPerSecond float64, burst int) *RateLimiter {
+	if requestsPerSecond <= 0 {
+		requestsPerSecond = defaultRequestsPerSecond
+	}
+	if burst <= 0 {
+		burst = max(1, int(requestsPerSecond))
+	}
+
+	return &RateLimiter{
+		baseRate: requestsPerSecond,
+		rate:     requestsPerSecond,
+		burst:    float64(burst),
+		tokens:   float64(burst),
+		last:     time.Now(),
+	}
+}
+
+// Wait блокирует до получения токена или отмены ctx.
+func (l *RateLimiter) Wait(ctx context.Context) error {
+	started := time.Now()
+	defer func() {
+		l.mu.Lock()
+		l.waitTotal += time.Since(started)
+		l.mu.Unlock()
+	}()
+
+	for {
+		l.mu.Lock()
+		now := time.Now()
+		l.refill(now)
+
+		var delay time.Duration
+		switch {
+		case now.Before(l.pausedUntil):
+			delay = l.pausedUntil.Sub(now)
+		case l.tokens >= 1:
+			l.tokens--
+			l.requests++
+			l.mu.Unlock()
+			return nil
+		default:
+			delay = time.Duration((1 - l.tokens) / l.rate * float64(time.Second))
+		}
+		l.mu.Unlock()
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+func (l *RateLimiter) refill(now time.Time) {
+	elapsed := now.Sub(l.last).Seconds()
+	l.last = now
+	l.tokens = min(l.burst, l.tokens+elapsed*l.rate)
+}
+
+// Observe подстраивает скорость по ответу трекера.
+func (l *RateLimiter) Observe(resp *http.Response) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	nearLimit := resp.Header.Get("X-RateLimit-NearLimit") == "true"
+	exhausted := false
+
+	prefix := "X-RateLimit-"
+	if resp.Header.Get(prefix+"Remaining") == "" && resp.Header.Get("RateLimit-Remaining") != "" {
+		prefix = "RateLimit-"
+	}
+	if limit, err := strconv.Atoi(resp.Header.Get(prefix + "Limit")); err == nil {
+		l.limit = &limit
+	}
+	if remaining, err := strconv.Atoi(resp.Header.Get(prefix + "Remaining")); err == nil {
+		l.remaining = &remaining
+		if l.limit != nil && float64(remaining) < float64(*l.limit)*nearLimitFraction {
+			nearLimit = true
+		}
+		// Квота исчерпана - ждём её сброса
+		if remaining == 0 {
+			exhausted = true
+			if reset, ok := parseRateLimitReset(resp.Header.Get(prefix + "Reset")); ok {
+				l.pause(reset)
+			}
+		}
+	}
+
+	// GitHub при исчерпанной квоте и вторичном лимите отвечает 403
+	limited := resp.StatusCode == http.StatusForbidden && (exhausted || resp.Header.Get("Retry-After") != "")
+
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests || limited:
+		l.throttled++
+		pause := defaultThrottlePause
+		if retryAfter, ok := parseRetryAfter(resp.Header.Get("Retry-After"), now); ok {
+			pause = retryAfter
+		}
+		l.pause(now.Add(pause))
+		l.decrease(now)
+	case nearLimit:
+		l.decrease(now)
+	default:
+		l.rate = min(l.baseRate, l.rate+l.baseRate*rateRecoveryFraction)
+	}
+}
+
+func (l *RateLimiter) pause(until time.Time) {
+	if until.After(l.pausedUntil) {
+		l.pausedUntil = until
+	}
+	l.tokens = 0
+}
+
+func (l *RateLimiter) decrease(now time.Time) {
+	if now.Sub(l.lastDecrease) < rateDecreaseInterval {
+		return
+	}
+	l.lastDecrease = now
+	l.rate = max(l.baseRate*minRateFraction, l.rate/2)
+	l.rateReductions++
+}
+
+func (l *RateLimiter) Metrics() models.RateLimiterMetrics {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	m := models.RateLimiterMetrics{
+		ConfiguredRate:     l.baseRate,
+		CurrentRate:        l.rate,
+		Burst:              int(l.burst),
+		Requests:           l.requests,
+		Throttled:          l.throttled,
+		RateReductions:     l.rateReductions,
+		WaitSeconds:        l.waitTotal.Seconds(),
+		RateLimitLimit:     l.limit,
+		RateLimitRemaining: l.remaining,
+	}
+	if l.pausedUntil.After(time.Now()) {
+		pausedUntil := l.pausedUntil
+		m.PausedUntil = &pausedUntil
+	}
+	return m
+}
+
+// parseRetryAfter разбирает Retry-After: число секунд или HTTP-дату.
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return max(0, date.Sub(now)), true
+	}
+	return 0, false
+}
+
+// parseRateLimitReset разбирает время сброса квоты из X-RateLimit-Reset.
+func parseRateLimitReset(value string) (time.Time, bool) {
+	if value == "" {
+		return time.Time{}, false
+	}
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.Unix(seconds, 0), true
+	}
+	for _, layout := range rateLimitResetLayouts {
+		if reset, err := time.Parse(layout, value); err == nil {
+			return reset, true
+		}
+	}
+	log.Printf("Unrecognized X-RateLimit-Reset %q, not pausing until quota reset", value)
+	return time.Time{}, false
+}
@@ -0,0 +1,172 @@
+package jira
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		value string
+		want  time.Duration
+		ok    bool
+	}{
+		{"", 0, false},
+		{"5", 5 * time.Second, true},
+		{"0", 0, true},
+		{"-1", 0, false},
+		{"soon", 0, false},
+		{now.Add(90 * time.Second).Format(http.TimeFormat), 90 * time.Second, true},
+		// Дата в прошлом - ждать не нужно
+		{now.Add(-time.Minute).Format(http.TimeFormat), 0, true},
+	}
+
+	for _, tt := range tests {
+		got, ok := parseRetryAfter(tt.value, now)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("parseRetryAfter(%q) = %s, %v, want %s, %v", tt.value, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestParseRateLimitReset(t *testing.T) {
+	tests := []struct {
+		value string
+		want  time.Time
+		ok    bool
+	}{
+		{"", time.Time{}, false},
+		{"2021-05-26T00:00:30Z", time.Date(2021, 5, 26, 0, 0, 30, 0, time.UTC), true},
+		// Формат Jira Cloud - без секунд
+		{"2021-05-26T00:00Z", time.Date(2021, 5, 26, 0, 0, 0, 0, time.UTC), true},
+		{"2021-05-26T03:00+03:00", time.Date(2021, 5, 26, 0, 0, 0, 0, time.UTC), true},
+		// Формат GitHub и GitLab - Unix-время
+		{"1622000000", time.Unix(1622000000, 0), true},
+		{"soon", time.Time{}, false},
+	}
+
+	for _, tt := range tests {
+		got, ok := parseRateLimitReset(tt.value)
+		if !got.Equal(tt.want) || ok != tt.ok {
+			t.Errorf("parseRateLimitReset(%q) = %s, %v, want %s, %v", tt.value, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestRateLimiterRefill(t *testing.T) {
+	l := NewRateLimiter(10, 5)
+	start := l.last
+	l.tokens = 0
+
+	l.refill(start.Add(200 * time.Millisecond))
+	if l.tokens < 1.99 || l.tokens > 2.01 {
+		t.Errorf("tokens after 200ms = %.2f, want 2", l.tokens)
+	}
+	// Токены не копятся больше burst
+	l.refill(start.Add(time.Minute))
+	if l.tokens != 5 {
+		t.Errorf("tokens after a minute = %.2f, want burst 5", l.tokens)
+	}
+}
+
+func TestRateLimiterDefaults(t *testing.T) {
+	l := NewRateLimiter(0, 0)
+	if l.rate != defaultRequestsPerSecond || l.burst != defaultRequestsPerSecond {
+		t.Errorf("rate %.1f, burst %.1f, want %d", l.rate, l.burst, defaultRequestsPerSecond)
+	}
+	if l := NewRateLimiter(0.5, 0); l.burst != 1 {
+		t.Errorf("burst for 0.5 rps = %.1f, want 1", l.burst)
+	}
+}
+
+func TestRateLimiterObserve(t *testing.T) {
+	response := func(status int, headers ...string) *http.Response {
+		resp := &http.Response{StatusCode: status, Header: make(http.Header)}
+		for i := 0; i+1 < len(headers); i += 2 {
+			resp.Header.Set(headers[i], headers[i+1])
+		}
+		return resp
+	}
+	reset := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+	resetMinute := time.Now().Add(time.Hour).UTC().Truncate(time.Minute)
+
+	tests := []struct {
+		name string
+		// rate - скорость до ответов при настроенной 10 rps
+		rate      float64
+		responses []*http.Response
+		wantRate  float64
+		// paused - ожидаемая пауза запросов, 0 - без паузы
+		paused time.Duration
+	}{
+		{"ok response recovers rate", 4, []*http.Response{response(http.StatusOK)}, 4.5, 0},
+		{"recovery stops at configured rate", 10, []*http.Response{response(http.StatusOK)}, 10, 0},
+		{"429 halves rate and pauses", 10, []*http.Response{response(http.StatusTooManyRequests, "Retry-After", "30")}, 5, 30 * time.Second},
+		{"429 without Retry-After pauses by default", 10, []*http.Response{response(http.StatusTooManyRequests)}, 5, defaultThrottlePause},
+		{"simultaneous 429s decrease once", 10, []*http.Response{
+			response(http.StatusTooManyRequests), response(http.StatusTooManyRequests),
+		}, 5, defaultThrottlePause},
+		{"rate does not drop below minimum", 1.5, []*http.Response{response(http.StatusTooManyRequests)}, 1, defaultThrottlePause},
+		{"near limit header", 10, []*http.Response{response(http.StatusOK, "X-RateLimit-NearLimit", "true")}, 5, 0},
+		{"low remaining quota", 10, []*http.Response{
+			response(http.StatusOK, "X-RateLimit-Limit", "100", "X-RateLimit-Remaining", "9"),
+		}, 5, 0},
+		{"exhausted quota pauses until reset", 10, []*http.Response{
+			response(http.StatusOK, "X-RateLimit-Limit", "100", "X-RateLimit-Remaining", "0", "X-RateLimit-Reset", reset.Format(time.RFC3339)),
+		}, 5, time.Until(reset)},
+		{"exhausted quota pauses until Jira Cloud reset", 10, []*http.Response{
+			response(http.StatusOK, "X-RateLimit-Limit", "100", "X-RateLimit-Remaining", "0", "X-RateLimit-Reset", resetMinute.Format("2006-01-02T15:04Z07:00")),
+		}, 5, time.Until(resetMinute)},
+		{"GitHub exhausted quota 403 pauses until reset", 10, []*http.Response{
+			response(http.StatusForbidden, "X-RateLimit-Limit", "5000", "X-RateLimit-Remaining", "0", "X-RateLimit-Reset", strconv.FormatInt(reset.Unix(), 10)),
+		}, 5, time.Until(reset)},
+		{"GitHub secondary limit 403 pauses", 10, []*http.Response{response(http.StatusForbidden, "Retry-After", "60")}, 5, time.Minute},
+		{"403 without rate limit headers", 4, []*http.Response{response(http.StatusForbidden)}, 4.5, 0},
+		{"GitLab low remaining quota", 10, []*http.Response{
+			response(http.StatusOK, "RateLimit-Limit", "600", "RateLimit-Remaining", "10"),
+		}, 5, 0},
+		{"GitLab exhausted quota pauses until reset", 10, []*http.Response{
+			response(http.StatusTooManyRequests, "RateLimit-Limit", "600", "RateLimit-Remaining", "0", "RateLimit-Reset", strconv.FormatInt(reset.Unix(), 10)),
+		}, 5, time.Until(reset)},
+	}
+
+	for _, tt := range tests {
+		l := NewRateLimiter(10, 10)
+		l.rate = tt.rate
+		for _, resp := range tt.responses {
+			l.Observe(resp)
+		}
+
+		if l.rate < tt.wantRate-0.001 || l.rate > tt.wantRate+0.001 {
+			t.Errorf("%s: rate = %.2f, want %.2f", tt.name, l.rate, tt.wantRate)
+		}
+		paused := time.Until(l.pausedUntil)
+		if tt.paused == 0 && paused > 0 {
+			t.Errorf("%s: paused for %s, want no pause", tt.name, paused)
+		}
+		if tt.paused > 0 && (paused > tt.paused || paused < tt.paused-time.Second) {
+			t.Errorf("%s: paused for %s, want %s", tt.name, paused, tt.paused)
+		}
+		if tt.paused > 0 && l.tokens != 0 {
+			t.Errorf("%s: %.2f tokens left during pause, want 0", tt.name, l.tokens)
+		}
+	}
+}
+
+func TestRateLimiterWaitRespectsPause(t *testing.T) {
+	l := NewRateLimiter(1000, 10)
+	l.pause(time.Now().Add(time.Hour))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := l.Wait(ctx); err != context.DeadlineExceeded {
+		t.Errorf("wait during pause = %v, want deadline exceeded", err)
+	}
+	if l.requests != 0 {
+		t.Errorf("requests = %d, want 0", l.requests)
+	}
+}
@@ -0,0 +1,239 @@
+package offline
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"jiraAnalyzer/jiraConnector/internal/models"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ErrNotInExport - запрошенных данных нет в выгрузке, а обратиться к Jira нельзя.
+var ErrNotInExport = errors.New("not available in offline export")
+
+// Export - задачи Jira, загруженные из сохранённых файлов. Export отдаёт
+// задачи так же, как клиент Jira, поэтому импорт проходит тем же путём
+// преобразования и сохранения, что и синхронизация.
+//
+// Поддерживаются страницы ответа /rest/api/2/search в JSON (сохранённые
+// с expand=changelog и fields=*navigable,comment,worklog,issuelinks, как их
+// запрашивает коннектор) и XML-выгрузка задач из поиска Jira.
+type Export struct {
+	pageSize int
+	projects map[string]models.JiraProject
+	// issues - задачи по проектам, отсортированные по ключу
+	issues map[string][]models.JiraIssue
+	byKey  map[string]models.JiraIssue
+}
+
+// Load читает выгрузку из файла или из всех *.json и *.xml файлов каталога.
+// Задача, встречающаяся в нескольких файлах, берётся в последней по updated
+// версии. pageSize - размер страницы, которой задачи отдаются при загрузке.
+func Load(path string, pageSize int) (*Export, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open export: %w", err)
+	}
+
+	files := []string{path}
+	if info.IsDir() {
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read export directory: %w", err)
+		}
+		files = files[:0]
+		for _, entry := range entries {
+			ext := strings.ToLower(filepath.Ext(entry.Name()))
+			if !entry.IsDir() && (ext == ".json" || ext == ".xml") {
+				files = append(files, filepath.Join(path, entry.Name()))
+			}
+		}
+		sort.Strings(files)
+	}
+
+	e := &Export{
+		pageSize: pageSize,
+		projects: make(map[string]models.JiraProject),
+		issues:   make(map[string][]models.JiraIssue),
+		byKey:    make(map[string]models.JiraIssue),
+	}
+
+	for _, file := range files {
+		issues, err := readFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load %s: %w", file, err)
+		}
+		log.Printf("Loaded %d issues from %s", len(issues), file)
+
+		for _, issue := range issues {
+			e.add(issue)
+		}
+	}
+
+	for _, issue := range e.byKey {
+		projectKey := issue.Fields.Project.Key
+		e.issues[projectKey] = append(e.issues[projectKey], issue)
+	}
+	for _, issues := range e.issues {
+		sort.Slice(issues, func(i, j int) bool { return issues[i].Key < issues[j].Key })
+	}
+
+	return e, nil
+}
+
+func readFile(file string) ([]models.JiraIssue, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	if strings.EqualFold(filepath.Ext(file), ".xml") {
+		return parseXML(data)
+	}
+
+	var page models.JiraSearchResponse
+	if err := json.Unmarshal(data, &page); err != nil {
+		return nil, err
+	}
+	return page.Issues, nil
+}
+
+func (e *Export) add(issue models.JiraIssue) {
+	// Проект задачи может не попасть в выгрузку, если поле project не запрошено
+	if issue.Fields.Project.Key == "" {
+		if i := strings.LastIndex(issue.Key, "-"); i > 0 {
+			issue.Fields.Project.Key = issue.Key[:i]
+		}
+	}
+
+	project := issue.Fields.Project
+	if project.Name == "" {
+		project.Name = project.Key
+	}
+	if _, ok := e.projects[project.Key]; !ok || e.projects[project.Key].Name == project.Key {
+		e.projects[project.Key] = project
+	}
+
+	if existing, ok := e.byKey[issue.Key]; ok && !updatedAfter(issue, existing) {
+		return
+	}
+	e.byKey[issue.Key] = issue
+}
+
+func updatedAfter(a, b models.JiraIssue) bool {
+	at, _ := time.Parse(models.JiraTimeLayout, a.Fields.Updated)
+	bt, _ := time.Parse(models.JiraTimeLayout, b.Fields.Updated)
+	return !at.Before(bt)
+}
+
+// ProjectKeys возвращает ключи проектов выгрузки.
+func (e *Export) ProjectKeys() []string {
+	keys := make([]string, 0, len(e.issues))
+	for key := range e.issues {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (e *Export) GetAllProjects(ctx context.Context) ([]models.JiraProject, error) {
+	projects := make([]models.JiraProject, 0, len(e.projects))
+	for _, project := range e.projects {
+		projects = append(projects, project)
+	}
+	return projects, nil
+}
+
+func (e *Export) GetProjectIssues(ctx context.Context, projectKey string, updatedSince *time.Time, startAt int) ([]models.JiraIssue, error) {
+	issues := e.projectIssues(projectKey, updatedSince)
+	if startAt >= len(issues) {
+		return nil, nil
+	}
+	return issues[startAt:min(startAt+e.pageSize, len(issues))], nil
+}
+
+func (e *Export) GetIssueCount(ctx context.Context, projectKey string, updatedSince *time.Time) (int, error) {
+	return len(e.projectIssues(projectKey, updatedSince)), nil
+}
+
+func (e *Export) projectIssues(projectKey string, updatedSince *time.Time) []models.JiraIssue {
+	issues := e.issues[projectKey]
+	if updatedSince == nil {
+		return issues
+	}
+
+	filtered := make([]models.JiraIssue, 0, len(issues))
+	for _, issue := range issues {
+		updated, err := time.Parse(models.JiraTimeLayout, issue.Fields.Updated)
+		if err != nil || !updated.Before(*updatedSince) {
+			filtered = append(filtered, issue)
+		}
+	}
+	return filtered
+}
+
+// GetIssueWorklogs возвращает списания, сохранённые в выгрузке. Дозагрузить
+// обрезанный список нельзя, поэтому сохраняется только его начало.
+func (e *Export) GetIssueWorklogs(ctx context.Context, issueKey string) ([]models.JiraWorklog, error) {
+	issue, ok := e.byKey[issueKey]
+	if !ok {
+		return nil, fmt.Errorf("issue %s: %w", issueKey, ErrNotInExport)
+	}
+
+	worklog := issue.Fields.Worklog
+	if worklog == nil {
+		return nil, nil
+	}
+	if worklog.Truncated() {
+		log.Printf("Issue %s: export contains %d of %d worklogs", issueKey, len(worklog.Worklogs), worklog.Total)
+	}
+	return worklog.Worklogs, nil
+}
+
+// GetIssueRef возвращает ключ и проект задачи из выгрузки. По выгрузке нельзя
+// отличить удалённую задачу от не попавшей в неё, поэтому для отсутствующей
+// задачи возвращается ошибка.
+func (e *Export) GetIssueRef(ctx context.Context, issueKey string) (*models.JiraIssueRef, error) {
+	issue, ok := e.byKey[issueKey]
+	if !ok {
+		return nil, fmt.Errorf("issue %s: %w", issueKey, ErrNotInExport)
+	}
+
+	ref := &models.JiraIssueRef{Key: issue.Key}
+	ref.Fields.Project = issue.Fields.Project
+	return ref, nil
+}
+
+// GetIssue возвращает задачу из выгрузки, отсутствующая задача - ошибка,
+// как и в GetIssueRef.
+func (e *Export) GetIssue(ctx context.Context, issueKey string) (*models.JiraIssue, error) {
+	issue, ok := e.byKey[issueKey]
+	if !ok {
+		return nil, fmt.Errorf("issue %s: %w", issueKey, ErrNotInExport)
+	}
+	return &issue, nil
+}
+
+// Доски и спринты в выгрузку не входят
+
+func (e *Export) GetProjectBoards(ctx context.Context, projectKey string) ([]models.JiraBoard, error) {
+	return nil, nil
+}
+
+func (e *Export) GetBoardSprints(ctx context.Context, boardID int) ([]models.JiraSprint, error) {
+	return nil, nil
+}
+
+func (e *Export) GetSprintIssueKeys(ctx context.Context, sprintID int) ([]string, error) {
+	return nil, nil
+}
+
+func (e *Export) RateLimiterMetrics() models.RateLimiterMetrics {
+	return models.RateLimiterMetrics{}
+}
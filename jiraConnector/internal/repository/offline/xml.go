@@ -0,0 +1,304 @@
+package offline
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"jiraAnalyzer/jiraConnector/internal/models"
+	"strings"
+	"time"
+)
+
+// XML-выгрузка задач Jira (RSS, "Export XML" в поиске задач). В ней нет
+// истории изменений и списаний, описание отдаётся в HTML, а у компонентов,
+// версий и связей нет идентификаторов Jira: для них строятся устойчивые
+// идентификаторы из имён, а при следующей онлайн-синхронизации связи
+// и состав компонентов и версий задач заменяются данными Jira.
+
+type xmlExport struct {
+	Items []xmlItem `xml:"channel>item"`
+}
+
+type xmlItem struct {
+	Key         string           `xml:"key"`
+	Project     xmlProject       `xml:"project"`
+	Summary     string           `xml:"summary"`
+	Description string           `xml:"description"`
+	Type        string           `xml:"type"`
+	Priority    string           `xml:"priority"`
+	Status      string           `xml:"status"`
+	Created     string           `xml:"created"`
+	Updated     string           `xml:"updated"`
+	Resolved    string           `xml:"resolved"`
+	Creator     *xmlUser         `xml:"creator"`
+	Reporter    *xmlUser         `xml:"reporter"`
+	Assignee    *xmlUser         `xml:"assignee"`
+	Labels      []string         `xml:"labels>label"`
+	Components  []string         `xml:"component"`
+	FixVersions []string         `xml:"fixVersion"`
+	TimeSpent   xmlTimeSpent     `xml:"timespent"`
+	Comments    []xmlComment     `xml:"comments>comment"`
+	LinkTypes   []xmlLinkType    `xml:"issuelinks>issuelinktype"`
+	Custom      []xmlCustomField `xml:"customfields>customfield"`
+}
+
+type xmlProject struct {
+	Key  string `xml:"key,attr"`
+	Name string `xml:",chardata"`
+}
+
+// xmlUser - пользователь: accountid в Jira Cloud, username и, если
+// выгружен, key в Jira Server.
+type xmlUser struct {
+	AccountID   string `xml:"accountid,attr"`
+	Key         string `xml:"key,attr"`
+	Username    string `xml:"username,attr"`
+	DisplayName string `xml:",chardata"`
+}
+
+type xmlTimeSpent struct {
+	Seconds int `xml:"seconds,attr"`
+}
+
+// xmlComment - комментарий, author - accountId или имя пользователя.
+type xmlComment struct {
+	ID      string `xml:"id,attr"`
+	Author  string `xml:"author,attr"`
+	Created string `xml:"created,attr"`
+	Updated string `xml:"updated,attr"`
+	Body    string `xml:",chardata"`
+}
+
+type xmlLinkType struct {
+	Name    string       `xml:"name"`
+	Outward xmlLinkGroup `xml:"outwardlinks"`
+	Inward  xmlLinkGroup `xml:"inwardlinks"`
+}
+
+type xmlLinkGroup struct {
+	Description string   `xml:"description,attr"`
+	Keys        []string `xml:"issuelink>issuekey"`
+}
+
+type xmlCustomField struct {
+	ID     string   `xml:"id,attr"`
+	Values []string `xml:"customfieldvalues>customfieldvalue"`
+}
+
+// parseXML разбирает XML-выгрузку в задачи в формате REST API.
+func parseXML(data []byte) ([]models.JiraIssue, error) {
+	var export xmlExport
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	// Выгрузка содержит HTML-сущности в описаниях
+	decoder.Strict = false
+	decoder.Entity = xml.HTMLEntity
+	if err := decoder.Decode(&export); err != nil {
+		return nil, fmt.Errorf("failed to parse xml export: %w", err)
+	}
+
+	// У авторов комментариев в выгрузке есть только идентификатор, автор
+	// берётся у того же пользователя в других полях. Задача содержит
+	// описание только тех направлений связи, которые у неё есть, поэтому
+	// описания типов связей собираются по всей выгрузке.
+	users := make(map[string]models.JiraAuthor)
+	linkTypes := make(map[string]models.JiraLinkType)
+	for _, item := range export.Items {
+		for _, user := range []*xmlUser{item.Creator, item.Reporter, item.Assignee} {
+			author, ok := user.author()
+			if !ok {
+				continue
+			}
+			for _, id := range []string{author.AccountID, author.Key, author.Name} {
+				if id != "" {
+					users[id] = author
+				}
+			}
+		}
+		for _, linkType := range item.LinkTypes {
+			known := linkTypes[linkType.Name]
+			known.Name = linkType.Name
+			if linkType.Outward.Description != "" {
+				known.Outward = linkType.Outward.Description
+			}
+			if linkType.Inward.Description != "" {
+				known.Inward = linkType.Inward.Description
+			}
+			linkTypes[linkType.Name] = known
+		}
+	}
+
+	issues := make([]models.JiraIssue, 0, len(export.Items))
+	for _, item := range export.Items {
+		issue, err := item.issue(users, linkTypes)
+		if err != nil {
+			return nil, fmt.Errorf("issue %s: %w", item.Key, err)
+		}
+		issues = append(issues, issue)
+	}
+	return issues, nil
+}
+
+func (item xmlItem) issue(users map[string]models.JiraAuthor, linkTypes map[string]models.JiraLinkType) (models.JiraIssue, error) {
+	fields := models.JiraFields{
+		Project:     models.JiraProject{Key: item.Project.Key, Name: strings.TrimSpace(item.Project.Name)},
+		Created:     xmlTime(item.Created),
+		Updated:     xmlTime(item.Updated),
+		Resolved:    xmlTime(item.Resolved),
+		Summary:     item.Summary,
+		Description: item.Description,
+		IssueType:   models.JiraType{Name: item.Type},
+		Priority:    models.JiraPriority{Name: item.Priority},
+		Status:      models.JiraStatus{Name: item.Status},
+		TimeSpent:   item.TimeSpent.Seconds,
+		Labels:      item.Labels,
+		Custom:      make(map[string]json.RawMessage),
+	}
+
+	creator := item.Creator
+	if creator == nil {
+		creator = item.Reporter
+	}
+	if author, ok := creator.author(); ok {
+		fields.Creator = author
+	}
+	if author, ok := item.Assignee.author(); ok {
+		fields.Assignee = &author
+	}
+
+	for _, c := range item.Comments {
+		updated := c.Updated
+		if updated == "" {
+			updated = c.Created
+		}
+		author, ok := users[c.Author]
+		if !ok {
+			author = models.JiraAuthor{Name: c.Author, DisplayName: c.Author}
+		}
+		fields.Comment.Comments = append(fields.Comment.Comments, models.JiraComment{
+			ID:      c.ID,
+			Author:  author,
+			Body:    c.Body,
+			Created: xmlTime(c.Created),
+			Updated: xmlTime(updated),
+		})
+	}
+	fields.Comment.Total = len(fields.Comment.Comments)
+	fields.Comment.MaxResults = fields.Comment.Total
+
+	for _, name := range item.Components {
+		fields.Components = append(fields.Components, models.JiraComponent{
+			ID:   syntheticID("component", item.Project.Key, name),
+			Name: name,
+		})
+	}
+	for _, name := range item.FixVersions {
+		fields.FixVersions = append(fields.FixVersions, models.JiraVersion{
+			ID:   syntheticID("version", item.Project.Key, name),
+			Name: name,
+		})
+	}
+
+	for _, linkType := range item.LinkTypes {
+		jiraType := linkTypes[linkType.Name]
+		// Связь приходит в обеих задачах, идентификатор строится
+		// по прямому направлению, чтобы совпадать в обеих
+		for _, target := range linkType.Outward.Keys {
+			fields.IssueLinks = append(fields.IssueLinks, models.JiraLink{
+				ID:           syntheticID("link", item.Key, linkType.Name, target),
+				Type:         jiraType,
+				OutwardIssue: &models.JiraLinkedIssue{Key: target},
+			})
+		}
+		for _, source := range linkType.Inward.Keys {
+			fields.IssueLinks = append(fields.IssueLinks, models.JiraLink{
+				ID:          syntheticID("link", source, linkType.Name, item.Key),
+				Type:        jiraType,
+				InwardIssue: &models.JiraLinkedIssue{Key: source},
+			})
+		}
+	}
+
+	for _, field := range item.Custom {
+		value, err := xmlCustomValue(field.Values)
+		if err != nil {
+			return models.JiraIssue{}, fmt.Errorf("failed to convert custom field %s: %w", field.ID, err)
+		}
+		if value != nil {
+			fields.Custom[field.ID] = value
+		}
+	}
+
+	// Связи XML получают искусственные идентификаторы и не должны удалять
+	// связи, сохранённые из Jira
+	return models.JiraIssue{Key: item.Key, Fields: fields, LinksPartial: true}, nil
+}
+
+// author возвращает пользователя Jira. Неназначенный исполнитель
+// выгружается с идентификатором -1.
+func (u *xmlUser) author() (models.JiraAuthor, bool) {
+	if u == nil {
+		return models.JiraAuthor{}, false
+	}
+	if u.AccountID == "-1" || u.Username == "-1" || u.AccountID+u.Key+u.Username == "" {
+		return models.JiraAuthor{}, false
+	}
+
+	// Идентификаторы раскладываются по тем же полям, что в REST API, и
+	// Identity совпадает с онлайн-синхронизацией. Jira Server 8+ при
+	// онлайн-синхронизации идентифицирует пользователя по key
+	// (JIRAUSER12345); если выгрузка не содержит key, остаётся username,
+	// совпадающий с key только у пользователей, созданных до Jira 8.
+	return models.JiraAuthor{
+		AccountID:   u.AccountID,
+		Key:         u.Key,
+		Name:        u.Username,
+		DisplayName: strings.TrimSpace(u.DisplayName),
+		Active:      true,
+	}, true
+}
+
+// xmlCustomValue сводит значения пользовательского поля к JSON: одно
+// значение - строка, несколько - список строк. Даты приводятся к формату
+// REST API.
+func xmlCustomValue(values []string) (json.RawMessage, error) {
+	converted := make([]string, 0, len(values))
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if t := xmlTime(value); t != "" {
+			value = t
+		}
+		converted = append(converted, value)
+	}
+
+	switch len(converted) {
+	case 0:
+		return nil, nil
+	case 1:
+		return json.Marshal(converted[0])
+	default:
+		return json.Marshal(converted)
+	}
+}
+
+// xmlTimeLayout - дата XML-выгрузки. В отличие от RFC 1123, день месяца
+// пишется без ведущего нуля: "Mon, 4 Mar 2024 10:00:00 +0000".
+const xmlTimeLayout = "Mon, 2 Jan 2006 15:04:05 -0700"
+
+// xmlTime переводит дату XML-выгрузки в формат REST API. Нераспознанная
+// дата возвращается пустой строкой.
+func xmlTime(value string) string {
+	t, err := time.Parse(xmlTimeLayout, strings.TrimSpace(value))
+	if err != nil {
+		return ""
+	}
+	return t.Format(models.JiraTimeLayout)
+}
+
+// syntheticID - идентификатор объекта, у которого в XML-выгрузке нет id Jira.
+func syntheticID(parts ...string) string {
+	sum := sha256.Sum256([]byte(strings.Join(parts, "\x00")))
+	return "xml:" + hex.EncodeToString(sum[:16])
+}
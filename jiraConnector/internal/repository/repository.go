@@ -5,7 +5,7 @@ import (
 	"database/sql"
 	"github.com/jmoiron/sqlx"
 	"jiraAnalyzer/jiraConnector/internal/repository/database"
-	"jiraAnalyzer/jiraConnector/internal/repository/jira"
+	"time"
 
 	"jiraAnalyzer/jiraConnector/internal/models"
 )
@@ -18,27 +18,112 @@ type JiraDB interface {
 	// Задачи
 	SaveIssuesTx(tx *sql.Tx, issues []models.DBIssue) error
 	SaveChangelogTx(tx *sql.Tx, changelogs []models.DBChangelog) error
-	GetOrCreateAuthor(displayName string) (int, error)
+	SaveFieldChangesTx(tx *sql.Tx, changes []models.DBFieldChange) error
+	SaveCommentsTx(tx *sql.Tx, comments []models.DBComment) error
+	DeleteStaleCommentsTx(tx *sql.Tx, issueKeys []string, keepIDs []string) error
+	SaveWorklogsTx(tx *sql.Tx, worklogs []models.DBWorklog) error
+	DeleteStaleWorklogsTx(tx *sql.Tx, issueKeys []string, keepIDs []string) error
+	SaveIssueLinksTx(tx *sql.Tx, links []models.DBIssueLink) error
+	DeleteStaleIssueLinksTx(tx *sql.Tx, issueKeys []string, keepIDs []string) error
+	SaveIssueDimensionsTx(tx *sql.Tx, dimensions []models.DBIssueDimensions) error
+	CheckIssueExists(ctx context.Context, issueKey string) (bool, error)
+	GetIssuesByKeys(ctx context.Context, keys []string) ([]models.DBIssue, error)
+	GetStatusChanges(ctx context.Context, issueKeys []string) ([]models.DBChangelog, error)
+	GetFieldChanges(ctx context.Context, issueKeys []string) ([]models.DBFieldChange, error)
+	DeleteComment(ctx context.Context, jiraID string) error
+
+	// Сверка задач с Jira
+	GetActiveIssueKeys(ctx context.Context, projectKey string) ([]string, error)
+	MarkIssuesDeleted(ctx context.Context, keys []string) error
+	MoveIssue(ctx context.Context, oldKey, newKey, newProjectKey string) (bool, error)
+
+	// Доставки webhook
+	ClaimWebhookDelivery(ctx context.Context, deliveryID, event, issueKey string, claimTimeout time.Duration) (string, error)
+	CompleteWebhookDelivery(ctx context.Context, deliveryID string) error
+	ReleaseWebhookDelivery(ctx context.Context, deliveryID string) error
+	PruneWebhookDeliveries(ctx context.Context, olderThan time.Duration) (int64, error)
+
+	// Авторы
+	GetAuthors(ctx context.Context) ([]models.DBAuthor, error)
+	SaveAuthorsTx(tx *sql.Tx, authors []models.DBAuthor) (map[string]int, error)
+
+	// Спринты
+	GetClosedSprintIDs(ctx context.Context, projectKey string) (map[int]bool, error)
+	SaveSprintTx(tx *sql.Tx, sprint models.DBSprint) error
+	ReplaceSprintIssuesTx(tx *sql.Tx, sprintID int, issueKeys []string) error
+
+	// Состояние синхронизации
+	GetSyncWatermark(ctx context.Context, projectKey string) (*time.Time, error)
+	SaveSyncWatermark(ctx context.Context, projectKey string, syncedAt time.Time) error
+	GetActiveSyncRun(ctx context.Context, projectKey string) (*models.DBSyncRun, error)
+	CreateSyncRun(ctx context.Context, run models.DBSyncRun) (int, error)
+	StartSyncRunPass(ctx context.Context, runID, pass int) error
+	GetSyncCheckpoints(ctx context.Context, runID int) ([]models.DBSyncCheckpoint, error)
+	SaveSyncCheckpointTx(tx *sql.Tx, checkpoint models.DBSyncCheckpoint) error
+	FinishSyncRun(ctx context.Context, runID int) error
+
+	// Фоновые задачи синхронизации
+	CreateSyncJob(ctx context.Context, projectKeys []string, full, dryRun bool) (int, error)
+	GetSyncJob(ctx context.Context, jobID int) (models.DBSyncJob, error)
+	ListSyncJobs(ctx context.Context, limit int) ([]models.DBSyncJob, error)
+	StartSyncJob(ctx context.Context, jobID int) error
+	AddSyncJobBatches(ctx context.Context, jobID int, batches int) error
+	AddSyncJobProgress(ctx context.Context, jobID int, issues int) error
+	AddSyncJobError(ctx context.Context, jobID int, message string) error
+	AddSyncJobReconciliation(ctx context.Context, jobID int, deleted, moved int) error
+	FinishSyncJob(ctx context.Context, jobID int, status string) error
+	SaveSyncJobReport(ctx context.Context, jobID int, report []byte) error
+	InterruptActiveSyncJobs(ctx context.Context, reason string) (int64, error)
+
+	// Задачи, не прошедшие ETL
+	SaveDeadLettersTx(tx *sql.Tx, letters []models.DBDeadLetter) error
+	ResolveDeadLettersTx(tx *sql.Tx, issueKeys []string) error
+	GetDeadLetter(ctx context.Context, id int) (models.DBDeadLetter, error)
+	ListDeadLetters(ctx context.Context, projectKey string, resolved bool, limit int) ([]models.DBDeadLetter, error)
+
+	// История планировщика
+	SaveScheduleRun(ctx context.Context, run models.DBScheduleRun) error
+	ListScheduleRuns(ctx context.Context, scheduleName string, limit int) ([]models.DBScheduleRun, error)
 
 	// Транзакции
 	BeginTx(ctx context.Context) (*sql.Tx, error)
+	SavepointTx(tx *sql.Tx, name string) error
+	RollbackToSavepointTx(tx *sql.Tx, name string) error
+	ReleaseSavepointTx(tx *sql.Tx, name string) error
 }
 
-type JiraClient interface {
-	// Клиент
+// Tracker - источник задач: Jira, GitHub или GitLab. Интерфейс не
+// нейтрален к трекеру: он повторяет API Jira, и модель REST API Jira
+// (JiraIssue, JiraBoard и т.д.) служит форматом обмена. Адаптеры GitHub
+// и GitLab переводят свои ответы в эту модель, а возможности, которых
+// у них нет (списания, доски, спринты), отдают пустыми. Трекер, который
+// не укладывается в модель Jira, потребует нейтральных типов.
+type Tracker interface {
+	// Проекты и задачи
 	GetAllProjects(ctx context.Context) ([]models.JiraProject, error)
-	GetProjectIssues(ctx context.Context, projectKey string, startAt int) ([]models.JiraIssue, error)
-	GetIssueCount(ctx context.Context, projectKey string) (int, error)
+	GetProjectIssues(ctx context.Context, projectKey string, updatedSince *time.Time, startAt int) ([]models.JiraIssue, error)
+	GetIssueCount(ctx context.Context, projectKey string, updatedSince *time.Time) (int, error)
+	GetIssueWorklogs(ctx context.Context, issueKey string) ([]models.JiraWorklog, error)
+	GetIssueRef(ctx context.Context, issueKey string) (*models.JiraIssueRef, error)
+	GetIssue(ctx context.Context, issueKey string) (*models.JiraIssue, error)
+
+	// Agile API, у трекеров без досок списки пусты
+	GetProjectBoards(ctx context.Context, projectKey string) ([]models.JiraBoard, error)
+	GetBoardSprints(ctx context.Context, boardID int) ([]models.JiraSprint, error)
+	GetSprintIssueKeys(ctx context.Context, sprintID int) ([]string, error)
+
+	// Метрики лимитеров запросов к трекерам
+	RateLimiterMetrics() models.RateLimiterMetrics
 }
 
 type Repository struct {
 	JiraDB
-	JiraClient
+	Tracker
 }
 
-func NewRepository(db *sqlx.DB, client *jira.Jira) *Repository {
+func NewRepository(db *sqlx.DB, tracker Tracker) *Repository {
 	return &Repository{
-		JiraDB:     database.NewJiraPostgres(db),
-		JiraClient: client,
+		JiraDB:  database.NewJiraPostgres(db),
+		Tracker: tracker,
 	}
 }
@@ -0,0 +1,206 @@
+package tracker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"jiraAnalyzer/jiraConnector/internal/repository/jira"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+const (
+	defaultMaxAttempts = 3
+	defaultTimeout     = 60 * time.Second
+	// maxRateLimitWait - дольше этого ждать сброса лимита запросов
+	// не имеет смысла, синхронизация завершится ошибкой и будет повторена
+	maxRateLimitWait = 2 * time.Minute
+	// maxPageSize - наибольший размер страницы в API GitHub и GitLab
+	maxPageSize = 100
+)
+
+// apiClient выполняет GET-запросы к REST API GitHub или GitLab с токеном
+// и повторяет временные ошибки. Запросы к одному трекеру проходят через
+// общий лимитер, который следит за квотой в заголовках ответов.
+type apiClient struct {
+	source      string
+	baseURL     string
+	client      *http.Client
+	headers     http.Header
+	maxAttempts int
+	limiter     *jira.RateLimiter
+}
+
+func newAPIClient(source string, cfg APIConfig, authorize func(token string) http.Header) (*apiClient, error) {
+	c := &apiClient{
+		source:      source,
+		baseURL:     cfg.APIURL,
+		client:      &http.Client{Timeout: cfg.Timeout},
+		headers:     http.Header{},
+		maxAttempts: cfg.MaxAttempts,
+		limiter:     jira.NewRateLimiter(cfg.RequestsPerSecond, cfg.Burst),
+	}
+	if c.client.Timeout <= 0 {
+		c.client.Timeout = defaultTimeout
+	}
+	if c.maxAttempts <= 0 {
+		c.maxAttempts = defaultMaxAttempts
+	}
+
+	// Без токена доступны только публичные репозитории
+	if cfg.Token.IsSet() {
+		token, err := cfg.Token.Resolve()
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve %s token: %w", source, err)
+		}
+		c.headers = authorize(token)
+	}
+
+	return c, nil
+}
+
+// get запрашивает path с параметрами и декодирует ответ в response.
+// Возвращает заголовки ответа, в которых API сообщают о страницах.
+func (c *apiClient) get(ctx context.Context, path string, params url.Values, response interface{}) (http.Header, error) {
+	requestURL := c.baseURL + path
+	if len(params) > 0 {
+		requestURL += "?" + params.Encode()
+	}
+
+	for attempt := 1; ; attempt++ {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, requestURL, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create request: %w", err)
+		}
+		for name, values := range c.headers {
+			req.Header[name] = values
+		}
+
+		if err := c.limiter.Wait(ctx); err != nil {
+			return nil, err
+		}
+		resp, err := c.client.Do(req)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			if attempt >= c.maxAttempts {
+				return nil, &jira.RequestError{Source: c.source, Kind: jira.ErrorRetryable, URL: requestURL, Err: err}
+			}
+			if err := sleep(ctx, backoff(attempt)); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		c.limiter.Observe(resp)
+
+		if resp.StatusCode == http.StatusOK {
+			err := json.NewDecoder(resp.Body).Decode(response)
+			resp.Body.Close()
+			if err != nil {
+				return nil, &jira.RequestError{Source: c.source, Kind: jira.ErrorMalformed, StatusCode: resp.StatusCode, URL: requestURL,
+					Err: fmt.Errorf("failed to decode response: %w", err)}
+			}
+			return resp.Header, nil
+		}
+
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		resp.Body.Close()
+
+		kind := jira.ClassifyStatus(resp.StatusCode)
+		wait, limited := rateLimitWait(resp)
+		if limited {
+			// GitHub отвечает 403, когда исчерпан лимит запросов
+			kind = jira.ErrorRetryable
+		}
+		reqErr := &jira.RequestError{Source: c.source, Kind: kind, StatusCode: resp.StatusCode, URL: requestURL, Body: string(body)}
+
+		if kind != jira.ErrorRetryable || attempt >= c.maxAttempts || wait > maxRateLimitWait {
+			return nil, reqErr
+		}
+
+		if !limited {
+			wait = backoff(attempt)
+		}
+		log.Printf("%s request failed with status %d, retrying in %s", c.source, resp.StatusCode, wait)
+		if err := sleep(ctx, wait); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// rateLimitWait возвращает время до сброса лимита запросов, если ответ
+// означает, что лимит исчерпан. GitHub и GitLab сообщают время сброса
+// в Retry-After или в заголовках X-RateLimit-* / RateLimit-*.
+func rateLimitWait(resp *http.Response) (time.Duration, bool) {
+	if resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode != http.StatusForbidden {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	for _, prefix := range []string{"X-RateLimit-", "RateLimit-"} {
+		if resp.Header.Get(prefix+"Remaining") != "0" {
+			continue
+		}
+		reset, err := strconv.ParseInt(resp.Header.Get(prefix+"Reset"), 10, 64)
+		if err != nil {
+			return 0, true
+		}
+		return max(time.Until(time.Unix(reset, 0)), 0), true
+	}
+
+	return 0, resp.StatusCode == http.StatusTooManyRequests
+}
+
+func backoff(attempt int) time.Duration {
+	return time.Second << (attempt - 1)
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// pageSize подбирает размер страницы API для батча ETL: батчи начинаются
+// с кратных batchSize позиций, поэтому размер страницы должен делить
+// batchSize и не превышать ограничение API.
+func pageSize(batchSize int) int {
+	for size := min(batchSize, maxPageSize); size > 1; size-- {
+		if batchSize%size == 0 {
+			return size
+		}
+	}
+	return 1
+}
+
+// eachPage запрашивает страницы списка через fetch, пока страница не
+// окажется неполной. fetch возвращает число элементов страницы.
+func eachPage(fetch func(params url.Values) (int, error)) error {
+	for page := 1; ; page++ {
+		params := url.Values{}
+		params.Set("per_page", strconv.Itoa(maxPageSize))
+		params.Set("page", strconv.Itoa(page))
+
+		count, err := fetch(params)
+		if err != nil {
+			return err
+		}
+		if count < maxPageSize {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package tracker
+
+import (
+	"fmt"
+	"jiraAnalyzer/jiraConnector/internal/repository/jira"
+	"time"
+)
+
+// Источники задач проекта
+const (
+	SourceJira   = "jira"
+	SourceGitHub = "github"
+	SourceGitLab = "gitlab"
+)
+
+// Config задаёт трекеры, отличные от Jira, и проекты, задачи которых
+// берутся из них. Проекты, не указанные в Projects, загружаются из Jira.
+type Config struct {
+	GitHub   APIConfig                `yaml:"github"`
+	GitLab   APIConfig                `yaml:"gitlab"`
+	Projects map[string]ProjectConfig `yaml:"projects"`
+}
+
+type APIConfig struct {
+	// APIURL - адрес REST API, например https://api.github.com
+	// или https://gitlab.com/api/v4
+	APIURL      string        `yaml:"apiUrl"`
+	Token       jira.Secret   `yaml:"token"`
+	MaxAttempts int           `yaml:"maxAttempts"`
+	Timeout     time.Duration `yaml:"timeout"`
+	// RequestsPerSecond и Burst - лимитер запросов к трекеру, скорость
+	// снижается по заголовкам квоты в ответах
+	RequestsPerSecond float64 `yaml:"requestsPerSecond"`
+	Burst             int     `yaml:"burst"`
+}
+
+// ProjectConfig - источник задач проекта.
+type ProjectConfig struct {
+	Source string `yaml:"source"`
+	// Repository - owner/repo в GitHub или путь проекта (group/project) в GitLab
+	Repository string `yaml:"repository"`
+	// Name - название проекта, по умолчанию берётся из трекера
+	Name string `yaml:"name"`
+}
+
+func (c Config) Validate() error {
+	for key, project := range c.Projects {
+		if key == "" {
+			return fmt.Errorf("project key must not be empty")
+		}
+
+		switch project.Source {
+		case SourceJira:
+		case SourceGitHub, SourceGitLab:
+			if project.Repository == "" {
+				return fmt.Errorf("project %s: repository is required for %s", key, project.Source)
+			}
+		default:
+			return fmt.Errorf("project %s: unknown source %q", key, project.Source)
+		}
+	}
+
+	for source, api := range map[string]APIConfig{SourceGitHub: c.GitHub, SourceGitLab: c.GitLab} {
+		if c.uses(source) && api.APIURL == "" {
+			return fmt.Errorf("%s: apiUrl is required", source)
+		}
+	}
+	return nil
+}
+
+func (c Config) uses(source string) bool {
+	for _, project := range c.Projects {
+		if project.Source == source {
+			return true
+		}
+	}
+	return false
+}
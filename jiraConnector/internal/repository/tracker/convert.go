@@ -0,0 +1,117 @@
+package tracker
+
+import (
+	"fmt"
+	"jiraAnalyzer/jiraConnector/internal/models"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Статусы задач GitHub и GitLab в терминах Jira, по которым считает аналитика
+const (
+	statusOpen   = "Open"
+	statusClosed = "Closed"
+)
+
+// issueKey - ключ задачи в стиле Jira: ключ проекта и номер задачи в трекере.
+func issueKey(projectKey string, number int) string {
+	return fmt.Sprintf("%s-%d", projectKey, number)
+}
+
+// parseIssueKey разбирает ключ задачи на ключ проекта и номер.
+func parseIssueKey(key string) (string, int, bool) {
+	i := strings.LastIndex(key, "-")
+	if i <= 0 {
+		return "", 0, false
+	}
+	number, err := strconv.Atoi(key[i+1:])
+	if err != nil {
+		return "", 0, false
+	}
+	return key[:i], number, true
+}
+
+// author - пользователь трекера. Идентификатор содержит имя трекера, чтобы
+// не совпадать с accountId пользователей Jira.
+func author(source string, id int64, login, name string) models.JiraAuthor {
+	if name == "" {
+		name = login
+	}
+	return models.JiraAuthor{
+		AccountID:   fmt.Sprintf("%s:%d", source, id),
+		Name:        login,
+		DisplayName: name,
+		Active:      true,
+	}
+}
+
+func formatTime(t time.Time) string {
+	return t.Format(models.JiraTimeLayout)
+}
+
+func state(closed bool) string {
+	if closed {
+		return statusClosed
+	}
+	return statusOpen
+}
+
+// statusItem - переход статуса в истории задачи.
+func statusItem(closed bool) models.JiraHistoryItem {
+	return models.JiraHistoryItem{
+		Field:      "status",
+		FieldType:  "jira",
+		FromString: state(!closed),
+		ToString:   state(closed),
+	}
+}
+
+// labelItem - добавление или снятие метки. Jira пишет в историю полный
+// список меток до и после, трекеры сообщают об одной метке.
+func labelItem(label string, added bool) models.JiraHistoryItem {
+	item := models.JiraHistoryItem{Field: "labels", FieldType: "jira"}
+	if added {
+		item.ToString = label
+	} else {
+		item.FromString = label
+	}
+	return item
+}
+
+// history - запись истории из одного события трекера.
+func history(source string, id int64, created time.Time, by models.JiraAuthor, item models.JiraHistoryItem) models.JiraHistory {
+	return models.JiraHistory{
+		ID:      fmt.Sprintf("%s:%d", source, id),
+		Created: formatTime(created),
+		Author:  by,
+		Items:   []models.JiraHistoryItem{item},
+	}
+}
+
+// sortHistories упорядочивает историю по времени, как её отдаёт Jira.
+func sortHistories(histories []models.JiraHistory) {
+	sort.SliceStable(histories, func(i, j int) bool {
+		a, _ := time.Parse(models.JiraTimeLayout, histories[i].Created)
+		b, _ := time.Parse(models.JiraTimeLayout, histories[j].Created)
+		return a.Before(b)
+	})
+}
+
+// completeComments - страница комментариев, содержащая все комментарии задачи.
+func completeComments(comments []models.JiraComment) models.JiraComments {
+	return models.JiraComments{MaxResults: len(comments), Total: len(comments), Comments: comments}
+}
+
+// assigneeItem - назначение или снятие исполнителя.
+func assigneeItem(assignee models.JiraAuthor, assigned bool) models.JiraHistoryItem {
+	item := models.JiraHistoryItem{Field: "assignee", FieldType: "jira"}
+	id := assignee.AccountID
+	if assigned {
+		item.To, item.ToString = &id, assignee.DisplayName
+	} else {
+		item.From, item.FromString = &id, assignee.DisplayName
+	}
+	return item
+}
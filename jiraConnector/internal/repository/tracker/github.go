@@ -0,0 +1,288 @@
+package tracker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"jiraAnalyzer/jiraConnector/internal/models"
+	"jiraAnalyzer/jiraConnector/internal/repository/jira"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// lastPageRe извлекает номер последней страницы из заголовка Link GitHub.
+var lastPageRe = regexp.MustCompile(`[?&]page=(\d+)[^>]*>;\s*rel="last"`)
+
+// githubProject - проект, задачи которого хранятся в репозитории GitHub.
+// Pull request'ы в списке задач репозитория пропускаются.
+type githubProject struct {
+	plainTracker
+	api      *apiClient
+	key      string
+	cfg      ProjectConfig
+	pageSize int
+	// projectByRepo - ключ проекта по репозиторию, для перенесённых задач
+	projectByRepo func(repository string) string
+}
+
+func newGitHubAPI(cfg APIConfig) (*apiClient, error) {
+	return newAPIClient(SourceGitHub, cfg, func(token string) http.Header {
+		return http.Header{
+			"Authorization":        {"Bearer " + token},
+			"Accept":               {"application/vnd.github+json"},
+			"X-Github-Api-Version": {"2022-11-28"},
+		}
+	})
+}
+
+func (p *githubProject) repoPath() string {
+	return "/repos/" + p.cfg.Repository
+}
+
+func (p *githubProject) project(ctx context.Context) (models.JiraProject, error) {
+	var repo models.GitHubRepository
+	if _, err := p.api.get(ctx, p.repoPath(), nil, &repo); err != nil {
+		return models.JiraProject{}, fmt.Errorf("failed to fetch repository %s: %w", p.cfg.Repository, err)
+	}
+
+	name := p.cfg.Name
+	if name == "" {
+		name = repo.FullName
+	}
+	return models.JiraProject{Key: p.key, Name: name, URL: repo.HTMLURL}, nil
+}
+
+// GetAllProjects возвращает единственный проект источника.
+func (p *githubProject) GetAllProjects(ctx context.Context) ([]models.JiraProject, error) {
+	project, err := p.project(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return []models.JiraProject{project}, nil
+}
+
+func (p *githubProject) listParams(updatedSince *time.Time, page, perPage int) url.Values {
+	params := url.Values{}
+	params.Set("state", "all")
+	params.Set("sort", "created")
+	params.Set("direction", "asc")
+	params.Set("per_page", strconv.Itoa(perPage))
+	params.Set("page", strconv.Itoa(page))
+	if updatedSince != nil {
+		params.Set("since", updatedSince.UTC().Format(time.RFC3339))
+	}
+	return params
+}
+
+// GetIssueCount возвращает число элементов списка задач, включая pull
+// request'ы: ETL делит список на батчи по этому числу.
+func (p *githubProject) GetIssueCount(ctx context.Context, projectKey string, updatedSince *time.Time) (int, error) {
+	perPage := pageSize(p.pageSize)
+
+	var issues []models.GitHubIssue
+	header, err := p.api.get(ctx, p.repoPath()+"/issues", p.listParams(updatedSince, 1, perPage), &issues)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count issues of %s: %w", p.cfg.Repository, err)
+	}
+
+	match := lastPageRe.FindStringSubmatch(header.Get("Link"))
+	if match == nil {
+		return len(issues), nil
+	}
+	lastPage, _ := strconv.Atoi(match[1])
+	return lastPage * perPage, nil
+}
+
+func (p *githubProject) GetProjectIssues(ctx context.Context, projectKey string, updatedSince *time.Time, startAt int) ([]models.JiraIssue, error) {
+	perPage := pageSize(p.pageSize)
+
+	var result []models.JiraIssue
+	for page := startAt/perPage + 1; page <= (startAt+p.pageSize)/perPage; page++ {
+		var issues []models.GitHubIssue
+		if _, err := p.api.get(ctx, p.repoPath()+"/issues", p.listParams(updatedSince, page, perPage), &issues); err != nil {
+			return nil, fmt.Errorf("failed to fetch issues of %s: %w", p.cfg.Repository, err)
+		}
+
+		for _, issue := range issues {
+			if issue.PullRequest != nil {
+				continue
+			}
+			converted, err := p.convert(ctx, issue)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, converted)
+		}
+
+		if len(issues) < perPage {
+			break
+		}
+	}
+	return result, nil
+}
+
+func (p *githubProject) convert(ctx context.Context, issue models.GitHubIssue) (models.JiraIssue, error) {
+	key := issueKey(p.key, issue.Number)
+
+	issueType := "Issue"
+	if issue.Type != nil && issue.Type.Name != "" {
+		issueType = issue.Type.Name
+	}
+
+	fields := models.JiraFields{
+		Project:     models.JiraProject{Key: p.key},
+		Created:     formatTime(issue.CreatedAt),
+		Updated:     formatTime(issue.UpdatedAt),
+		Summary:     issue.Title,
+		Description: issue.Body,
+		IssueType:   models.JiraType{Name: issueType},
+		Status:      models.JiraStatus{Name: state(issue.State == "closed")},
+		Creator:     githubAuthor(issue.User),
+	}
+	if issue.Assignee != nil {
+		assignee := githubAuthor(*issue.Assignee)
+		fields.Assignee = &assignee
+	}
+	for _, label := range issue.Labels {
+		fields.Labels = append(fields.Labels, label.Name)
+	}
+
+	comments, histories, err := p.timeline(ctx, issue.Number)
+	if err != nil {
+		return models.JiraIssue{}, err
+	}
+	fields.Comment = completeComments(comments)
+
+	return models.JiraIssue{
+		Key:       key,
+		Fields:    fields,
+		Changelog: models.JiraChangelog{Histories: histories},
+		SourceRaw: issue.Raw,
+	}, nil
+}
+
+// timeline загружает комментарии и историю изменений задачи одним списком
+// событий. Закрытие и переоткрытие - переходы статуса, метки
+// и исполнители - изменения полей.
+func (p *githubProject) timeline(ctx context.Context, number int) ([]models.JiraComment, []models.JiraHistory, error) {
+	var comments []models.JiraComment
+	var histories []models.JiraHistory
+	path := fmt.Sprintf("%s/issues/%d/timeline", p.repoPath(), number)
+	err := eachPage(func(params url.Values) (int, error) {
+		var page []models.GitHubEvent
+		if _, err := p.api.get(ctx, path, params, &page); err != nil {
+			return 0, fmt.Errorf("failed to fetch timeline of %s#%d: %w", p.cfg.Repository, number, err)
+		}
+
+		for _, event := range page {
+			if event.Event == "commented" {
+				user := models.GitHubUser{Login: "ghost"}
+				if event.User != nil {
+					user = *event.User
+				}
+				comments = append(comments, models.JiraComment{
+					ID:      strconv.FormatInt(event.ID, 10),
+					Author:  githubAuthor(user),
+					Body:    event.Body,
+					Created: formatTime(event.CreatedAt),
+					Updated: formatTime(event.UpdatedAt),
+				})
+				continue
+			}
+
+			// Событие удалённого пользователя GitHub приписывает пользователю ghost
+			by := githubAuthor(models.GitHubUser{Login: "ghost"})
+			if event.Actor != nil {
+				by = githubAuthor(*event.Actor)
+			}
+
+			var item models.JiraHistoryItem
+			switch {
+			case event.Event == "closed" || event.Event == "reopened":
+				item = statusItem(event.Event == "closed")
+			case (event.Event == "labeled" || event.Event == "unlabeled") && event.Label != nil:
+				item = labelItem(event.Label.Name, event.Event == "labeled")
+			case (event.Event == "assigned" || event.Event == "unassigned") && event.Assignee != nil:
+				item = assigneeItem(githubAuthor(*event.Assignee), event.Event == "assigned")
+			default:
+				continue
+			}
+			histories = append(histories, history(SourceGitHub, event.ID, event.CreatedAt, by, item))
+		}
+		return len(page), nil
+	})
+
+	sortHistories(histories)
+	return comments, histories, err
+}
+
+// GetIssueRef возвращает текущий ключ задачи. Удалённая задача отвечает 404
+// или 410, перенесённая - перенаправлением на задачу в другом репозитории.
+func (p *githubProject) GetIssueRef(ctx context.Context, key string) (*models.JiraIssueRef, error) {
+	_, number, ok := parseIssueKey(key)
+	if !ok {
+		return nil, fmt.Errorf("invalid issue key %s", key)
+	}
+
+	var issue models.GitHubIssue
+	_, err := p.api.get(ctx, fmt.Sprintf("%s/issues/%d", p.repoPath(), number), nil, &issue)
+	var reqErr *jira.RequestError
+	if errors.As(err, &reqErr) && (reqErr.StatusCode == http.StatusNotFound || reqErr.StatusCode == http.StatusGone) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to fetch issue %s#%d: %w", p.cfg.Repository, number, err)
+	}
+
+	ref := &models.JiraIssueRef{Key: issueKey(p.key, issue.Number)}
+	ref.Fields.Project.Key = p.key
+
+	repository := strings.TrimPrefix(issue.RepositoryURL, p.api.baseURL+"/repos/")
+	if issue.RepositoryURL != "" && !strings.EqualFold(repository, p.cfg.Repository) {
+		// Задача перенесена в репозиторий другого проекта или в репозиторий,
+		// который не синхронизируется
+		ref.Key = fmt.Sprintf("%s#%d", repository, issue.Number)
+		ref.Fields.Project.Key = ""
+		if projectKey := p.projectByRepo(repository); projectKey != "" {
+			ref.Key = issueKey(projectKey, issue.Number)
+			ref.Fields.Project.Key = projectKey
+		}
+	}
+	return ref, nil
+}
+
+// GetIssue загружает задачу репозитория или возвращает nil, если задача
+// удалена, перенесена в другой репозиторий или оказалась pull request'ом.
+func (p *githubProject) GetIssue(ctx context.Context, key string) (*models.JiraIssue, error) {
+	_, number, ok := parseIssueKey(key)
+	if !ok {
+		return nil, fmt.Errorf("invalid issue key %s", key)
+	}
+
+	var issue models.GitHubIssue
+	_, err := p.api.get(ctx, fmt.Sprintf("%s/issues/%d", p.repoPath(), number), nil, &issue)
+	var reqErr *jira.RequestError
+	if errors.As(err, &reqErr) && (reqErr.StatusCode == http.StatusNotFound || reqErr.StatusCode == http.StatusGone) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to fetch issue %s#%d: %w", p.cfg.Repository, number, err)
+	}
+
+	repository := strings.TrimPrefix(issue.RepositoryURL, p.api.baseURL+"/repos/")
+	if issue.PullRequest != nil || (issue.RepositoryURL != "" && !strings.EqualFold(repository, p.cfg.Repository)) {
+		return nil, nil
+	}
+
+	converted, err := p.convert(ctx, issue)
+	if err != nil {
+		return nil, err
+	}
+	return &converted, nil
+}
+
+func githubAuthor(user models.GitHubUser) models.JiraAuthor {
+	return author(SourceGitHub, user.ID, user.Login, "")
+}
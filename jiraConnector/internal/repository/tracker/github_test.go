@@ -0,0 +1,65 @@
+package tracker
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestGitHubIssueFromTimeline(t *testing.T) {
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.URL.Path)
+		switch r.URL.Path {
+		case "/repos/example-org/web/issues/1":
+			w.Write([]byte(`{"number": 1, "title": "Broken build", "state": "closed", "comments": 1,
+				"user": {"id": 10, "login": "alice"},
+				"created_at": "2024-03-01T10:00:00Z", "updated_at": "2024-03-02T10:00:00Z"}`))
+		case "/repos/example-org/web/issues/1/timeline":
+			w.Write([]byte(`[
+				{"id": 501, "event": "labeled", "actor": {"id": 10, "login": "alice"},
+					"created_at": "2024-03-01T10:05:00Z", "label": {"name": "bug"}},
+				{"id": 900, "event": "commented", "actor": {"id": 11, "login": "bob"}, "user": {"id": 11, "login": "bob"},
+					"body": "Fixed in main", "created_at": "2024-03-02T09:00:00Z", "updated_at": "2024-03-02T09:30:00Z"},
+				{"event": "cross-referenced", "created_at": "2024-03-02T09:10:00Z"},
+				{"id": 502, "event": "closed", "actor": {"id": 11, "login": "bob"}, "created_at": "2024-03-02T10:00:00Z"}
+			]`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	api, err := newGitHubAPI(APIConfig{APIURL: server.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	project := &githubProject{api: api, key: "WEB", cfg: ProjectConfig{Source: SourceGitHub, Repository: "example-org/web"}}
+
+	issue, err := project.GetIssue(context.Background(), "WEB-1")
+	if err != nil || issue == nil {
+		t.Fatalf("GetIssue = %v, %v", issue, err)
+	}
+
+	// Для разбора dead letters сохраняется задача в формате GitHub
+	if !strings.Contains(string(issue.SourceRaw), `"title": "Broken build"`) {
+		t.Errorf("source payload = %s, want GitHub issue", issue.SourceRaw)
+	}
+	// Комментарии и история приходят одним запросом хронологии
+	if len(requests) != 2 {
+		t.Errorf("requests = %v, want issue and timeline", requests)
+	}
+	comments := issue.Fields.Comment.Comments
+	if len(comments) != 1 || comments[0].ID != "900" || comments[0].Body != "Fixed in main" || comments[0].Author.Name != "bob" {
+		t.Errorf("comments = %+v, want comment 900 by bob", comments)
+	}
+	histories := issue.Changelog.Histories
+	if len(histories) != 2 || histories[0].ID != "github:501" || histories[1].ID != "github:502" {
+		t.Fatalf("histories = %+v, want labeled and closed events", histories)
+	}
+	if item := histories[1].Items[0]; item.Field != "status" {
+		t.Errorf("closed event item = %+v, want status change", item)
+	}
+}
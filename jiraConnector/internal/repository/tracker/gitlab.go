@@ -0,0 +1,294 @@
+package tracker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"jiraAnalyzer/jiraConnector/internal/models"
+	"jiraAnalyzer/jiraConnector/internal/repository/jira"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// gitlabProject - проект, задачи которого хранятся в проекте GitLab.
+type gitlabProject struct {
+	plainTracker
+	api      *apiClient
+	key      string
+	cfg      ProjectConfig
+	pageSize int
+}
+
+func newGitLabAPI(cfg APIConfig) (*apiClient, error) {
+	return newAPIClient(SourceGitLab, cfg, func(token string) http.Header {
+		return http.Header{"Private-Token": {token}}
+	})
+}
+
+func (p *gitlabProject) projectPath() string {
+	return "/projects/" + url.PathEscape(p.cfg.Repository)
+}
+
+func (p *gitlabProject) project(ctx context.Context) (models.JiraProject, error) {
+	var project models.GitLabProject
+	if _, err := p.api.get(ctx, p.projectPath(), nil, &project); err != nil {
+		return models.JiraProject{}, fmt.Errorf("failed to fetch project %s: %w", p.cfg.Repository, err)
+	}
+
+	name := p.cfg.Name
+	if name == "" {
+		name = project.PathWithNamespace
+	}
+	return models.JiraProject{Key: p.key, Name: name, URL: project.WebURL}, nil
+}
+
+// GetAllProjects возвращает единственный проект источника.
+func (p *gitlabProject) GetAllProjects(ctx context.Context) ([]models.JiraProject, error) {
+	project, err := p.project(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return []models.JiraProject{project}, nil
+}
+
+func (p *gitlabProject) listParams(updatedSince *time.Time, page, perPage int) url.Values {
+	params := url.Values{}
+	params.Set("scope", "all")
+	params.Set("order_by", "created_at")
+	params.Set("sort", "asc")
+	params.Set("per_page", strconv.Itoa(perPage))
+	params.Set("page", strconv.Itoa(page))
+	if updatedSince != nil {
+		params.Set("updated_after", updatedSince.UTC().Format(time.RFC3339))
+	}
+	return params
+}
+
+// GetIssueCount берёт число задач из X-Total. Для больших списков GitLab
+// его не отдаёт, тогда число оценивается по X-Total-Pages.
+func (p *gitlabProject) GetIssueCount(ctx context.Context, projectKey string, updatedSince *time.Time) (int, error) {
+	perPage := pageSize(p.pageSize)
+
+	var issues []models.GitLabIssue
+	header, err := p.api.get(ctx, p.projectPath()+"/issues", p.listParams(updatedSince, 1, perPage), &issues)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count issues of %s: %w", p.cfg.Repository, err)
+	}
+
+	if total, err := strconv.Atoi(header.Get("X-Total")); err == nil {
+		return total, nil
+	}
+	if pages, err := strconv.Atoi(header.Get("X-Total-Pages")); err == nil {
+		return pages * perPage, nil
+	}
+	return len(issues), nil
+}
+
+func (p *gitlabProject) GetProjectIssues(ctx context.Context, projectKey string, updatedSince *time.Time, startAt int) ([]models.JiraIssue, error) {
+	perPage := pageSize(p.pageSize)
+
+	var result []models.JiraIssue
+	for page := startAt/perPage + 1; page <= (startAt+p.pageSize)/perPage; page++ {
+		var issues []models.GitLabIssue
+		if _, err := p.api.get(ctx, p.projectPath()+"/issues", p.listParams(updatedSince, page, perPage), &issues); err != nil {
+			return nil, fmt.Errorf("failed to fetch issues of %s: %w", p.cfg.Repository, err)
+		}
+
+		for _, issue := range issues {
+			converted, err := p.convert(ctx, issue)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, converted)
+		}
+
+		if len(issues) < perPage {
+			break
+		}
+	}
+	return result, nil
+}
+
+func (p *gitlabProject) convert(ctx context.Context, issue models.GitLabIssue) (models.JiraIssue, error) {
+	key := issueKey(p.key, issue.IID)
+
+	issueType := "Issue"
+	if issue.IssueType != "" {
+		issueType = strings.ToUpper(issue.IssueType[:1]) + strings.ReplaceAll(issue.IssueType[1:], "_", " ")
+	}
+
+	fields := models.JiraFields{
+		Project:     models.JiraProject{Key: p.key},
+		Created:     formatTime(issue.CreatedAt),
+		Updated:     formatTime(issue.UpdatedAt),
+		Summary:     issue.Title,
+		Description: issue.Description,
+		IssueType:   models.JiraType{Name: issueType},
+		Status:      models.JiraStatus{Name: state(issue.State == "closed")},
+		TimeSpent:   issue.TimeStats.TotalTimeSpent,
+		Creator:     gitlabAuthor(issue.Author),
+		Labels:      issue.Labels,
+	}
+	if len(issue.Assignees) > 0 {
+		assignee := gitlabAuthor(issue.Assignees[0])
+		fields.Assignee = &assignee
+	}
+
+	var comments []models.JiraComment
+	if issue.UserNotesCount > 0 {
+		var err error
+		if comments, err = p.comments(ctx, issue.IID); err != nil {
+			return models.JiraIssue{}, err
+		}
+	}
+	fields.Comment = completeComments(comments)
+
+	histories, err := p.histories(ctx, issue.IID)
+	if err != nil {
+		return models.JiraIssue{}, err
+	}
+
+	return models.JiraIssue{
+		Key:       key,
+		Fields:    fields,
+		Changelog: models.JiraChangelog{Histories: histories},
+		SourceRaw: issue.Raw,
+	}, nil
+}
+
+// comments возвращает комментарии пользователей без системных заметок.
+func (p *gitlabProject) comments(ctx context.Context, iid int) ([]models.JiraComment, error) {
+	var comments []models.JiraComment
+	path := fmt.Sprintf("%s/issues/%d/notes", p.projectPath(), iid)
+	err := eachPage(func(params url.Values) (int, error) {
+		params.Set("sort", "asc")
+		var page []models.GitLabNote
+		if _, err := p.api.get(ctx, path, params, &page); err != nil {
+			return 0, fmt.Errorf("failed to fetch notes of %s#%d: %w", p.cfg.Repository, iid, err)
+		}
+		for _, note := range page {
+			if note.System {
+				continue
+			}
+			comments = append(comments, models.JiraComment{
+				ID:      strconv.FormatInt(note.ID, 10),
+				Author:  gitlabAuthor(note.Author),
+				Body:    note.Body,
+				Created: formatTime(note.CreatedAt),
+				Updated: formatTime(note.UpdatedAt),
+			})
+		}
+		return len(page), nil
+	})
+	return comments, err
+}
+
+// histories собирает историю из событий состояния и меток задачи.
+// Идентификаторы событий разных типов пересекаются, поэтому в id
+// записи истории входит тип события.
+func (p *gitlabProject) histories(ctx context.Context, iid int) ([]models.JiraHistory, error) {
+	var histories []models.JiraHistory
+
+	statePath := fmt.Sprintf("%s/issues/%d/resource_state_events", p.projectPath(), iid)
+	err := eachPage(func(params url.Values) (int, error) {
+		var page []models.GitLabStateEvent
+		if _, err := p.api.get(ctx, statePath, params, &page); err != nil {
+			return 0, fmt.Errorf("failed to fetch state events of %s#%d: %w", p.cfg.Repository, iid, err)
+		}
+		for _, event := range page {
+			if event.State != "closed" && event.State != "reopened" {
+				continue
+			}
+			histories = append(histories, history(SourceGitLab+":state", event.ID, event.CreatedAt, gitlabEventAuthor(event.User), statusItem(event.State == "closed")))
+		}
+		return len(page), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	labelPath := fmt.Sprintf("%s/issues/%d/resource_label_events", p.projectPath(), iid)
+	err = eachPage(func(params url.Values) (int, error) {
+		var page []models.GitLabLabelEvent
+		if _, err := p.api.get(ctx, labelPath, params, &page); err != nil {
+			return 0, fmt.Errorf("failed to fetch label events of %s#%d: %w", p.cfg.Repository, iid, err)
+		}
+		for _, event := range page {
+			// Метка могла быть удалена из проекта
+			if event.Label == nil {
+				continue
+			}
+			histories = append(histories, history(SourceGitLab+":label", event.ID, event.CreatedAt, gitlabEventAuthor(event.User), labelItem(event.Label.Name, event.Action == "add")))
+		}
+		return len(page), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sortHistories(histories)
+	return histories, nil
+}
+
+// GetIssueRef возвращает ключ задачи или nil, если задача удалена. Перенесённая
+// задача GitLab остаётся в исходном проекте закрытой, поэтому её ключ
+// не меняется.
+func (p *gitlabProject) GetIssueRef(ctx context.Context, key string) (*models.JiraIssueRef, error) {
+	_, iid, ok := parseIssueKey(key)
+	if !ok {
+		return nil, fmt.Errorf("invalid issue key %s", key)
+	}
+
+	var issue models.GitLabIssue
+	_, err := p.api.get(ctx, fmt.Sprintf("%s/issues/%d", p.projectPath(), iid), nil, &issue)
+	var reqErr *jira.RequestError
+	if errors.As(err, &reqErr) && reqErr.StatusCode == http.StatusNotFound {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to fetch issue %s#%d: %w", p.cfg.Repository, iid, err)
+	}
+
+	ref := &models.JiraIssueRef{Key: issueKey(p.key, issue.IID)}
+	ref.Fields.Project.Key = p.key
+	return ref, nil
+}
+
+// GetIssue загружает задачу проекта или возвращает nil, если задача удалена.
+func (p *gitlabProject) GetIssue(ctx context.Context, key string) (*models.JiraIssue, error) {
+	_, iid, ok := parseIssueKey(key)
+	if !ok {
+		return nil, fmt.Errorf("invalid issue key %s", key)
+	}
+
+	var issue models.GitLabIssue
+	_, err := p.api.get(ctx, fmt.Sprintf("%s/issues/%d", p.projectPath(), iid), nil, &issue)
+	var reqErr *jira.RequestError
+	if errors.As(err, &reqErr) && reqErr.StatusCode == http.StatusNotFound {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to fetch issue %s#%d: %w", p.cfg.Repository, iid, err)
+	}
+
+	converted, err := p.convert(ctx, issue)
+	if err != nil {
+		return nil, err
+	}
+	return &converted, nil
+}
+
+func gitlabAuthor(user models.GitLabUser) models.JiraAuthor {
+	a := author(SourceGitLab, user.ID, user.Username, user.Name)
+	a.Active = user.State != "blocked" && user.State != "deactivated"
+	return a
+}
+
+// gitlabEventAuthor - автор события, для удалённого пользователя - ghost.
+func gitlabEventAuthor(user *models.GitLabUser) models.JiraAuthor {
+	if user == nil {
+		return gitlabAuthor(models.GitLabUser{Username: "ghost"})
+	}
+	return gitlabAuthor(*user)
+}
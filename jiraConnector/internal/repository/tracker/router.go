@@ -0,0 +1,186 @@
+package tracker
+
+import (
+	"context"
+	"fmt"
+	"jiraAnalyzer/jiraConnector/internal/models"
+	"jiraAnalyzer/jiraConnector/internal/repository"
+	"sort"
+	"strings"
+	"time"
+)
+
+// plainTracker - общие методы трекеров без отдельных списаний времени
+// и Agile API: задачи GitHub и GitLab не содержат обрезанных списков,
+// досок и спринтов в них нет.
+type plainTracker struct{}
+
+func (plainTracker) GetIssueWorklogs(ctx context.Context, issueKey string) ([]models.JiraWorklog, error) {
+	return nil, nil
+}
+
+func (plainTracker) GetProjectBoards(ctx context.Context, projectKey string) ([]models.JiraBoard, error) {
+	return nil, nil
+}
+
+func (plainTracker) GetBoardSprints(ctx context.Context, boardID int) ([]models.JiraSprint, error) {
+	return nil, nil
+}
+
+func (plainTracker) GetSprintIssueKeys(ctx context.Context, sprintID int) ([]string, error) {
+	return nil, nil
+}
+
+func (plainTracker) RateLimiterMetrics() models.RateLimiterMetrics {
+	return models.RateLimiterMetrics{}
+}
+
+// Router направляет запросы по проекту в его трекер: GitHub, GitLab
+// из конфигурации или трекер по умолчанию (Jira) для остальных проектов.
+// Доски есть только у трекера по умолчанию, поэтому запросы спринтов
+// по доске уходят в него.
+type Router struct {
+	fallback repository.Tracker
+	sources  map[string]repository.Tracker
+	// apis - клиенты GitHub и GitLab по источнику, у каждого свой лимитер
+	apis map[string]*apiClient
+}
+
+// NewRouter создаёт источники проектов из конфигурации. fallback - трекер
+// проектов, не указанных в конфигурации, batchSize - число задач, которое
+// ETL запрашивает за раз.
+func NewRouter(fallback repository.Tracker, cfg Config, batchSize int) (*Router, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	r := &Router{
+		fallback: fallback,
+		sources:  make(map[string]repository.Tracker),
+		apis:     make(map[string]*apiClient),
+	}
+
+	var githubAPI, gitlabAPI *apiClient
+	var err error
+	if cfg.uses(SourceGitHub) {
+		if githubAPI, err = newGitHubAPI(cfg.GitHub); err != nil {
+			return nil, err
+		}
+		r.apis[SourceGitHub] = githubAPI
+	}
+	if cfg.uses(SourceGitLab) {
+		if gitlabAPI, err = newGitLabAPI(cfg.GitLab); err != nil {
+			return nil, err
+		}
+		r.apis[SourceGitLab] = gitlabAPI
+	}
+
+	githubProjects := make(map[string]string)
+	for key, project := range cfg.Projects {
+		switch project.Source {
+		case SourceGitHub:
+			githubProjects[strings.ToLower(project.Repository)] = key
+			r.sources[key] = &githubProject{
+				api:      githubAPI,
+				key:      key,
+				cfg:      project,
+				pageSize: batchSize,
+				projectByRepo: func(repository string) string {
+					return githubProjects[strings.ToLower(repository)]
+				},
+			}
+		case SourceGitLab:
+			r.sources[key] = &gitlabProject{api: gitlabAPI, key: key, cfg: project, pageSize: batchSize}
+		}
+	}
+
+	return r, nil
+}
+
+// trackerOf возвращает трекер проекта.
+func (r *Router) trackerOf(projectKey string) repository.Tracker {
+	if source, ok := r.sources[projectKey]; ok {
+		return source
+	}
+	return r.fallback
+}
+
+// trackerOfIssue возвращает трекер проекта задачи.
+func (r *Router) trackerOfIssue(issueKey string) repository.Tracker {
+	projectKey, _, ok := parseIssueKey(issueKey)
+	if !ok {
+		return r.fallback
+	}
+	return r.trackerOf(projectKey)
+}
+
+// GetAllProjects возвращает проекты трекера по умолчанию и проекты
+// из конфигурации.
+func (r *Router) GetAllProjects(ctx context.Context) ([]models.JiraProject, error) {
+	projects, err := r.fallback.GetAllProjects(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, 0, len(r.sources))
+	for key := range r.sources {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		sourceProjects, err := r.sources[key].GetAllProjects(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch project %s: %w", key, err)
+		}
+		projects = append(projects, sourceProjects...)
+	}
+	return projects, nil
+}
+
+func (r *Router) GetProjectIssues(ctx context.Context, projectKey string, updatedSince *time.Time, startAt int) ([]models.JiraIssue, error) {
+	return r.trackerOf(projectKey).GetProjectIssues(ctx, projectKey, updatedSince, startAt)
+}
+
+func (r *Router) GetIssueCount(ctx context.Context, projectKey string, updatedSince *time.Time) (int, error) {
+	return r.trackerOf(projectKey).GetIssueCount(ctx, projectKey, updatedSince)
+}
+
+func (r *Router) GetIssueWorklogs(ctx context.Context, issueKey string) ([]models.JiraWorklog, error) {
+	return r.trackerOfIssue(issueKey).GetIssueWorklogs(ctx, issueKey)
+}
+
+func (r *Router) GetIssueRef(ctx context.Context, issueKey string) (*models.JiraIssueRef, error) {
+	return r.trackerOfIssue(issueKey).GetIssueRef(ctx, issueKey)
+}
+
+func (r *Router) GetIssue(ctx context.Context, issueKey string) (*models.JiraIssue, error) {
+	return r.trackerOfIssue(issueKey).GetIssue(ctx, issueKey)
+}
+
+func (r *Router) GetProjectBoards(ctx context.Context, projectKey string) ([]models.JiraBoard, error) {
+	return r.trackerOf(projectKey).GetProjectBoards(ctx, projectKey)
+}
+
+func (r *Router) GetBoardSprints(ctx context.Context, boardID int) ([]models.JiraSprint, error) {
+	return r.fallback.GetBoardSprints(ctx, boardID)
+}
+
+func (r *Router) GetSprintIssueKeys(ctx context.Context, sprintID int) ([]string, error) {
+	return r.fallback.GetSprintIssueKeys(ctx, sprintID)
+}
+
+// RateLimiterMetrics - метрики лимитера трекера по умолчанию, метрики
+// лимитеров GitHub и GitLab - в Trackers по источнику.
+func (r *Router) RateLimiterMetrics() models.RateLimiterMetrics {
+	metrics := r.fallback.RateLimiterMetrics()
+	if len(r.apis) == 0 {
+		return metrics
+	}
+
+	metrics.Trackers = make(map[string]models.RateLimiterMetrics, len(r.apis))
+	for source, api := range r.apis {
+		metrics.Trackers[source] = api.limiter.Metrics()
+	}
+	return metrics
+}
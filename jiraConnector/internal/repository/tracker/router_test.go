@@ -0,0 +1,74 @@
+package tracker
+
+import (
+	"context"
+	"jiraAnalyzer/jiraConnector/internal/models"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestRouterRateLimiterMetricsPerTracker(t *testing.T) {
+	reset := time.Now().Add(time.Hour).Truncate(time.Second)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-RateLimit-Limit", "5000")
+		w.Header().Set("X-RateLimit-Remaining", "0")
+		w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(reset.Unix(), 10))
+		w.Write([]byte(`{"full_name": "example-org/web", "name": "web"}`))
+	}))
+	defer server.Close()
+
+	cfg := Config{
+		GitHub:   APIConfig{APIURL: server.URL, RequestsPerSecond: 100},
+		Projects: map[string]ProjectConfig{"WEB": {Source: SourceGitHub, Repository: "example-org/web"}},
+	}
+	router, err := NewRouter(jiraStub{}, cfg, 50)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := router.trackerOf("WEB").GetAllProjects(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	metrics := router.RateLimiterMetrics()
+	if metrics.Requests != 7 {
+		t.Errorf("jira requests = %d, want 7", metrics.Requests)
+	}
+	github, ok := metrics.Trackers[SourceGitHub]
+	if !ok {
+		t.Fatalf("no github metrics in %+v", metrics.Trackers)
+	}
+	if github.Requests != 1 || github.RateLimitRemaining == nil || *github.RateLimitRemaining != 0 {
+		t.Errorf("github metrics = %+v, want 1 request and no remaining quota", github)
+	}
+	if github.PausedUntil == nil || !github.PausedUntil.Equal(reset) {
+		t.Errorf("github paused until %v, want %v", github.PausedUntil, reset)
+	}
+	if _, ok := metrics.Trackers[SourceGitLab]; ok {
+		t.Error("metrics of unused gitlab tracker")
+	}
+}
+
+// jiraStub - трекер по умолчанию, у которого важны только метрики.
+type jiraStub struct {
+	plainTracker
+}
+
+func (jiraStub) GetAllProjects(ctx context.Context) ([]models.JiraProject, error) { return nil, nil }
+func (jiraStub) GetIssueCount(ctx context.Context, projectKey string, updatedSince *time.Time) (int, error) {
+	return 0, nil
+}
+func (jiraStub) GetProjectIssues(ctx context.Context, projectKey string, updatedSince *time.Time, startAt int) ([]models.JiraIssue, error) {
+	return nil, nil
+}
+func (jiraStub) GetIssueRef(ctx context.Context, issueKey string) (*models.JiraIssueRef, error) {
+	return nil, nil
+}
+func (jiraStub) GetIssue(ctx context.Context, issueKey string) (*models.JiraIssue, error) {
+	return nil, nil
+}
+func (jiraStub) RateLimiterMetrics() models.RateLimiterMetrics {
+	return models.RateLimiterMetrics{Requests: 7}
+}
@@ -0,0 +1,163 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"jiraAnalyzer/jiraConnector/internal/models"
+	"sync"
+)
+
+// authorCache хранит известных авторов по идентификатору Jira, чтобы
+// не обращаться к БД за каждым автором задачи. Кэш общий для всех потоков
+// и перечитывается из БД перед каждой синхронизацией: так учитываются
+// авторы, объединённые через backend.
+type authorCache struct {
+	mu      sync.RWMutex
+	authors map[string]models.DBAuthor
+}
+
+func newAuthorCache() *authorCache {
+	return &authorCache{authors: make(map[string]models.DBAuthor)}
+}
+
+// warm загружает авторов из БД.
+func (c *authorCache) warm(ctx context.Context, load func(ctx context.Context) ([]models.DBAuthor, error)) error {
+	authors, err := load(ctx)
+	if err != nil {
+		return err
+	}
+
+	cached := make(map[string]models.DBAuthor, len(authors))
+	for _, a := range authors {
+		cached[a.AccountID] = a
+	}
+
+	c.mu.Lock()
+	c.authors = cached
+	c.mu.Unlock()
+	return nil
+}
+
+// resolve возвращает id всех авторов батча. Новые и изменившиеся авторы
+// сохраняются через save в транзакции батча; в кэш они попадают только
+// после коммита через store.
+func (c *authorCache) resolve(tx *sql.Tx, authors map[string]models.DBAuthor, save func(tx *sql.Tx, authors []models.DBAuthor) (map[string]int, error)) (authorIDs, []models.DBAuthor, error) {
+	ids := make(authorIDs, len(authors))
+	var changed []models.DBAuthor
+
+	c.mu.RLock()
+	for accountID, author := range authors {
+		cached, ok := c.authors[accountID]
+		if ok && !authorChanged(cached, author) {
+			ids[accountID] = cached.ID
+			continue
+		}
+		changed = append(changed, author)
+	}
+	c.mu.RUnlock()
+
+	saved, err := save(tx, changed)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for i := range changed {
+		id, ok := saved[changed[i].AccountID]
+		if !ok {
+			return nil, nil, fmt.Errorf("author %s was not saved", changed[i].AccountID)
+		}
+		changed[i].ID = id
+		ids[changed[i].AccountID] = id
+	}
+
+	return ids, changed, nil
+}
+
+// lookup возвращает id известных авторов батча без записи в БД и
+// авторов, которых ещё нет в кэше. У новых авторов id нет.
+func (c *authorCache) lookup(authors map[string]models.DBAuthor) (authorIDs, []models.DBAuthor) {
+	ids := make(authorIDs, len(authors))
+	var unknown []models.DBAuthor
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	for accountID, author := range authors {
+		if cached, ok := c.authors[accountID]; ok {
+			ids[accountID] = cached.ID
+			continue
+		}
+		unknown = append(unknown, author)
+	}
+	return ids, unknown
+}
+
+// names возвращает имена известных авторов по id.
+func (c *authorCache) names() map[int]string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	names := make(map[int]string, len(c.authors))
+	for _, a := range c.authors {
+		names[a.ID] = a.DisplayName
+	}
+	return names
+}
+
+// store добавляет в кэш авторов, сохранённых закоммиченным батчем.
+func (c *authorCache) store(authors []models.DBAuthor) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, a := range authors {
+		c.authors[a.AccountID] = a
+	}
+}
+
+// authorChanged - автор сменил имя, email или активность. Пустой email
+// не затирает сохранённый, поэтому изменением не считается.
+func authorChanged(cached, author models.DBAuthor) bool {
+	if cached.DisplayName != author.DisplayName || cached.Active != author.Active {
+		return true
+	}
+	return author.Email != nil && (cached.Email == nil || *cached.Email != *author.Email)
+}
+
+// authorIDs - id авторов батча по идентификатору Jira.
+type authorIDs map[string]int
+
+func (ids authorIDs) id(author models.JiraAuthor) int {
+	return ids[author.Identity()]
+}
+
+// collectAuthors возвращает всех авторов, упомянутых в задачах батча.
+func collectAuthors(issues []models.JiraIssue) map[string]models.DBAuthor {
+	authors := make(map[string]models.DBAuthor)
+	add := func(a models.JiraAuthor) {
+		author := transformAuthor(a)
+		authors[author.AccountID] = author
+	}
+
+	for _, issue := range issues {
+		add(issue.Fields.Creator)
+		if issue.Fields.Assignee != nil {
+			add(*issue.Fields.Assignee)
+		}
+		for _, history := range issue.Changelog.Histories {
+			if len(history.Items) > 0 {
+				add(history.Author)
+			}
+		}
+		for _, comment := range issue.Fields.Comment.Comments {
+			add(comment.Author)
+		}
+		if issue.Fields.Worklog == nil {
+			continue
+		}
+		for _, worklog := range issue.Fields.Worklog.Worklogs {
+			add(worklog.Author)
+		}
+	}
+
+	return authors
+}
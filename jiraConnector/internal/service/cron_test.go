@@ -0,0 +1,76 @@
+package service
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseCronRejectsInvalidExpressions(t *testing.T) {
+	for _, expr := range []string{
+		"* * * *",
+		"* * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"5-1 * * * *",
+		"a * * * *",
+		"1-x * * * *",
+	} {
+		if _, err := parseCron(expr); err == nil {
+			t.Errorf("parseCron(%q) succeeded, want error", expr)
+		}
+	}
+}
+
+func TestCronNext(t *testing.T) {
+	at := func(value string) time.Time {
+		parsed, err := time.Parse("2006-01-02 15:04", value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return parsed
+	}
+
+	tests := []struct {
+		expr, from, want string
+	}{
+		{"*/15 * * * *", "2024-03-01 10:07", "2024-03-01 10:15"},
+		// Срабатывание строго после from
+		{"5 * * * *", "2024-03-01 10:05", "2024-03-01 11:05"},
+		{"10/20 * * * *", "2024-03-01 10:31", "2024-03-01 10:50"},
+		{"0 8-18/5 * * *", "2024-03-01 12:00", "2024-03-01 13:00"},
+		{"0 9,17 * * *", "2024-03-01 17:00", "2024-03-02 09:00"},
+		// Пятница 8 марта -> понедельник 11 марта
+		{"0 9 * * 1-5", "2024-03-08 10:00", "2024-03-11 09:00"},
+		{"30 2 1 * *", "2024-01-31 23:00", "2024-02-01 02:30"},
+		{"0 0 29 2 *", "2023-03-01 00:00", "2024-02-29 00:00"},
+		{"0 0 * * 7", "2024-03-01 00:00", "2024-03-03 00:00"},
+		// День месяца или день недели: 1 марта - пятница, ближайшее
+		// воскресенье наступает раньше 1 апреля
+		{"0 0 1 * 0", "2024-03-01 00:00", "2024-03-03 00:00"},
+		{"0 0 1 * 0", "2024-03-31 00:00", "2024-04-01 00:00"},
+	}
+
+	for _, tt := range tests {
+		schedule, err := parseCron(tt.expr)
+		if err != nil {
+			t.Fatalf("parseCron(%q): %v", tt.expr, err)
+		}
+		if got := schedule.next(at(tt.from)); !got.Equal(at(tt.want)) {
+			t.Errorf("%q after %s = %s, want %s", tt.expr, tt.from, got.Format("2006-01-02 15:04"), tt.want)
+		}
+	}
+}
+
+func TestCronNextNeverFires(t *testing.T) {
+	schedule, err := parseCron("0 0 30 2 *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := schedule.next(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)); !got.IsZero() {
+		t.Errorf("next = %s, want zero time", got)
+	}
+}
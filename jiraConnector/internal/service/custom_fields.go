@@ -0,0 +1,155 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Типы пользовательских полей Jira
+const (
+	CustomFieldString = "string"
+	CustomFieldNumber = "number"
+	CustomFieldDate   = "date"
+	// CustomFieldOption - поле выбора, хранится значение варианта
+	CustomFieldOption = "option"
+	// CustomFieldUser - поле с пользователем, хранится отображаемое имя
+	CustomFieldUser = "user"
+	// CustomFieldArray - множественное поле, хранится список строк
+	CustomFieldArray = "array"
+)
+
+// CustomFieldsConfig сопоставляет логическое имя поля (storyPoints, team,
+// epicLink) с полем Jira. Значения сохраняются в issues.custom_fields
+// под логическими именами.
+type CustomFieldsConfig map[string]CustomFieldConfig
+
+type CustomFieldConfig struct {
+	ID   string `yaml:"id"`
+	Type string `yaml:"type"`
+}
+
+func (c CustomFieldsConfig) Validate() error {
+	ids := make(map[string]string, len(c))
+	for name, field := range c {
+		if !strings.HasPrefix(field.ID, "customfield_") {
+			return fmt.Errorf("custom field %q: id must look like customfield_XXXXX, got %q", name, field.ID)
+		}
+		if other, ok := ids[field.ID]; ok {
+			return fmt.Errorf("custom fields %q and %q map to the same id %s", other, name, field.ID)
+		}
+		ids[field.ID] = name
+
+		switch field.Type {
+		case CustomFieldString, CustomFieldNumber, CustomFieldDate, CustomFieldOption, CustomFieldUser, CustomFieldArray:
+		default:
+			return fmt.Errorf("custom field %q: unknown type %q", name, field.Type)
+		}
+	}
+	return nil
+}
+
+// extract возвращает значения настроенных полей задачи по логическим именам.
+// Пустые поля пропускаются, значения неверного типа логируются и пропускаются.
+func (c CustomFieldsConfig) extract(issueKey string, raw map[string]json.RawMessage) map[string]interface{} {
+	values := make(map[string]interface{}, len(c))
+
+	for name, field := range c {
+		data, ok := raw[field.ID]
+		if !ok || string(data) == "null" {
+			continue
+		}
+
+		value, err := decodeCustomField(field.Type, data)
+		if err != nil {
+			log.Printf("Issue %s: failed to decode custom field %s (%s): %v", issueKey, name, field.ID, err)
+			continue
+		}
+		if value != nil {
+			values[name] = value
+		}
+	}
+
+	return values
+}
+
+func decodeCustomField(fieldType string, data json.RawMessage) (interface{}, error) {
+	switch fieldType {
+	case CustomFieldNumber:
+		var number float64
+		if err := json.Unmarshal(data, &number); err == nil {
+			return number, nil
+		}
+		// В XML-выгрузке Jira все значения - строки
+		var str string
+		if err := json.Unmarshal(data, &str); err != nil {
+			return nil, err
+		}
+		return strconv.ParseFloat(str, 64)
+	case CustomFieldDate:
+		var str string
+		if err := json.Unmarshal(data, &str); err != nil {
+			return nil, err
+		}
+		// Поле даты приходит как "2006-01-02", поле даты и времени - в формате задачи
+		if _, err := time.Parse(time.DateOnly, str); err == nil {
+			return str, nil
+		}
+		t, err := parseJiraTime(str)
+		if err != nil {
+			return nil, fmt.Errorf("invalid date %q", str)
+		}
+		return t.UTC().Format(time.RFC3339), nil
+	case CustomFieldArray:
+		var items []json.RawMessage
+		if err := json.Unmarshal(data, &items); err != nil {
+			// Одно значение множественного поля в XML-выгрузке не оборачивается в список
+			value, err := displayValue(data)
+			if err != nil {
+				return nil, err
+			}
+			return []string{value}, nil
+		}
+		values := make([]string, 0, len(items))
+		for _, item := range items {
+			value, err := displayValue(item)
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, value)
+		}
+		return values, nil
+	default:
+		// string, option и user сводятся к строке
+		return displayValue(data)
+	}
+}
+
+// displayValue извлекает строковое представление значения поля: саму строку
+// или value/name/displayName объекта.
+func displayValue(data json.RawMessage) (string, error) {
+	var str string
+	if err := json.Unmarshal(data, &str); err == nil {
+		return str, nil
+	}
+
+	var object struct {
+		Value       string `json:"value"`
+		Name        string `json:"name"`
+		DisplayName string `json:"displayName"`
+		Key         string `json:"key"`
+	}
+	if err := json.Unmarshal(data, &object); err != nil {
+		return "", fmt.Errorf("unexpected value %s", data)
+	}
+
+	for _, value := range []string{object.Value, object.DisplayName, object.Name, object.Key} {
+		if value != "" {
+			return value, nil
+		}
+	}
+	return "", fmt.Errorf("unexpected value %s", data)
+}
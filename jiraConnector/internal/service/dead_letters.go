@@ -0,0 +1,126 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"jiraAnalyzer/jiraConnector/internal/models"
+	"log"
+)
+
+var ErrDeadLetterNotFound = errors.New("dead letter not found")
+
+// deadLetterRetryLimit - сколько записей проекта повторяется за один запрос.
+const deadLetterRetryLimit = 1000
+
+// issueFailure - задача, которую не удалось преобразовать или сохранить.
+type issueFailure struct {
+	issue models.JiraIssue
+	stage string
+	err   error
+}
+
+// DeadLetterRetryResult - итог повтора записей проекта.
+type DeadLetterRetryResult struct {
+	Retried  int `json:"retried"`
+	Resolved int `json:"resolved"`
+	Failed   int `json:"failed"`
+}
+
+// saveDeadLetters сохраняет задачи, не прошедшие ETL, в транзакции батча.
+func (s *ETLService) saveDeadLetters(tx *sql.Tx, projectKey string, failures []issueFailure, partial bool, checkpoint *models.DBSyncCheckpoint) error {
+	if len(failures) == 0 {
+		return nil
+	}
+
+	var runID *int
+	if checkpoint != nil {
+		runID = &checkpoint.RunID
+	}
+
+	letters := make([]models.DBDeadLetter, 0, len(failures))
+	for _, f := range failures {
+		// Задача хранится как её прислала Jira, собранная не из JSON Jira -
+		// в модели REST API. Задача GitHub или GitLab дополнительно хранится
+		// в формате трекера.
+		raw := f.issue.Raw
+		if len(raw) == 0 {
+			var err error
+			if raw, err = json.Marshal(f.issue); err != nil {
+				return fmt.Errorf("failed to marshal issue %s: %w", f.issue.Key, err)
+			}
+		}
+		letters = append(letters, models.DBDeadLetter{
+			IssueKey:   f.issue.Key,
+			ProjectKey: projectKey,
+			SyncRunID:  runID,
+			Stage:      f.stage,
+			Error:      f.err.Error(),
+			Raw:        raw,
+			Partial:    partial,
+			SourceRaw:  f.issue.SourceRaw,
+		})
+	}
+
+	log.Printf("Saving %d issues of project %s to dead letters", len(letters), projectKey)
+	return s.repo.SaveDeadLettersTx(tx, letters)
+}
+
+// ListDeadLetters возвращает последние записи проекта или всех проектов.
+func (s *ETLService) ListDeadLetters(ctx context.Context, projectKey string, resolved bool, limit int) ([]models.DBDeadLetter, error) {
+	return s.repo.ListDeadLetters(ctx, projectKey, resolved, limit)
+}
+
+// RetryDeadLetter повторно сохраняет задачу из записи. Задача берётся в том
+// виде, в каком её вернула Jira, поэтому повтор имеет смысл после
+// исправления настройки или схемы. Возвращается обновлённая запись:
+// решённая или с новой ошибкой.
+func (s *ETLService) RetryDeadLetter(ctx context.Context, id int) (models.DBDeadLetter, error) {
+	letter, err := s.repo.GetDeadLetter(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.DBDeadLetter{}, ErrDeadLetterNotFound
+	} else if err != nil {
+		return models.DBDeadLetter{}, err
+	}
+	if letter.ResolvedAt != nil {
+		return letter, nil
+	}
+
+	var issue models.JiraIssue
+	if err := json.Unmarshal(letter.Raw, &issue); err != nil {
+		return models.DBDeadLetter{}, fmt.Errorf("failed to unmarshal dead letter %d: %w", id, err)
+	}
+	issue.SourceRaw = letter.SourceRaw
+
+	if _, err := s.saveIssues(ctx, letter.ProjectKey, []models.JiraIssue{issue}, letter.Partial, nil); err != nil {
+		return models.DBDeadLetter{}, err
+	}
+
+	return s.repo.GetDeadLetter(ctx, id)
+}
+
+// RetryDeadLetters повторяет нерешённые записи проекта.
+func (s *ETLService) RetryDeadLetters(ctx context.Context, projectKey string) (DeadLetterRetryResult, error) {
+	letters, err := s.repo.ListDeadLetters(ctx, projectKey, false, deadLetterRetryLimit)
+	if err != nil {
+		return DeadLetterRetryResult{}, err
+	}
+
+	var result DeadLetterRetryResult
+	for _, l := range letters {
+		letter, err := s.RetryDeadLetter(ctx, l.ID)
+		if err != nil {
+			return result, fmt.Errorf("failed to retry dead letter %d: %w", l.ID, err)
+		}
+		result.Retried++
+		if letter.ResolvedAt != nil {
+			result.Resolved++
+		} else {
+			result.Failed++
+		}
+	}
+
+	return result, nil
+}
@@ -0,0 +1,378 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"jiraAnalyzer/jiraConnector/internal/models"
+	"reflect"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// wallClockLayout - время без часового пояса. Поля TIMESTAMP хранят время
+// задачи без пояса, поэтому сохранённое и загруженное время сравниваются
+// по показаниям часов.
+const wallClockLayout = "2006-01-02 15:04:05.999999"
+
+// DryRunReport - отчёт пробной синхронизации: что изменилось бы в базе,
+// если бы загруженные задачи были сохранены. Батчи заполняют отчёт
+// конкурентно, списки упорядочиваются при сериализации.
+type DryRunReport struct {
+	mu         sync.Mutex
+	projects   map[string]*ProjectDryRun
+	newAuthors map[string]DryRunAuthor
+}
+
+// ProjectDryRun - изменения задач одного проекта.
+type ProjectDryRun struct {
+	// NewProject - проекта ещё нет в базе
+	NewProject      bool        `json:"newProject"`
+	NewIssues       []string    `json:"newIssues"`
+	UpdatedIssues   []IssueDiff `json:"updatedIssues"`
+	UnchangedIssues []string    `json:"unchangedIssues"`
+	// NewStatusChanges и NewFieldChanges - строки истории, которых нет в базе
+	NewStatusChanges []StatusChangeRow `json:"newStatusChanges"`
+	NewFieldChanges  []FieldChangeRow  `json:"newFieldChanges"`
+	// FailedIssues - задачи, которые не удалось преобразовать: при
+	// синхронизации они попали бы в etl_dead_letters
+	FailedIssues []FailedIssue `json:"failedIssues"`
+}
+
+// FailedIssue - задача, не прошедшая преобразование.
+type FailedIssue struct {
+	Key   string `json:"key"`
+	Error string `json:"error"`
+}
+
+// IssueDiff - изменившиеся поля сохранённой задачи.
+type IssueDiff struct {
+	Key    string      `json:"key"`
+	Fields []FieldDiff `json:"fields"`
+}
+
+type FieldDiff struct {
+	Field string `json:"field"`
+	Old   string `json:"old"`
+	New   string `json:"new"`
+}
+
+type StatusChangeRow struct {
+	IssueKey   string    `json:"issueKey"`
+	Created    time.Time `json:"created"`
+	FromStatus string    `json:"fromStatus"`
+	ToStatus   string    `json:"toStatus"`
+}
+
+type FieldChangeRow struct {
+	IssueKey   string    `json:"issueKey"`
+	HistoryID  string    `json:"historyId"`
+	ItemIndex  int       `json:"itemIndex"`
+	Created    time.Time `json:"created"`
+	Field      string    `json:"field"`
+	FromString *string   `json:"fromString"`
+	ToString   *string   `json:"toString"`
+}
+
+type DryRunAuthor struct {
+	AccountID   string `json:"accountId"`
+	DisplayName string `json:"displayName"`
+}
+
+// DryRunSummary - итоги отчёта по всем проектам.
+type DryRunSummary struct {
+	NewIssues        int `json:"newIssues"`
+	UpdatedIssues    int `json:"updatedIssues"`
+	UnchangedIssues  int `json:"unchangedIssues"`
+	NewAuthors       int `json:"newAuthors"`
+	NewStatusChanges int `json:"newStatusChanges"`
+	NewFieldChanges  int `json:"newFieldChanges"`
+	FailedIssues     int `json:"failedIssues"`
+}
+
+func NewDryRunReport() *DryRunReport {
+	return &DryRunReport{
+		projects:   make(map[string]*ProjectDryRun),
+		newAuthors: make(map[string]DryRunAuthor),
+	}
+}
+
+// project возвращает изменения проекта. Вызывается под r.mu.
+func (r *DryRunReport) project(projectKey string) *ProjectDryRun {
+	p, ok := r.projects[projectKey]
+	if !ok {
+		p = &ProjectDryRun{
+			NewIssues:        []string{},
+			UpdatedIssues:    []IssueDiff{},
+			UnchangedIssues:  []string{},
+			NewStatusChanges: []StatusChangeRow{},
+			NewFieldChanges:  []FieldChangeRow{},
+			FailedIssues:     []FailedIssue{},
+		}
+		r.projects[projectKey] = p
+	}
+	return p
+}
+
+func (r *DryRunReport) markNewProject(projectKey string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.project(projectKey).NewProject = true
+}
+
+// Project возвращает упорядоченную копию изменений проекта.
+func (r *DryRunReport) Project(projectKey string) (ProjectDryRun, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	p, ok := r.projects[projectKey]
+	if !ok {
+		return ProjectDryRun{}, false
+	}
+	p.sort()
+	return *p, true
+}
+
+// NewAuthors возвращает авторов, которых нет в базе, по идентификатору.
+func (r *DryRunReport) NewAuthors() []DryRunAuthor {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.sortedAuthors()
+}
+
+func (r *DryRunReport) sortedAuthors() []DryRunAuthor {
+	authors := make([]DryRunAuthor, 0, len(r.newAuthors))
+	for _, a := range r.newAuthors {
+		authors = append(authors, a)
+	}
+	sort.Slice(authors, func(i, j int) bool { return authors[i].AccountID < authors[j].AccountID })
+	return authors
+}
+
+func (r *DryRunReport) MarshalJSON() ([]byte, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	summary := DryRunSummary{NewAuthors: len(r.newAuthors)}
+	for _, p := range r.projects {
+		p.sort()
+		summary.NewIssues += len(p.NewIssues)
+		summary.UpdatedIssues += len(p.UpdatedIssues)
+		summary.UnchangedIssues += len(p.UnchangedIssues)
+		summary.NewStatusChanges += len(p.NewStatusChanges)
+		summary.NewFieldChanges += len(p.NewFieldChanges)
+		summary.FailedIssues += len(p.FailedIssues)
+	}
+
+	return json.Marshal(struct {
+		Summary    DryRunSummary             `json:"summary"`
+		Projects   map[string]*ProjectDryRun `json:"projects"`
+		NewAuthors []DryRunAuthor            `json:"newAuthors"`
+	}{summary, r.projects, r.sortedAuthors()})
+}
+
+func (p *ProjectDryRun) sort() {
+	sort.Slice(p.NewIssues, func(i, j int) bool { return p.NewIssues[i] < p.NewIssues[j] })
+	sort.Slice(p.UpdatedIssues, func(i, j int) bool { return p.UpdatedIssues[i].Key < p.UpdatedIssues[j].Key })
+	sort.Slice(p.UnchangedIssues, func(i, j int) bool { return p.UnchangedIssues[i] < p.UnchangedIssues[j] })
+	sort.Slice(p.FailedIssues, func(i, j int) bool { return p.FailedIssues[i].Key < p.FailedIssues[j].Key })
+	sort.Slice(p.NewStatusChanges, func(i, j int) bool {
+		a, b := p.NewStatusChanges[i], p.NewStatusChanges[j]
+		if a.IssueKey != b.IssueKey {
+			return a.IssueKey < b.IssueKey
+		}
+		return a.Created.Before(b.Created)
+	})
+	sort.Slice(p.NewFieldChanges, func(i, j int) bool {
+		a, b := p.NewFieldChanges[i], p.NewFieldChanges[j]
+		if a.IssueKey != b.IssueKey {
+			return a.IssueKey < b.IssueKey
+		}
+		if !a.Created.Equal(b.Created) {
+			return a.Created.Before(b.Created)
+		}
+		if a.HistoryID != b.HistoryID {
+			return a.HistoryID < b.HistoryID
+		}
+		return a.ItemIndex < b.ItemIndex
+	})
+}
+
+// previewIssues преобразует задачи батча так же, как saveIssues, и вместо
+// записи сравнивает результат с базой. Новые авторы не сохраняются,
+// поэтому у них нет id.
+func (s *ETLService) previewIssues(ctx context.Context, projectKey string, issues []models.JiraIssue, report *DryRunReport) ([]string, error) {
+	authors, newAuthors := s.authors.lookup(collectAuthors(issues))
+
+	batch, failures := s.transformIssues(issues, projectKey, authors)
+
+	stored, err := s.repo.GetIssuesByKeys(ctx, batch.issueKeys)
+	if err != nil {
+		return nil, err
+	}
+	statusChanges, err := s.repo.GetStatusChanges(ctx, batch.issueKeys)
+	if err != nil {
+		return nil, err
+	}
+	fieldChanges, err := s.repo.GetFieldChanges(ctx, batch.issueKeys)
+	if err != nil {
+		return nil, err
+	}
+
+	storedIssues := make(map[string]models.DBIssue, len(stored))
+	for _, issue := range stored {
+		storedIssues[issue.Key] = issue
+	}
+	storedStatusChanges := make(map[string]bool, len(statusChanges))
+	for _, c := range statusChanges {
+		storedStatusChanges[c.IssueID+"|"+c.Created.Format(wallClockLayout)] = true
+	}
+	storedFieldChanges := make(map[string]bool, len(fieldChanges))
+	for _, c := range fieldChanges {
+		storedFieldChanges[fmt.Sprintf("%s|%s|%d", c.IssueID, c.HistoryID, c.ItemIndex)] = true
+	}
+	authorNames := s.authors.names()
+	sources := make(map[string]models.JiraIssue, len(issues))
+	for _, issue := range issues {
+		sources[issue.Key] = issue
+	}
+
+	report.mu.Lock()
+	defer report.mu.Unlock()
+
+	p := report.project(projectKey)
+	for _, a := range newAuthors {
+		report.newAuthors[a.AccountID] = DryRunAuthor{AccountID: a.AccountID, DisplayName: a.DisplayName}
+	}
+
+	for _, f := range failures {
+		p.FailedIssues = append(p.FailedIssues, FailedIssue{Key: f.issue.Key, Error: f.err.Error()})
+	}
+
+	for _, issue := range batch.issues {
+		old, ok := storedIssues[issue.Key]
+		if !ok {
+			p.NewIssues = append(p.NewIssues, issue.Key)
+			continue
+		}
+
+		fields := diffIssue(old, issue, sources[issue.Key], authorNames)
+		if len(fields) == 0 {
+			p.UnchangedIssues = append(p.UnchangedIssues, issue.Key)
+			continue
+		}
+		p.UpdatedIssues = append(p.UpdatedIssues, IssueDiff{Key: issue.Key, Fields: fields})
+	}
+
+	for _, c := range batch.changelogs {
+		if storedStatusChanges[c.IssueID+"|"+c.Created.Format(wallClockLayout)] {
+			continue
+		}
+		p.NewStatusChanges = append(p.NewStatusChanges, StatusChangeRow{
+			IssueKey:   c.IssueID,
+			Created:    c.Created,
+			FromStatus: c.FromStatus,
+			ToStatus:   c.ToStatus,
+		})
+	}
+
+	for _, c := range batch.fieldChanges {
+		if storedFieldChanges[fmt.Sprintf("%s|%s|%d", c.IssueID, c.HistoryID, c.ItemIndex)] {
+			continue
+		}
+		p.NewFieldChanges = append(p.NewFieldChanges, FieldChangeRow{
+			IssueKey:   c.IssueID,
+			HistoryID:  c.HistoryID,
+			ItemIndex:  c.ItemIndex,
+			Created:    c.Created,
+			Field:      c.Field,
+			FromString: c.FromString,
+			ToString:   c.ToString,
+		})
+	}
+
+	return issueKeys(issues), nil
+}
+
+// diffIssue сравнивает задачу с сохранённой по правилам SaveIssuesTx:
+// более старая версия задачи не записывается, а неизвестное время
+// закрытия не сбрасывает сохранённое. Авторы сравниваются по id,
+// в отчёт попадают их имена.
+func diffIssue(old, issue models.DBIssue, source models.JiraIssue, authorNames map[int]string) []FieldDiff {
+	if old.Updated.Format(wallClockLayout) > issue.Updated.Format(wallClockLayout) {
+		return nil
+	}
+	if issue.Closed == nil {
+		issue.Closed = old.Closed
+	}
+
+	var diffs []FieldDiff
+	add := func(field, oldValue, newValue string) {
+		if oldValue != newValue {
+			diffs = append(diffs, FieldDiff{Field: field, Old: oldValue, New: newValue})
+		}
+	}
+
+	add("project_key", old.ProjectKey, issue.ProjectKey)
+	add("created", formatWallClock(&old.Created), formatWallClock(&issue.Created))
+	add("updated", formatWallClock(&old.Updated), formatWallClock(&issue.Updated))
+	add("closed", formatWallClock(old.Closed), formatWallClock(issue.Closed))
+	add("summary", old.Summary, issue.Summary)
+	add("description", old.Description, issue.Description)
+	add("issue_type", old.Type, issue.Type)
+	add("priority", old.Priority, issue.Priority)
+	add("status", old.Status, issue.Status)
+	add("time_spent", strconv.Itoa(old.TimeSpent), strconv.Itoa(issue.TimeSpent))
+
+	if old.CreatorID != issue.CreatorID {
+		diffs = append(diffs, FieldDiff{Field: "creator", Old: authorNames[old.CreatorID], New: source.Fields.Creator.DisplayName})
+	}
+	if authorID(old.AssigneeID) != authorID(issue.AssigneeID) {
+		newAssignee := ""
+		if source.Fields.Assignee != nil {
+			newAssignee = source.Fields.Assignee.DisplayName
+		}
+		oldAssignee := ""
+		if old.AssigneeID != nil {
+			oldAssignee = authorNames[*old.AssigneeID]
+		}
+		diffs = append(diffs, FieldDiff{Field: "assignee", Old: oldAssignee, New: newAssignee})
+	}
+
+	if !sameJSON(old.CustomFields, issue.CustomFields) {
+		diffs = append(diffs, FieldDiff{Field: "custom_fields", Old: string(old.CustomFields), New: string(issue.CustomFields)})
+	}
+
+	return diffs
+}
+
+// authorID - id автора или -1 для пустого исполнителя. Новый автор
+// без id (0) отличается от пустого исполнителя.
+func authorID(id *int) int {
+	if id == nil {
+		return -1
+	}
+	return *id
+}
+
+func formatWallClock(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format(wallClockLayout)
+}
+
+// sameJSON сравнивает JSON по значению: JSONB возвращается с другими
+// пробелами и порядком ключей.
+func sameJSON(a, b []byte) bool {
+	if bytes.Equal(a, b) {
+		return true
+	}
+	var va, vb interface{}
+	if json.Unmarshal(a, &va) != nil || json.Unmarshal(b, &vb) != nil {
+		return false
+	}
+	return reflect.DeepEqual(va, vb)
+}
@@ -0,0 +1,906 @@
+package service_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"jiraAnalyzer/jiraConnector/internal/fakejira"
+	"jiraAnalyzer/jiraConnector/internal/models"
+	"jiraAnalyzer/jiraConnector/internal/repository"
+	"jiraAnalyzer/jiraConnector/internal/repository/jira"
+	"jiraAnalyzer/jiraConnector/internal/repository/offline"
+	"jiraAnalyzer/jiraConnector/internal/service"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Сквозные тесты синхронизации: ETLService с настоящим клиентом Jira
+// против fakejira и хранилища в памяти.
+
+const (
+	fixturesDir = "../../testdata/fakejira"
+	// fixtureIssues - задач проекта DEMO в фикстурах
+	fixtureIssues = 7
+	batchSize     = 3
+)
+
+type testEnv struct {
+	jira  *fakejira.Server
+	store *memStore
+	repo  *repository.Repository
+	etl   *service.ETLService
+}
+
+func newTestEnv(t *testing.T, tune func(cfg *jira.ClientConfig)) *testEnv {
+	t.Helper()
+
+	server := fakejira.NewServer()
+	t.Cleanup(server.Close)
+	if err := server.LoadFixtures(fixturesDir); err != nil {
+		t.Fatalf("load fixtures: %v", err)
+	}
+
+	cfg := jira.ClientConfig{
+		JiraUrl:           server.URL,
+		IssueInOneRequest: batchSize,
+		ThreadCount:       2,
+		MaxAttempts:       3,
+		MinTimeSleep:      time.Millisecond,
+		MaxTimeSleep:      10 * time.Millisecond,
+		RequestsPerSecond: 1000,
+		Burst:             100,
+		Timeout:           5 * time.Second,
+	}
+	if tune != nil {
+		tune(&cfg)
+	}
+	client, err := jira.NewJiraClient(cfg)
+	if err != nil {
+		t.Fatalf("create jira client: %v", err)
+	}
+
+	store := newMemStore()
+	repo := &repository.Repository{JiraDB: store, Tracker: client}
+	return &testEnv{
+		jira:  server,
+		store: store,
+		repo:  repo,
+		etl:   service.NewETLService(repo, cfg.ThreadCount, cfg.IssueInOneRequest, service.CustomFieldsConfig{}),
+	}
+}
+
+func (e *testEnv) sync(t *testing.T, opts service.SyncOptions, projectKeys ...string) error {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	return e.etl.UpdateProject(ctx, projectKeys, opts)
+}
+
+// recordingProgress запоминает отчёты синхронизации.
+type recordingProgress struct {
+	mu         sync.Mutex
+	batches    int
+	loaded     int
+	failed     []error
+	reconciled service.ReconcileResult
+}
+
+func (p *recordingProgress) BatchesPlanned(_ string, batches int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.batches += batches
+}
+
+func (p *recordingProgress) BatchLoaded(_ string, issues int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.loaded += issues
+}
+
+func (p *recordingProgress) ProjectFailed(_ string, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.failed = append(p.failed, err)
+}
+
+func (p *recordingProgress) IssuesReconciled(_ string, result service.ReconcileResult) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.reconciled = result
+}
+
+func TestUpdateProjectFullSync(t *testing.T) {
+	env := newTestEnv(t, nil)
+	progress := &recordingProgress{}
+
+	if err := env.sync(t, service.SyncOptions{Full: true, Progress: progress}, "DEMO"); err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+
+	if keys := env.store.issueKeys(); len(keys) != fixtureIssues {
+		t.Fatalf("issues = %v, want %d", keys, fixtureIssues)
+	}
+	if progress.batches != 3 || progress.loaded != fixtureIssues {
+		t.Errorf("progress = %d batches, %d issues, want 3 batches, %d issues", progress.batches, progress.loaded, fixtureIssues)
+	}
+	// Запрос числа задач и три страницы
+	if n := env.jira.Requests(fakejira.EndpointSearch); n != 4 {
+		t.Errorf("search requests = %d, want 4", n)
+	}
+
+	closed, _ := env.store.issue("DEMO-3")
+	if closed.Status != "Closed" || closed.Closed == nil {
+		t.Errorf("DEMO-3 status = %q, closed = %v, want Closed with close time", closed.Status, closed.Closed)
+	}
+	if want := time.Date(2024, 3, 3, 15, 0, 0, 0, time.UTC); closed.Closed != nil && !closed.Closed.Equal(want) {
+		t.Errorf("DEMO-3 closed = %v, want %v", closed.Closed, want)
+	}
+	if n := env.store.count("changelogs", "DEMO-3"); n != 2 {
+		t.Errorf("DEMO-3 status changes = %d, want 2", n)
+	}
+	if n := env.store.count("comments", "DEMO-3"); n != 1 {
+		t.Errorf("DEMO-3 comments = %d, want 1", n)
+	}
+	if n := env.store.count("links", "DEMO-4"); n != 1 {
+		t.Errorf("DEMO-4 links = %d, want 1", n)
+	}
+
+	// Поиск отдаёт 20 списаний из 22, остальные дозагружаются
+	if n := env.store.count("worklogs", "DEMO-2"); n != 22 {
+		t.Errorf("DEMO-2 worklogs = %d, want 22", n)
+	}
+	if n := env.jira.Requests(fakejira.EndpointWorklog); n != 1 {
+		t.Errorf("worklog requests = %d, want 1", n)
+	}
+
+	if watermark, _ := env.store.GetSyncWatermark(context.Background(), "DEMO"); watermark == nil {
+		t.Error("watermark is not saved")
+	}
+}
+
+func TestUpdateProjectIncremental(t *testing.T) {
+	env := newTestEnv(t, nil)
+	if err := env.sync(t, service.SyncOptions{}, "DEMO"); err != nil {
+		t.Fatalf("first sync: %v", err)
+	}
+
+	now := time.Now().UTC()
+	updated := map[string]interface{}{
+		"key": "DEMO-8",
+		"fields": map[string]interface{}{
+			"project":   map[string]string{"key": "DEMO"},
+			"created":   now.Format(models.JiraTimeLayout),
+			"updated":   now.Format(models.JiraTimeLayout),
+			"summary":   "Created after the first sync",
+			"issuetype": map[string]string{"name": "Task"},
+			"status":    map[string]string{"name": "Open"},
+			"creator":   map[string]interface{}{"accountId": "5b10a2844c20165700ede21g", "displayName": "Anna Petrova", "active": true},
+		},
+	}
+	raw, _ := json.Marshal(updated)
+	if err := env.jira.PutIssue(raw); err != nil {
+		t.Fatalf("put issue: %v", err)
+	}
+
+	progress := &recordingProgress{}
+	if err := env.sync(t, service.SyncOptions{Progress: progress}, "DEMO"); err != nil {
+		t.Fatalf("incremental sync: %v", err)
+	}
+
+	// Задачи фикстур изменены задолго до watermark и не загружаются повторно
+	if progress.loaded != 1 {
+		t.Errorf("loaded %d issues, want 1", progress.loaded)
+	}
+	if issue, ok := env.store.issue("DEMO-8"); !ok || issue.Summary != "Created after the first sync" {
+		t.Errorf("DEMO-8 = %+v, %v", issue, ok)
+	}
+}
+
+func TestUpdateProjectRetriesTooManyRequests(t *testing.T) {
+	env := newTestEnv(t, nil)
+	env.jira.Inject(fakejira.EndpointSearch, fakejira.TooManyRequests(time.Second))
+
+	started := time.Now()
+	if err := env.sync(t, service.SyncOptions{Full: true}, "DEMO"); err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+
+	if elapsed := time.Since(started); elapsed < time.Second {
+		t.Errorf("sync took %s, Retry-After was not honored", elapsed)
+	}
+	if n := env.jira.Requests(fakejira.EndpointSearch); n != 5 {
+		t.Errorf("search requests = %d, want 5", n)
+	}
+	if metrics := env.etl.RateLimiterMetrics(); metrics.Throttled != 1 {
+		t.Errorf("throttled = %d, want 1", metrics.Throttled)
+	}
+	if keys := env.store.issueKeys(); len(keys) != fixtureIssues {
+		t.Errorf("issues = %v, want %d", keys, fixtureIssues)
+	}
+}
+
+func TestUpdateProjectRetriesServerErrors(t *testing.T) {
+	env := newTestEnv(t, nil)
+	env.jira.Inject(fakejira.EndpointSearch,
+		fakejira.ServerError(http.StatusServiceUnavailable),
+		fakejira.ServerError(http.StatusBadGateway),
+	)
+
+	if err := env.sync(t, service.SyncOptions{Full: true}, "DEMO"); err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+	if keys := env.store.issueKeys(); len(keys) != fixtureIssues {
+		t.Errorf("issues = %v, want %d", keys, fixtureIssues)
+	}
+}
+
+func TestUpdateProjectFailsAfterMaxAttempts(t *testing.T) {
+	env := newTestEnv(t, nil)
+	// Первая попытка и три повтора
+	for i := 0; i < 4; i++ {
+		env.jira.Inject(fakejira.EndpointProjects, fakejira.ServerError(http.StatusInternalServerError))
+	}
+	progress := &recordingProgress{}
+
+	err := env.sync(t, service.SyncOptions{Full: true, Progress: progress}, "DEMO")
+	if err == nil {
+		t.Fatal("sync succeeded, want error")
+	}
+
+	var reqErr *jira.RequestError
+	if !errors.As(err, &reqErr) || reqErr.StatusCode != http.StatusInternalServerError || reqErr.Kind != jira.ErrorRetryable {
+		t.Errorf("error = %v, want retryable 500 RequestError", err)
+	}
+	if n := env.jira.Requests(fakejira.EndpointProjects); n != 4 {
+		t.Errorf("project requests = %d, want 4", n)
+	}
+	if len(progress.failed) != 1 {
+		t.Errorf("failures reported = %d, want 1", len(progress.failed))
+	}
+	if exists, _ := env.store.CheckProjectExists(context.Background(), "DEMO"); exists {
+		t.Error("project saved after failed sync")
+	}
+}
+
+func TestUpdateProjectRetriesSlowResponse(t *testing.T) {
+	env := newTestEnv(t, func(cfg *jira.ClientConfig) {
+		cfg.Timeout = 200 * time.Millisecond
+	})
+	env.jira.Inject(fakejira.EndpointSearch, fakejira.Slow(time.Second))
+
+	if err := env.sync(t, service.SyncOptions{Full: true}, "DEMO"); err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+	if n := env.jira.Requests(fakejira.EndpointSearch); n != 5 {
+		t.Errorf("search requests = %d, want 5", n)
+	}
+	if keys := env.store.issueKeys(); len(keys) != fixtureIssues {
+		t.Errorf("issues = %v, want %d", keys, fixtureIssues)
+	}
+}
+
+func TestUpdateProjectMalformedJSON(t *testing.T) {
+	env := newTestEnv(t, nil)
+	// Число задач приходит целым, первая страница - оборванной
+	env.jira.Inject(fakejira.EndpointSearch, fakejira.Fault{}, fakejira.MalformedJSON())
+
+	err := env.sync(t, service.SyncOptions{Full: true}, "DEMO")
+	if err == nil {
+		t.Fatal("sync succeeded, want decode error")
+	}
+	if !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("error = %v, want JSON decode error", err)
+	}
+	if !jira.IsKind(err, jira.ErrorMalformed) {
+		t.Errorf("error = %v, want RequestError of kind %s", err, jira.ErrorMalformed)
+	}
+
+	if watermark, _ := env.store.GetSyncWatermark(context.Background(), "DEMO"); watermark != nil {
+		t.Errorf("watermark saved after failed sync: %v", watermark)
+	}
+	if keys := env.store.issueKeys(); len(keys) == fixtureIssues {
+		t.Error("all issues saved despite a malformed page")
+	}
+}
+
+func TestUpdateProjectWithoutAgileAPI(t *testing.T) {
+	env := newTestEnv(t, nil)
+	// Jira без Jira Software отвечает 404 на запросы Agile API
+	env.jira.Inject(fakejira.EndpointBoards, fakejira.Fault{Status: http.StatusNotFound})
+
+	if err := env.sync(t, service.SyncOptions{Full: true}, "DEMO"); err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+	if keys := env.store.issueKeys(); len(keys) != fixtureIssues {
+		t.Errorf("issues = %v, want %d", keys, fixtureIssues)
+	}
+	if watermark, _ := env.store.GetSyncWatermark(context.Background(), "DEMO"); watermark == nil {
+		t.Error("watermark is not saved")
+	}
+}
+
+// demoXMLExport - XML-выгрузка DEMO-4: без списаний, со связью без
+// идентификатора Jira.
+const demoXMLExport = `<?xml version="1.0" encoding="UTF-8"?>
+<rss version="0.92"><channel>
+    <item>
+        <project key="DEMO">Demo</project>
+        <key>DEMO-4</key>
+        <summary>Bug number 4</summary>
+        <type>Bug</type>
+        <status>Open</status>
+        <reporter username="apetrova">Anna Petrova</reporter>
+        <created>Mon, 4 Mar 2024 10:00:00 +0000</created>
+        <updated>Mon, 4 Mar 2024 12:00:00 +0000</updated>
+        <issuelinks>
+            <issuelinktype id="10000">
+                <name>Blocks</name>
+                <outwardlinks description="blocks">
+                    <issuelink><issuekey>DEMO-3</issuekey></issuelink>
+                </outwardlinks>
+            </issuelinktype>
+        </issuelinks>
+    </item>
+</channel></rss>`
+
+func TestImportKeepsWorklogsAndLinksMissingFromExport(t *testing.T) {
+	env := newTestEnv(t, nil)
+	if err := env.sync(t, service.SyncOptions{Full: true}, "DEMO"); err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+	if env.store.count("worklogs", "DEMO-2") != 22 || env.store.count("links", "DEMO-4") != 1 {
+		t.Fatalf("online sync saved %d worklogs of DEMO-2 and %d links of DEMO-4, want 22 and 1",
+			env.store.count("worklogs", "DEMO-2"), env.store.count("links", "DEMO-4"))
+	}
+
+	// В JSON-выгрузке у DEMO-2 только первая страница списаний
+	demo2 := fixtureIssue(t, "DEMO-2")
+	var fields map[string]json.RawMessage
+	json.Unmarshal(demo2["fields"], &fields)
+	var worklog map[string]json.RawMessage
+	json.Unmarshal(fields["worklog"], &worklog)
+	var worklogs []json.RawMessage
+	json.Unmarshal(worklog["worklogs"], &worklogs)
+	worklog["worklogs"], _ = json.Marshal(worklogs[:20])
+	worklog["maxResults"] = json.RawMessage("20")
+	fields["worklog"], _ = json.Marshal(worklog)
+	demo2["fields"], _ = json.Marshal(fields)
+	page, _ := json.Marshal(map[string]interface{}{"startAt": 0, "maxResults": 50, "total": 1, "issues": []interface{}{demo2}})
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "page.json"), page, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "demo.xml"), []byte(demoXMLExport), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	export, err := offline.Load(dir, batchSize)
+	if err != nil {
+		t.Fatalf("load export: %v", err)
+	}
+
+	repo := &repository.Repository{JiraDB: env.store, Tracker: export}
+	etl := service.NewETLService(repo, 1, batchSize, service.CustomFieldsConfig{})
+	if err := etl.UpdateProject(context.Background(), []string{"DEMO"}, service.SyncOptions{Full: true, Offline: true}); err != nil {
+		t.Fatalf("import: %v", err)
+	}
+
+	if n := env.store.count("worklogs", "DEMO-2"); n != 22 {
+		t.Errorf("DEMO-2 has %d worklogs after import, want 22", n)
+	}
+	// Связь из Jira остаётся, связь из XML добавляется
+	if n := env.store.count("links", "DEMO-4"); n != 2 {
+		t.Errorf("DEMO-4 has %d links after import, want 2", n)
+	}
+}
+
+// serverXMLExport - XML-выгрузка Jira Server: у автора есть key, у
+// исполнителя только username, история изменений не выгружается.
+const serverXMLExport = `<?xml version="1.0" encoding="UTF-8"?>
+<rss version="0.92"><channel>
+    <item>
+        <project key="DEMO">Demo</project>
+        <key>DEMO-4</key>
+        <summary>Bug number 4</summary>
+        <type>Bug</type>
+        <status>Closed</status>
+        <reporter key="JIRAUSER10100" username="apetrova">Anna Petrova</reporter>
+        <assignee username="isidorov">Ivan Sidorov</assignee>
+        <created>Mon, 4 Mar 2024 10:00:00 +0000</created>
+        <updated>Mon, 4 Mar 2024 12:00:00 +0000</updated>
+        <resolved>Mon, 4 Mar 2024 11:30:00 +0000</resolved>
+        <comments>
+            <comment id="900" author="apetrova" created="Mon, 4 Mar 2024 11:00:00 +0000">Fixed</comment>
+        </comments>
+    </item>
+</channel></rss>`
+
+func TestImportXMLUsesUserKeysAndResolvedDate(t *testing.T) {
+	env := newTestEnv(t, nil)
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "demo.xml"), []byte(serverXMLExport), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	export, err := offline.Load(dir, batchSize)
+	if err != nil {
+		t.Fatalf("load export: %v", err)
+	}
+
+	repo := &repository.Repository{JiraDB: env.store, Tracker: export}
+	etl := service.NewETLService(repo, 1, batchSize, service.CustomFieldsConfig{})
+	if err := etl.UpdateProject(context.Background(), []string{"DEMO"}, service.SyncOptions{Full: true, Offline: true}); err != nil {
+		t.Fatalf("import: %v", err)
+	}
+
+	issue, ok := env.store.issue("DEMO-4")
+	if !ok {
+		t.Fatal("DEMO-4 is not imported")
+	}
+	wantClosed := time.Date(2024, 3, 4, 11, 30, 0, 0, time.UTC)
+	if issue.Closed == nil || !issue.Closed.Equal(wantClosed) {
+		t.Errorf("closed = %v, want %v", issue.Closed, wantClosed)
+	}
+
+	// Онлайн-синхронизация Jira Server идентифицирует пользователя по key,
+	// username остаётся, только если key не выгружен. Автор комментария
+	// указан по username и сводится к тому же пользователю.
+	env.store.mu.Lock()
+	defer env.store.mu.Unlock()
+	for _, id := range []string{"JIRAUSER10100", "isidorov"} {
+		if _, ok := env.store.authors[id]; !ok {
+			t.Errorf("author %s is not saved", id)
+		}
+	}
+	if _, ok := env.store.authors["apetrova"]; ok {
+		t.Error("reporter saved by username despite exported key")
+	}
+}
+
+func TestUpdateProjectReconcilesDeletedAndMovedIssues(t *testing.T) {
+	env := newTestEnv(t, nil)
+	if err := env.sync(t, service.SyncOptions{Full: true}, "DEMO", "OPS"); err != nil {
+		t.Fatalf("first sync: %v", err)
+	}
+
+	env.jira.DeleteIssue("DEMO-7")
+	if err := env.jira.MoveIssue("DEMO-6", "OPS-2"); err != nil {
+		t.Fatalf("move issue: %v", err)
+	}
+
+	progress := &recordingProgress{}
+	if err := env.sync(t, service.SyncOptions{Full: true, Progress: progress}, "DEMO"); err != nil {
+		t.Fatalf("second sync: %v", err)
+	}
+
+	if progress.reconciled != (service.ReconcileResult{Deleted: 1, Moved: 1}) {
+		t.Errorf("reconciled = %+v, want 1 deleted, 1 moved", progress.reconciled)
+	}
+	if issue, ok := env.store.issue("DEMO-7"); !ok || !issue.Deleted {
+		t.Errorf("DEMO-7 = %+v, want marked deleted", issue)
+	}
+	if _, ok := env.store.issue("DEMO-6"); ok {
+		t.Error("DEMO-6 still stored under the old key")
+	}
+	if issue, ok := env.store.issue("OPS-2"); !ok || issue.ProjectKey != "OPS" {
+		t.Errorf("OPS-2 = %+v, want issue of project OPS", issue)
+	}
+}
+
+func TestUpdateProjectReconcileLoadsSkippedIssues(t *testing.T) {
+	env := newTestEnv(t, nil)
+	if err := env.sync(t, service.SyncOptions{Full: true}, "DEMO"); err != nil {
+		t.Fatalf("first sync: %v", err)
+	}
+
+	// Jira урезает страницы до двух задач: батчи по три задачи пропускают
+	// DEMO-3 и DEMO-6, изменённые после первой синхронизации
+	env.jira.SetMaxResults(2)
+	putBrokenIssue(t, env, "DEMO-3", map[string]interface{}{"summary": "Changed DEMO-3"})
+	putBrokenIssue(t, env, "DEMO-6", map[string]interface{}{"summary": "Changed DEMO-6"})
+
+	progress := &recordingProgress{}
+	if err := env.sync(t, service.SyncOptions{Full: true, Progress: progress}, "DEMO"); err != nil {
+		t.Fatalf("second sync: %v", err)
+	}
+
+	if progress.reconciled != (service.ReconcileResult{Loaded: 2}) {
+		t.Errorf("reconciled = %+v, want 2 loaded", progress.reconciled)
+	}
+	for _, key := range []string{"DEMO-3", "DEMO-6"} {
+		if issue, ok := env.store.issue(key); !ok || issue.Deleted || issue.Summary != "Changed "+key {
+			t.Errorf("%s = %+v, want reloaded issue", key, issue)
+		}
+	}
+}
+
+func TestUpdateProjectReplaysRecordedCassette(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("FAKEJIRA_TOKEN", "s3cr3t-token")
+
+	recording := newTestEnv(t, func(cfg *jira.ClientConfig) {
+		cfg.Auth = jira.AuthConfig{Type: jira.AuthBearer, Token: jira.Secret{Env: "FAKEJIRA_TOKEN"}}
+		cfg.Cassette = jira.CassetteConfig{Mode: jira.CassetteRecord, Dir: dir}
+	})
+	recording.jira.Inject(fakejira.EndpointSearch, fakejira.Fault{}, fakejira.ServerError(http.StatusBadGateway))
+	if err := recording.sync(t, service.SyncOptions{Full: true}, "DEMO"); err != nil {
+		t.Fatalf("recorded sync: %v", err)
+	}
+	recording.jira.Close()
+
+	files, _ := filepath.Glob(filepath.Join(dir, "*"))
+	for _, file := range files {
+		data, _ := os.ReadFile(file)
+		if strings.Contains(string(data), "s3cr3t-token") {
+			t.Errorf("%s contains the auth token", filepath.Base(file))
+		}
+	}
+
+	// Jira недоступна, ответы берутся из кассеты
+	replaying := newTestEnv(t, func(cfg *jira.ClientConfig) {
+		cfg.JiraUrl = "http://127.0.0.1:1"
+		cfg.Cassette = jira.CassetteConfig{Mode: jira.CassetteReplay, Dir: dir}
+	})
+	if err := replaying.sync(t, service.SyncOptions{Full: true}, "DEMO"); err != nil {
+		t.Fatalf("replayed sync: %v", err)
+	}
+
+	if got, want := replaying.store.issueKeys(), recording.store.issueKeys(); strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("replayed issues = %v, recorded %v", got, want)
+	}
+	if n := replaying.store.count("worklogs", "DEMO-2"); n != 22 {
+		t.Errorf("DEMO-2 worklogs = %d, want 22", n)
+	}
+	if n := replaying.jira.Requests(fakejira.EndpointSearch); n != 0 {
+		t.Errorf("replay reached the server: %d search requests", n)
+	}
+
+	// Запросов сверх записанных в кассете нет
+	err := replaying.sync(t, service.SyncOptions{Full: true}, "DEMO")
+	if !errors.Is(err, jira.ErrNotRecorded) || !jira.IsKind(err, jira.ErrorPermanent) {
+		t.Errorf("error = %v, want permanent ErrNotRecorded", err)
+	}
+}
+
+func TestUpdateProjectDryRunWritesNothing(t *testing.T) {
+	env := newTestEnv(t, nil)
+	report := service.NewDryRunReport()
+
+	if err := env.sync(t, service.SyncOptions{Full: true, DryRun: report}, "DEMO"); err != nil {
+		t.Fatalf("dry run: %v", err)
+	}
+
+	if keys := env.store.issueKeys(); len(keys) != 0 {
+		t.Errorf("dry run saved issues %v", keys)
+	}
+	if exists, _ := env.store.CheckProjectExists(context.Background(), "DEMO"); exists {
+		t.Error("dry run saved project")
+	}
+	if watermark, _ := env.store.GetSyncWatermark(context.Background(), "DEMO"); watermark != nil {
+		t.Error("dry run saved watermark")
+	}
+
+	project, ok := report.Project("DEMO")
+	if !ok {
+		t.Fatal("report has no DEMO project")
+	}
+	if !project.NewProject || len(project.NewIssues) != fixtureIssues {
+		t.Errorf("new project = %v, new issues = %v, want new project with %d issues", project.NewProject, project.NewIssues, fixtureIssues)
+	}
+	if !hasAuthor(report.NewAuthors(), "Anna Petrova") {
+		t.Errorf("new authors = %+v, want Anna Petrova", report.NewAuthors())
+	}
+
+	// Отчёт совпадает с тем, что записывает настоящая синхронизация
+	if err := env.sync(t, service.SyncOptions{Full: true}, "DEMO"); err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+	saved := 0
+	for _, key := range env.store.issueKeys() {
+		saved += env.store.count("changelogs", key)
+	}
+	if saved == 0 || len(project.NewStatusChanges) != saved {
+		t.Errorf("new status changes = %d, sync saved %d", len(project.NewStatusChanges), saved)
+	}
+	authors, _ := env.store.GetAuthors(context.Background())
+	if len(report.NewAuthors()) != len(authors) {
+		t.Errorf("new authors = %d, sync saved %d", len(report.NewAuthors()), len(authors))
+	}
+}
+
+func TestUpdateProjectDryRunReportsChanges(t *testing.T) {
+	env := newTestEnv(t, nil)
+	if err := env.sync(t, service.SyncOptions{Full: true}, "DEMO"); err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+
+	unchanged := service.NewDryRunReport()
+	if err := env.sync(t, service.SyncOptions{Full: true, DryRun: unchanged}, "DEMO"); err != nil {
+		t.Fatalf("dry run: %v", err)
+	}
+	if project, _ := unchanged.Project("DEMO"); project.NewProject || len(project.UnchangedIssues) != fixtureIssues ||
+		len(project.NewStatusChanges) != 0 || len(project.NewFieldChanges) != 0 || len(unchanged.NewAuthors()) != 0 {
+		t.Errorf("report of synced project = %+v, authors %+v, want everything unchanged", project, unchanged.NewAuthors())
+	}
+
+	// DEMO-1 переходит в работу к новому исполнителю
+	issue := fixtureIssue(t, "DEMO-1")
+	var fields map[string]interface{}
+	json.Unmarshal(issue["fields"], &fields)
+	newcomer := map[string]interface{}{"accountId": "5b10ac8d82e05b22cc7d4f00", "displayName": "Olga Smirnova", "active": true}
+	fields["updated"] = "2024-03-05T10:00:00.000+0000"
+	fields["summary"] = "Bug number 1 (reproduced)"
+	fields["status"] = map[string]string{"name": "In Progress"}
+	fields["assignee"] = newcomer
+	issue["fields"], _ = json.Marshal(fields)
+	issue["changelog"], _ = json.Marshal(map[string]interface{}{
+		"histories": []interface{}{map[string]interface{}{
+			"id":      "101",
+			"author":  newcomer,
+			"created": "2024-03-05T10:00:00.000+0000",
+			"items":   []interface{}{map[string]interface{}{"field": "status", "fieldtype": "jira", "fromString": "Open", "toString": "In Progress"}},
+		}},
+	})
+	raw, _ := json.Marshal(issue)
+	if err := env.jira.PutIssue(raw); err != nil {
+		t.Fatalf("put issue: %v", err)
+	}
+
+	report := service.NewDryRunReport()
+	if err := env.sync(t, service.SyncOptions{Full: true, DryRun: report}, "DEMO"); err != nil {
+		t.Fatalf("dry run: %v", err)
+	}
+
+	project, _ := report.Project("DEMO")
+	if len(project.UpdatedIssues) != 1 || project.UpdatedIssues[0].Key != "DEMO-1" || len(project.UnchangedIssues) != fixtureIssues-1 {
+		t.Fatalf("updated = %+v, unchanged = %v, want only DEMO-1 updated", project.UpdatedIssues, project.UnchangedIssues)
+	}
+	changed := make(map[string]service.FieldDiff)
+	for _, diff := range project.UpdatedIssues[0].Fields {
+		changed[diff.Field] = diff
+	}
+	want := map[string][2]string{
+		"summary":  {"Bug number 1", "Bug number 1 (reproduced)"},
+		"status":   {"Open", "In Progress"},
+		"assignee": {"Ivan Sidorov", "Olga Smirnova"},
+		"updated":  {"2024-03-01 12:00:00", "2024-03-05 10:00:00"},
+	}
+	if len(changed) != len(want) {
+		t.Errorf("changed fields = %+v, want %v", project.UpdatedIssues[0].Fields, want)
+	}
+	for field, values := range want {
+		if diff := changed[field]; diff.Old != values[0] || diff.New != values[1] {
+			t.Errorf("%s diff = %+v, want %q -> %q", field, diff, values[0], values[1])
+		}
+	}
+
+	if len(project.NewStatusChanges) != 1 || project.NewStatusChanges[0].ToStatus != "In Progress" {
+		t.Errorf("new status changes = %+v, want transition to In Progress", project.NewStatusChanges)
+	}
+	if len(project.NewFieldChanges) != 1 || project.NewFieldChanges[0].HistoryID != "101" {
+		t.Errorf("new field changes = %+v, want history 101", project.NewFieldChanges)
+	}
+	if authors := report.NewAuthors(); len(authors) != 1 || authors[0].DisplayName != "Olga Smirnova" {
+		t.Errorf("new authors = %+v, want Olga Smirnova", authors)
+	}
+
+	if stored, _ := env.store.issue("DEMO-1"); stored.Summary != "Bug number 1" || env.store.count("changelogs", "DEMO-1") != 0 {
+		t.Errorf("dry run changed DEMO-1: %+v", stored)
+	}
+}
+
+func hasAuthor(authors []service.DryRunAuthor, name string) bool {
+	for _, a := range authors {
+		if a.DisplayName == name {
+			return true
+		}
+	}
+	return false
+}
+
+// fixtureIssue возвращает задачу фикстур по ключу.
+func fixtureIssue(t *testing.T, key string) map[string]json.RawMessage {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(fixturesDir, "issues", "DEMO.json"))
+	if err != nil {
+		t.Fatalf("read fixtures: %v", err)
+	}
+	var issues []map[string]json.RawMessage
+	if err := json.Unmarshal(data, &issues); err != nil {
+		t.Fatalf("parse fixtures: %v", err)
+	}
+	for _, issue := range issues {
+		var k string
+		json.Unmarshal(issue["key"], &k)
+		if k == key {
+			return issue
+		}
+	}
+	t.Fatalf("fixture %s not found", key)
+	return nil
+}
+
+// failThirdBatch загружает батчи в один поток и роняет загрузку третьего
+// батча вместе с повтором: запросы поиска - число задач, первый, второй
+// и третий батч.
+func failThirdBatch(t *testing.T) *testEnv {
+	env := newTestEnv(t, func(cfg *jira.ClientConfig) {
+		cfg.ThreadCount = 1
+		cfg.MaxAttempts = 1
+	})
+	env.jira.Inject(fakejira.EndpointSearch, fakejira.Fault{}, fakejira.Fault{}, fakejira.Fault{},
+		fakejira.ServerError(http.StatusInternalServerError), fakejira.ServerError(http.StatusInternalServerError))
+
+	if err := env.sync(t, service.SyncOptions{Full: true}, "DEMO"); err == nil {
+		t.Fatal("sync succeeded, want failure on the third batch")
+	}
+	return env
+}
+
+func TestUpdateProjectResumesFromCheckpoint(t *testing.T) {
+	env := failThirdBatch(t)
+	if keys := env.store.issueKeys(); len(keys) != 2*batchSize {
+		t.Fatalf("issues after failure = %v, want first two batches", keys)
+	}
+	if watermark, _ := env.store.GetSyncWatermark(context.Background(), "DEMO"); watermark != nil {
+		t.Error("failed sync saved watermark")
+	}
+
+	progress := &recordingProgress{}
+	if err := env.sync(t, service.SyncOptions{Full: true, Progress: progress}, "DEMO"); err != nil {
+		t.Fatalf("resumed sync: %v", err)
+	}
+
+	if keys := env.store.issueKeys(); len(keys) != fixtureIssues {
+		t.Fatalf("issues = %v, want %d", keys, fixtureIssues)
+	}
+	// Второй батч (DEMO-4..DEMO-6) загружается повторно для проверки
+	// сдвига, первый пропускается
+	if progress.loaded != 4 {
+		t.Errorf("resumed sync loaded %d issues, want 4", progress.loaded)
+	}
+	// Задачи первого прохода не считаются удалёнными
+	if progress.reconciled.Deleted != 0 {
+		t.Errorf("reconciled %+v, want nothing deleted", progress.reconciled)
+	}
+	if watermark, _ := env.store.GetSyncWatermark(context.Background(), "DEMO"); watermark == nil {
+		t.Error("watermark is not saved")
+	}
+	if run, _ := env.store.GetActiveSyncRun(context.Background(), "DEMO"); run != nil {
+		t.Errorf("sync run %d is still active", run.ID)
+	}
+}
+
+func TestUpdateProjectResumesAfterIssuesChanged(t *testing.T) {
+	env := failThirdBatch(t)
+
+	// Пока синхронизация стояла, задачу удалили и создали две новые:
+	// батчи первого прохода сдвинулись, и DEMO-7 оказалась во втором
+	env.jira.DeleteIssue("DEMO-2")
+	now := time.Now().UTC()
+	for _, key := range []string{"DEMO-8", "DEMO-9"} {
+		raw, _ := json.Marshal(map[string]interface{}{
+			"key": key,
+			"fields": map[string]interface{}{
+				"project":   map[string]string{"key": "DEMO"},
+				"created":   now.Format(models.JiraTimeLayout),
+				"updated":   now.Format(models.JiraTimeLayout),
+				"summary":   "Created while the sync was failing",
+				"issuetype": map[string]string{"name": "Task"},
+				"status":    map[string]string{"name": "Open"},
+				"creator":   map[string]interface{}{"accountId": "5b10a2844c20165700ede21g", "displayName": "Anna Petrova", "active": true},
+			},
+		})
+		if err := env.jira.PutIssue(raw); err != nil {
+			t.Fatalf("put issue: %v", err)
+		}
+	}
+
+	progress := &recordingProgress{}
+	if err := env.sync(t, service.SyncOptions{Full: true, Progress: progress}, "DEMO"); err != nil {
+		t.Fatalf("resumed sync: %v", err)
+	}
+
+	want := []string{"DEMO-1", "DEMO-2", "DEMO-3", "DEMO-4", "DEMO-5", "DEMO-6", "DEMO-7", "DEMO-8", "DEMO-9"}
+	if keys := env.store.issueKeys(); strings.Join(keys, ",") != strings.Join(want, ",") {
+		t.Errorf("issues = %v, want %v", keys, want)
+	}
+	// Повторно загруженный второй батч содержит DEMO-7, и выборка
+	// загружается с начала: 3 задачи проверки и 8 задач выборки
+	if progress.loaded != 11 {
+		t.Errorf("resumed sync loaded %d issues, want 11", progress.loaded)
+	}
+	if issue, ok := env.store.issue("DEMO-2"); !ok || !issue.Deleted {
+		t.Errorf("DEMO-2 = %+v, want marked deleted", issue)
+	}
+}
+
+// putBrokenIssue кладёт в fakejira задачу фикстур с изменёнными полями.
+func putBrokenIssue(t *testing.T, env *testEnv, key string, changes map[string]interface{}) {
+	t.Helper()
+	issue := fixtureIssue(t, key)
+	var fields map[string]interface{}
+	json.Unmarshal(issue["fields"], &fields)
+	for field, value := range changes {
+		fields[field] = value
+	}
+	issue["fields"], _ = json.Marshal(fields)
+	raw, _ := json.Marshal(issue)
+	if err := env.jira.PutIssue(raw); err != nil {
+		t.Fatalf("put issue: %v", err)
+	}
+}
+
+func TestUpdateProjectDeadLettersFailingIssues(t *testing.T) {
+	env := newTestEnv(t, nil)
+	// DEMO-2 не преобразуется, DEMO-4 не помещается в колонку summary
+	putBrokenIssue(t, env, "DEMO-2", map[string]interface{}{"created": "yesterday"})
+	putBrokenIssue(t, env, "DEMO-4", map[string]interface{}{"summary": strings.Repeat("long ", 60)})
+
+	for i := 0; i < 2; i++ {
+		if err := env.sync(t, service.SyncOptions{Full: true}, "DEMO"); err != nil {
+			t.Fatalf("sync %d: %v", i+1, err)
+		}
+	}
+
+	keys := env.store.issueKeys()
+	if len(keys) != fixtureIssues-2 {
+		t.Fatalf("issues = %v, want all but DEMO-2 and DEMO-4", keys)
+	}
+	for _, key := range []string{"DEMO-2", "DEMO-4"} {
+		if _, ok := env.store.issue(key); ok {
+			t.Errorf("%s is saved", key)
+		}
+	}
+
+	ctx := context.Background()
+	letters, err := env.etl.ListDeadLetters(ctx, "DEMO", false, 10)
+	if err != nil {
+		t.Fatalf("list dead letters: %v", err)
+	}
+	stages := make(map[string]models.DBDeadLetter)
+	for _, l := range letters {
+		stages[l.IssueKey] = l
+	}
+	if len(letters) != 2 || stages["DEMO-2"].Stage != models.DeadLetterTransform || stages["DEMO-4"].Stage != models.DeadLetterPersist {
+		t.Fatalf("dead letters = %+v, want DEMO-2 at transform and DEMO-4 at persist", letters)
+	}
+	for _, l := range letters {
+		// Повторная синхронизация обновляет открытую запись
+		if l.Attempts != 2 || l.SyncRunID == nil || l.Error == "" {
+			t.Errorf("dead letter %s: attempts %d, run %v, error %q, want 2 attempts with run and error", l.IssueKey, l.Attempts, l.SyncRunID, l.Error)
+		}
+		// Запись хранит задачу из ответа Jira, включая поля вне модели
+		var raw map[string]json.RawMessage
+		if err := json.Unmarshal(l.Raw, &raw); err != nil || string(raw["self"]) != string(fixtureIssue(t, l.IssueKey)["self"]) {
+			t.Errorf("dead letter %s raw = %s, want issue from the search page", l.IssueKey, l.Raw)
+		}
+	}
+
+	// Колонку расширили миграцией: DEMO-4 сохраняется из записи, DEMO-2
+	// по-прежнему не преобразуется
+	env.store.summaryLimit = 1024
+	result, err := env.etl.RetryDeadLetters(ctx, "DEMO")
+	if err != nil {
+		t.Fatalf("retry dead letters: %v", err)
+	}
+	if result != (service.DeadLetterRetryResult{Retried: 2, Resolved: 1, Failed: 1}) {
+		t.Errorf("retry result = %+v, want 2 retried, 1 resolved, 1 failed", result)
+	}
+	if issue, ok := env.store.issue("DEMO-4"); !ok || issue.Summary != strings.Repeat("long ", 60) {
+		t.Errorf("DEMO-4 = %+v, want saved from dead letter", issue)
+	}
+
+	letter, err := env.etl.RetryDeadLetter(ctx, stages["DEMO-4"].ID)
+	if err != nil || letter.ResolvedAt == nil {
+		t.Errorf("retry of resolved DEMO-4 = %+v, %v, want resolved letter", letter, err)
+	}
+	if _, err := env.etl.RetryDeadLetter(ctx, 100); !errors.Is(err, service.ErrDeadLetterNotFound) {
+		t.Errorf("retry of unknown letter: %v, want ErrDeadLetterNotFound", err)
+	}
+}
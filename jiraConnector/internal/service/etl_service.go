@@ -2,29 +2,87 @@ package service
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
 	"jiraAnalyzer/jiraConnector/internal/models"
 	"jiraAnalyzer/jiraConnector/internal/repository"
+	"jiraAnalyzer/jiraConnector/internal/repository/database"
+	"jiraAnalyzer/jiraConnector/internal/repository/jira"
 	"log"
 	"strings"
 	"sync"
 	"time"
 )
 
+// watermarkOverlap - запас, на который инкрементальная синхронизация
+// отступает назад от сохранённого watermark. JQL сравнивает даты с точностью
+// до минуты и в часовом поясе пользователя Jira, поэтому без запаса часть
+// изменений может быть пропущена. Повторная загрузка задач безопасна.
+const watermarkOverlap = 24 * time.Hour
+
+// SyncOptions задаёт режим синхронизации проектов.
+type SyncOptions struct {
+	// Full - загрузить все задачи проекта, игнорируя сохранённый watermark.
+	Full bool
+	// Progress получает отчёт о ходе синхронизации, может быть nil.
+	Progress SyncProgress
+	// Offline - задачи загружаются из сохранённой выгрузки. Выгрузка может
+	// быть неполной и старше последней синхронизации, поэтому задачи не
+	// сверяются с Jira, а watermark не сдвигается.
+	Offline bool
+	// DryRun - задачи загружаются и преобразуются, но ничего не
+	// записывается: изменения, которые внесла бы синхронизация,
+	// собираются в отчёт.
+	DryRun *DryRunReport
+}
+
+// SyncProgress получает отчёт о ходе синхронизации. Методы вызываются
+// конкурентно из горутин, загружающих проекты и батчи.
+type SyncProgress interface {
+	BatchesPlanned(projectKey string, batches int)
+	BatchLoaded(projectKey string, issues int)
+	ProjectFailed(projectKey string, err error)
+	IssuesReconciled(projectKey string, result ReconcileResult)
+}
+
+type noopProgress struct{}
+
+func (noopProgress) BatchesPlanned(string, int)               {}
+func (noopProgress) BatchLoaded(string, int)                  {}
+func (noopProgress) ProjectFailed(string, error)              {}
+func (noopProgress) IssuesReconciled(string, ReconcileResult) {}
+
+func (o SyncOptions) progress() SyncProgress {
+	if o.Progress == nil {
+		return noopProgress{}
+	}
+	return o.Progress
+}
+
 type ETLService struct {
 	repo              *repository.Repository
 	ThreadCount       int
 	IssueInOneRequest int
+	customFields      CustomFieldsConfig
+	authors           *authorCache
 }
 
-func NewETLService(repo *repository.Repository, threadCount int, issueInOneRequest int) *ETLService {
+func NewETLService(repo *repository.Repository, threadCount int, issueInOneRequest int, customFields CustomFieldsConfig) *ETLService {
 	return &ETLService{
 		repo:              repo,
 		ThreadCount:       threadCount,
 		IssueInOneRequest: issueInOneRequest,
+		customFields:      customFields,
+		authors:           newAuthorCache(),
 	}
 }
 
+// RateLimiterMetrics возвращает состояние лимитеров запросов к трекерам.
+func (s *ETLService) RateLimiterMetrics() models.RateLimiterMetrics {
+	return s.repo.RateLimiterMetrics()
+}
+
 func (s *ETLService) GetProjectsFromJira(ctx context.Context, page, limit int, search string) ([]models.DBProject, models.PageInfo, error) {
 	// Получаем все проекты из JiraDB
 	jiraProjects, err := s.repo.GetAllProjects(ctx)
@@ -65,7 +123,11 @@ func (s *ETLService) GetProjectsFromJira(ctx context.Context, page, limit int, s
 	return paginatedProjects, pageInfo, nil
 }
 
-func (s *ETLService) UpdateProject(ctx context.Context, projectKeys []string) error {
+func (s *ETLService) UpdateProject(ctx context.Context, projectKeys []string, opts SyncOptions) error {
+	if err := s.authors.warm(ctx, s.repo.GetAuthors); err != nil {
+		return fmt.Errorf("failed to load authors: %w", err)
+	}
+
 	var wg sync.WaitGroup
 	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
@@ -76,9 +138,15 @@ func (s *ETLService) UpdateProject(ctx context.Context, projectKeys []string) er
 		wg.Add(1)
 		go func(key string) {
 			defer wg.Done()
-			if err := s.updateSingleProject(ctx, key); err != nil {
+			if err := s.updateSingleProject(ctx, key, opts); err != nil {
+				syncErr := newProjectSyncError(key, err)
+				if !errors.Is(err, context.Canceled) {
+					log.Printf("Project %s sync failed: %v", key, syncErr)
+					opts.progress().ProjectFailed(key, syncErr)
+				}
+
 				select {
-				case errChan <- fmt.Errorf("failed to update project %s: %w", key, err):
+				case errChan <- fmt.Errorf("failed to update project %s: %w", key, syncErr):
 				default:
 				}
 				cancel() // Прерываем все горутины при ошибке
@@ -86,20 +154,21 @@ func (s *ETLService) UpdateProject(ctx context.Context, projectKeys []string) er
 		}(projectKey)
 	}
 
-	go func() {
-		wg.Wait()
-		close(errChan)
-	}()
+	// Дожидаемся всех горутин, чтобы после возврата никто не продолжал писать в БД
+	wg.Wait()
+	close(errChan)
 
-	select {
-	case err := <-errChan:
+	if err := <-errChan; err != nil {
 		return err
-	case <-ctx.Done():
-		return ctx.Err()
 	}
+	return ctx.Err()
 }
 
-func (s *ETLService) updateSingleProject(ctx context.Context, projectKey string) error {
+func (s *ETLService) updateSingleProject(ctx context.Context, projectKey string, opts SyncOptions) error {
+	// Watermark фиксируем до начала загрузки, чтобы не потерять изменения,
+	// сделанные в Jira во время синхронизации
+	startedAt := time.Now().UTC()
+
 	exists, err := s.repo.CheckProjectExists(ctx, projectKey)
 	if err != nil {
 		return fmt.Errorf("failed to check project existence: %w", err)
@@ -118,30 +187,146 @@ func (s *ETLService) updateSingleProject(ctx context.Context, projectKey string)
 			return fmt.Errorf("failed to transform project: %w", err)
 		}
 
-		if err := s.repo.SaveProject(ctx, project); err != nil {
+		if opts.DryRun != nil {
+			opts.DryRun.markNewProject(projectKey)
+		} else if err := s.repo.SaveProject(ctx, project); err != nil {
 			return fmt.Errorf("failed to save project: %w", err)
 		}
 	}
 
+	// Пробная синхронизация и загрузка из выгрузки ничего не продолжают
+	checkpointed := opts.DryRun == nil && !opts.Offline
+
+	var run *syncRun
+	if checkpointed {
+		if run, err = s.resumeSyncRun(ctx, projectKey, opts.Full); err != nil {
+			return fmt.Errorf("failed to resume sync run: %w", err)
+		}
+	}
+
+	var updatedSince *time.Time
+	if run != nil {
+		// Прерванная синхронизация продолжается в своём режиме и сохранит
+		// свой watermark
+		opts.Full = run.Full
+		startedAt = run.StartedAt
+		updatedSince = run.UpdatedSince
+	} else if !opts.Full {
+		watermark, err := s.repo.GetSyncWatermark(ctx, projectKey)
+		if err != nil {
+			return fmt.Errorf("failed to get sync watermark: %w", err)
+		}
+
+		if watermark != nil {
+			since := watermark.Add(-watermarkOverlap)
+			updatedSince = &since
+		}
+	}
+
+	if checkpointed && run == nil {
+		if run, err = s.createSyncRun(ctx, projectKey, opts.Full, updatedSince, startedAt); err != nil {
+			return fmt.Errorf("failed to create sync run: %w", err)
+		}
+	}
+
 	// Загружаем issues с адаптивной обработкой рейт-лимитов
-	log.Printf("Loading issues for project %s...", projectKey)
-	return s.loadIssuesWithBackoff(ctx, projectKey)
+	if updatedSince != nil {
+		log.Printf("Loading issues for project %s updated since %s...", projectKey, updatedSince.Format(time.RFC3339))
+	} else {
+		log.Printf("Loading all issues for project %s...", projectKey)
+	}
+
+	seen, err := s.loadIssuesWithBackoff(ctx, projectKey, updatedSince, opts, run)
+	if err != nil {
+		return err
+	}
+	if run != nil {
+		for key := range run.seen {
+			seen[key] = true
+		}
+	}
+
+	// Пробная синхронизация не сверяет задачи, не загружает спринты и не
+	// сдвигает watermark
+	if opts.DryRun != nil {
+		return nil
+	}
+
+	// Удалённые и перенесённые задачи можно обнаружить только по полному
+	// списку задач проекта
+	if opts.Full && !opts.Offline {
+		result, err := s.reconcileIssues(ctx, projectKey, seen)
+		if err != nil {
+			return fmt.Errorf("failed to reconcile issues: %w", err)
+		}
+		opts.progress().IssuesReconciled(projectKey, result)
+	}
+
+	if err := s.syncSprints(ctx, projectKey, opts.Full); err != nil {
+		return fmt.Errorf("failed to sync sprints: %w", err)
+	}
+
+	if opts.Offline {
+		return nil
+	}
+
+	if err := s.repo.SaveSyncWatermark(ctx, projectKey, startedAt); err != nil {
+		return fmt.Errorf("failed to save sync watermark: %w", err)
+	}
+
+	return s.finishSyncRun(ctx, run)
 }
 
-func (s *ETLService) loadIssuesWithBackoff(ctx context.Context, projectKey string) error {
+// loadIssuesWithBackoff загружает задачи проекта и возвращает ключи
+// всех загруженных задач. Батчи отмечаются в синхронизации run, если
+// она задана.
+func (s *ETLService) loadIssuesWithBackoff(ctx context.Context, projectKey string, updatedSince *time.Time, opts SyncOptions, run *syncRun) (map[string]bool, error) {
+	progress := opts.progress()
 	sem := make(chan struct{}, s.ThreadCount) // Semaphore to limit goroutines
 	var wg sync.WaitGroup
 	errChan := make(chan error, 1)
 	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
 
-	totalIssues := s.getIssueCount(projectKey)
+	var seenMu sync.Mutex
+	seen := make(map[string]bool)
+
+	totalIssues, err := s.getIssueCount(ctx, projectKey, updatedSince)
+	if err != nil {
+		return nil, err
+	}
+
 	if totalIssues == 0 {
 		log.Printf("No issues found for project %s", projectKey)
-		return nil
+		return seen, nil
+	}
+
+	// Продолженная синхронизация повторно загружает последний сохранённый
+	// батч и по нему решает, можно ли пропустить батчи перед ним
+	start := 0
+	if resumeAt := run.resumeOffset(); resumeAt > 0 {
+		lastAt := resumeAt - s.IssueInOneRequest
+		progress.BatchesPlanned(projectKey, 1)
+		keys, err := s.loadIssuesBatch(ctx, projectKey, updatedSince, lastAt, opts.DryRun, run.checkpoint(lastAt))
+		if err != nil {
+			return nil, fmt.Errorf("failed to load batch starting at %d: %w", lastAt, err)
+		}
+		for _, key := range keys {
+			seen[key] = true
+		}
+		progress.BatchLoaded(projectKey, len(keys))
+
+		if run.unshifted(keys) {
+			start = resumeAt
+		} else {
+			log.Printf("Issues of project %s shifted since the previous pass, loading from the beginning", projectKey)
+			run.restart()
+		}
 	}
 
-	batches := (totalIssues + s.IssueInOneRequest - 1) / s.IssueInOneRequest
+	batches := max(totalIssues-start+s.IssueInOneRequest-1, 0) / s.IssueInOneRequest
+	progress.BatchesPlanned(projectKey, batches)
+
 	for i := 0; i < batches; i++ {
 		if ctx.Err() != nil {
 			break
@@ -155,83 +340,468 @@ func (s *ETLService) loadIssuesWithBackoff(ctx context.Context, projectKey strin
 				<-sem
 			}()
 
-			if err := s.loadIssuesBatch(ctx, projectKey, startAt); err != nil {
+			keys, err := s.loadIssuesBatch(ctx, projectKey, updatedSince, startAt, opts.DryRun, run.checkpoint(startAt))
+			if err != nil {
 				select {
-				case errChan <- fmt.Errorf("failed to load batch: %w", err):
+				case errChan <- fmt.Errorf("failed to load batch starting at %d: %w", startAt, err):
 				default:
 				}
 
 				cancel() // Прерываем все горутины при ошибке
+				return
+			}
+
+			seenMu.Lock()
+			for _, key := range keys {
+				seen[key] = true
 			}
-		}(i * s.IssueInOneRequest)
+			seenMu.Unlock()
+
+			progress.BatchLoaded(projectKey, len(keys))
+		}(start + i*s.IssueInOneRequest)
 	}
 
-	go func() {
-		wg.Wait()
-		close(errChan)
-	}()
+	wg.Wait()
+	close(errChan)
 
-	select {
-	case err := <-errChan:
-		return err
-	case <-ctx.Done():
-		return ctx.Err()
+	if err := <-errChan; err != nil {
+		return nil, err
 	}
+	return seen, ctx.Err()
 }
 
-func (s *ETLService) loadIssuesBatch(ctx context.Context, projectKey string, startAt int) error {
+// loadIssuesBatch загружает и сохраняет одну страницу задач, возвращает
+// ключи сохранённых задач. При пробной синхронизации страница не
+// сохраняется, а сравнивается с базой.
+func (s *ETLService) loadIssuesBatch(ctx context.Context, projectKey string, updatedSince *time.Time, startAt int, dryRun *DryRunReport, checkpoint *models.DBSyncCheckpoint) ([]string, error) {
 	log.Printf("Loading batch for project %s starting at %d", projectKey, startAt)
 
-	issues, err := s.repo.GetProjectIssues(ctx, projectKey, startAt)
+	issues, err := s.repo.GetProjectIssues(ctx, projectKey, updatedSince, startAt)
 	if err != nil {
-		return fmt.Errorf("failed to get project issues: %w", err)
+		return nil, fmt.Errorf("failed to get project issues: %w", err)
 	}
 	log.Printf("Fetched %d issues for project %s starting at %d", len(issues), projectKey, startAt)
 
+	if err := s.completeWorklogs(ctx, issues); err != nil {
+		return nil, err
+	}
+
+	if dryRun != nil {
+		return s.previewIssues(ctx, projectKey, issues, dryRun)
+	}
+	return s.saveIssues(ctx, projectKey, issues, false, checkpoint)
+}
+
+// completeWorklogs дозагружает списания времени задач: поиск возвращает
+// только первые из них. Вызывается до открытия транзакции.
+func (s *ETLService) completeWorklogs(ctx context.Context, issues []models.JiraIssue) error {
+	for i := range issues {
+		page := issues[i].Fields.Worklog
+		if page == nil || !page.Truncated() {
+			continue
+		}
+
+		worklogs, err := s.repo.GetIssueWorklogs(ctx, issues[i].Key)
+		if err != nil {
+			return fmt.Errorf("failed to get issue worklogs: %w", err)
+		}
+		// Выгрузка не может дозагрузить списания, и список остаётся обрезанным
+		issues[i].Fields.Worklog = &models.JiraWorklogs{
+			MaxResults: len(worklogs),
+			Total:      max(page.Total, len(worklogs)),
+			Worklogs:   worklogs,
+		}
+	}
+	return nil
+}
+
+// saveComment сохраняет комментарий загруженной задачи вместе с его автором.
+func (s *ETLService) saveComment(ctx context.Context, issueKey string, comment models.JiraComment) error {
 	tx, err := s.repo.BeginTx(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to begin transaction: %w", err)
 	}
 	defer tx.Rollback()
 
-	dbIssues := make([]models.DBIssue, len(issues))
-	dbChangelogs := make([]models.DBChangelog, 0)
+	author := transformAuthor(comment.Author)
+	authors, savedAuthors, err := s.authors.resolve(tx, map[string]models.DBAuthor{author.AccountID: author}, s.repo.SaveAuthorsTx)
+	if err != nil {
+		return fmt.Errorf("failed to save authors: %w", err)
+	}
 
-	for i, issue := range issues {
-		log.Printf("Transforming issue: %s", issue.Key)
+	dbComment, err := transformComment(issueKey, comment, authors)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.SaveCommentsTx(tx, []models.DBComment{dbComment}); err != nil {
+		return fmt.Errorf("failed to save comment: %w", err)
+	}
 
-		dbIssues[i], err = s.transformIssue(issue, projectKey)
-		if err != nil {
-			return fmt.Errorf("failed to transform issue: %w", err)
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit comment: %w", err)
+	}
+	s.authors.store(savedAuthors)
+
+	return nil
+}
+
+// syncSprints загружает спринты scrum-досок проекта и их состав. Состав
+// спринтов, закрытых ещё до прошлой синхронизации, повторно загружается
+// только при полной синхронизации.
+func (s *ETLService) syncSprints(ctx context.Context, projectKey string, full bool) error {
+	boards, err := s.repo.GetProjectBoards(ctx, projectKey)
+	if err != nil {
+		return err
+	}
+
+	closed, err := s.repo.GetClosedSprintIDs(ctx, projectKey)
+	if err != nil {
+		return err
+	}
+
+	// Спринт может быть виден на нескольких досках проекта
+	seen := make(map[int]bool)
+	for _, board := range boards {
+		if board.Type != jira.BoardTypeScrum {
+			continue
 		}
 
-		changelogs, err := s.extractChangelogs(issue)
+		sprints, err := s.repo.GetBoardSprints(ctx, board.ID)
 		if err != nil {
-			return fmt.Errorf("failed to extract changelogs: %w", err)
+			return err
 		}
-		dbChangelogs = append(dbChangelogs, changelogs...)
+
+		for _, sprint := range sprints {
+			if seen[sprint.ID] {
+				continue
+			}
+			seen[sprint.ID] = true
+
+			if closed[sprint.ID] && sprint.State == models.SprintStateClosed && !full {
+				continue
+			}
+
+			if err := s.saveSprint(ctx, projectKey, board.ID, sprint); err != nil {
+				return err
+			}
+		}
+	}
+
+	log.Printf("Synced %d sprints for project %s", len(seen), projectKey)
+	return nil
+}
+
+func (s *ETLService) saveSprint(ctx context.Context, projectKey string, boardID int, sprint models.JiraSprint) error {
+	issueKeys, err := s.repo.GetSprintIssueKeys(ctx, sprint.ID)
+	if err != nil {
+		return err
+	}
+
+	tx, err := s.repo.BeginTx(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
 	}
+	defer tx.Rollback()
 
-	if err := s.repo.SaveIssuesTx(tx, dbIssues); err != nil {
-		return fmt.Errorf("failed to save issues: %w", err)
+	if err := s.repo.SaveSprintTx(tx, s.transformSprint(sprint, projectKey, boardID)); err != nil {
+		return err
 	}
 
-	if err := s.repo.SaveChangelogTx(tx, dbChangelogs); err != nil {
-		return fmt.Errorf("failed to save changelogs: %w", err)
+	if err := s.repo.ReplaceSprintIssuesTx(tx, sprint.ID, issueKeys); err != nil {
+		return err
 	}
 
-	return tx.Commit()
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit sprint %d: %w", sprint.ID, err)
+	}
+	return nil
 }
 
-func (s *ETLService) getIssueCount(projectKey string) int {
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+func (s *ETLService) getIssueCount(ctx context.Context, projectKey string, updatedSince *time.Time) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
 	defer cancel()
 
-	count, err := s.repo.GetIssueCount(ctx, projectKey)
+	count, err := s.repo.GetIssueCount(ctx, projectKey, updatedSince)
 	if err != nil {
 		log.Printf("Failed to get issue count for project %s: %v", projectKey, err)
-		return 0
+		return 0, fmt.Errorf("failed to get issue count: %w", err)
+	}
+
+	return count, nil
+}
+
+// issueBatch - задачи батча и связанные записи в модели БД.
+type issueBatch struct {
+	issues       []models.DBIssue
+	changelogs   []models.DBChangelog
+	fieldChanges []models.DBFieldChange
+	comments     []models.DBComment
+	worklogs     []models.DBWorklog
+	links        []models.DBIssueLink
+	dimensions   []models.DBIssueDimensions
+	// Задачи, для которых получены все комментарии, списания и связи: у них
+	// можно удалить записи, удалённые в Jira
+	fullyCommentedKeys, commentIDs []string
+	fullyLoggedKeys, worklogIDs    []string
+	fullyLinkedKeys, linkIDs       []string
+	issueKeys                      []string
+}
+
+// transformIssues преобразует задачи батча в модель БД. Задачи, которые
+// не удалось преобразовать, не попадают в батч и возвращаются отдельно.
+func (s *ETLService) transformIssues(issues []models.JiraIssue, projectKey string, authors authorIDs) (*issueBatch, []issueFailure) {
+	batch := &issueBatch{
+		issues:       make([]models.DBIssue, 0, len(issues)),
+		changelogs:   make([]models.DBChangelog, 0),
+		fieldChanges: make([]models.DBFieldChange, 0),
+		comments:     make([]models.DBComment, 0),
+		worklogs:     make([]models.DBWorklog, 0),
+		links:        make([]models.DBIssueLink, 0),
+		dimensions:   make([]models.DBIssueDimensions, 0, len(issues)),
+	}
+
+	var failures []issueFailure
+	for _, issue := range issues {
+		log.Printf("Transforming issue: %s", issue.Key)
+
+		if err := s.addIssue(batch, issue, projectKey, authors); err != nil {
+			log.Printf("Failed to transform issue %s: %v", issue.Key, err)
+			failures = append(failures, issueFailure{issue: issue, stage: models.DeadLetterTransform, err: err})
+		}
+	}
+
+	return batch, failures
+}
+
+// addIssue добавляет задачу в батч. Батч меняется, только если задача
+// преобразована целиком.
+func (s *ETLService) addIssue(batch *issueBatch, issue models.JiraIssue, projectKey string, authors authorIDs) error {
+	dbIssue, err := s.transformIssue(issue, projectKey, authors)
+	if err != nil {
+		return fmt.Errorf("failed to transform issue: %w", err)
+	}
+	changelogs, fieldChanges, err := s.extractChangelogs(issue, authors)
+	if err != nil {
+		return err
+	}
+	comments, complete, err := s.extractComments(issue, authors)
+	if err != nil {
+		return err
+	}
+	worklogs, worklogsComplete, err := s.extractWorklogs(issue, authors)
+	if err != nil {
+		return err
+	}
+	links := s.extractIssueLinks(issue)
+
+	batch.issues = append(batch.issues, dbIssue)
+	batch.changelogs = append(batch.changelogs, changelogs...)
+	batch.fieldChanges = append(batch.fieldChanges, fieldChanges...)
+
+	batch.comments = append(batch.comments, comments...)
+	if complete {
+		batch.fullyCommentedKeys = append(batch.fullyCommentedKeys, issue.Key)
+		for _, c := range comments {
+			batch.commentIDs = append(batch.commentIDs, c.JiraID)
+		}
+	}
+
+	batch.worklogs = append(batch.worklogs, worklogs...)
+	batch.issueKeys = append(batch.issueKeys, issue.Key)
+	if worklogsComplete {
+		batch.fullyLoggedKeys = append(batch.fullyLoggedKeys, issue.Key)
+		for _, w := range worklogs {
+			batch.worklogIDs = append(batch.worklogIDs, w.JiraID)
+		}
+	}
+
+	batch.links = append(batch.links, links...)
+	if !issue.LinksPartial {
+		batch.fullyLinkedKeys = append(batch.fullyLinkedKeys, issue.Key)
+		for _, l := range links {
+			batch.linkIDs = append(batch.linkIDs, l.JiraID)
+		}
+	}
+
+	batch.dimensions = append(batch.dimensions, s.extractDimensions(issue, projectKey))
+	return nil
+}
+
+// Точки сохранения транзакции saveIssues
+const (
+	batchSavepoint = "etl_batch"
+	issueSavepoint = "etl_issue"
+)
+
+// saveIssues преобразует задачи и сохраняет их в одной транзакции. При
+// partial задачи могут содержать не все комментарии, списания и связи
+// (например, из webhook), поэтому отсутствующие в них записи не удаляются.
+// Если задан checkpoint, батч отмечается в синхронизации проекта.
+//
+// Задачи, которые не удалось преобразовать или которые отклонила база,
+// сохраняются в etl_dead_letters, а остальные задачи батча коммитятся.
+// Возвращаются ключи всех задач батча: задачи из etl_dead_letters есть
+// в Jira и не должны считаться удалёнными.
+func (s *ETLService) saveIssues(ctx context.Context, projectKey string, issues []models.JiraIssue, partial bool, checkpoint *models.DBSyncCheckpoint) ([]string, error) {
+	tx, err := s.repo.BeginTx(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	saved, savedAuthors, failures, err := s.writeIssues(ctx, tx, projectKey, issues, partial)
+	if err != nil {
+		return nil, err
+	}
+
+	savedKeys := make([]string, len(saved))
+	for i, issue := range saved {
+		savedKeys[i] = issue.Key
+	}
+	if err := s.repo.ResolveDeadLettersTx(tx, savedKeys); err != nil {
+		return nil, err
+	}
+	if err := s.saveDeadLetters(tx, projectKey, failures, partial, checkpoint); err != nil {
+		return nil, err
+	}
+
+	// Контрольная точка коммитится вместе с батчем. Смещения батчей
+	// считаются по всем задачам, включая отклонённые.
+	keys := issueKeys(issues)
+	if checkpoint != nil {
+		checkpoint.IssueKeys = keys
+		if err := s.repo.SaveSyncCheckpointTx(tx, *checkpoint); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit batch: %w", err)
+	}
+	s.authors.store(savedAuthors)
+
+	return keys, nil
+}
+
+// writeIssues пишет задачи в транзакцию. Сначала батч пишется целиком;
+// если база отклонила данные, батч откатывается и задачи пишутся по одной,
+// чтобы отделить отклонённые.
+func (s *ETLService) writeIssues(ctx context.Context, tx *sql.Tx, projectKey string, issues []models.JiraIssue, partial bool) ([]models.DBIssue, []models.DBAuthor, []issueFailure, error) {
+	if err := s.repo.SavepointTx(tx, batchSavepoint); err != nil {
+		return nil, nil, nil, err
+	}
+	saved, savedAuthors, failures, err := s.writeBatch(tx, projectKey, issues, partial)
+	if err == nil {
+		if err := s.repo.ReleaseSavepointTx(tx, batchSavepoint); err != nil {
+			return nil, nil, nil, err
+		}
+		return saved, savedAuthors, failures, nil
+	}
+	if !database.IsDataError(err) || ctx.Err() != nil {
+		return nil, nil, nil, err
+	}
+
+	log.Printf("Batch of project %s rejected, saving issues one by one: %v", projectKey, err)
+	if err := s.repo.RollbackToSavepointTx(tx, batchSavepoint); err != nil {
+		return nil, nil, nil, err
+	}
+
+	saved, savedAuthors, failures = nil, nil, nil
+	for _, issue := range issues {
+		if err := s.repo.SavepointTx(tx, issueSavepoint); err != nil {
+			return nil, nil, nil, err
+		}
+
+		issueSaved, issueAuthors, issueFailures, err := s.writeBatch(tx, projectKey, []models.JiraIssue{issue}, partial)
+		if err != nil {
+			if !database.IsDataError(err) {
+				return nil, nil, nil, err
+			}
+			log.Printf("Failed to save issue %s: %v", issue.Key, err)
+			if err := s.repo.RollbackToSavepointTx(tx, issueSavepoint); err != nil {
+				return nil, nil, nil, err
+			}
+			failures = append(failures, issueFailure{issue: issue, stage: models.DeadLetterPersist, err: err})
+			continue
+		}
+
+		if err := s.repo.ReleaseSavepointTx(tx, issueSavepoint); err != nil {
+			return nil, nil, nil, err
+		}
+		saved = append(saved, issueSaved...)
+		savedAuthors = append(savedAuthors, issueAuthors...)
+		failures = append(failures, issueFailures...)
+	}
+
+	return saved, savedAuthors, failures, nil
+}
+
+// writeBatch преобразует задачи и пишет их в транзакцию. Возвращает
+// записанные задачи, новых авторов и задачи, которые не удалось
+// преобразовать.
+func (s *ETLService) writeBatch(tx *sql.Tx, projectKey string, issues []models.JiraIssue, partial bool) ([]models.DBIssue, []models.DBAuthor, []issueFailure, error) {
+	// Новые авторы сохраняются в транзакции батча, чтобы при откате
+	// не оставалось авторов без задач
+	authors, savedAuthors, err := s.authors.resolve(tx, collectAuthors(issues), s.repo.SaveAuthorsTx)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to save authors: %w", err)
+	}
+
+	batch, failures := s.transformIssues(issues, projectKey, authors)
+
+	if err := s.repo.SaveIssuesTx(tx, batch.issues); err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to save issues: %w", err)
+	}
+
+	if err := s.repo.SaveChangelogTx(tx, batch.changelogs); err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to save changelogs: %w", err)
+	}
+
+	if err := s.repo.SaveFieldChangesTx(tx, batch.fieldChanges); err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to save field changes: %w", err)
 	}
 
-	return count
+	if !partial {
+		if err := s.repo.DeleteStaleCommentsTx(tx, batch.fullyCommentedKeys, batch.commentIDs); err != nil {
+			return nil, nil, nil, fmt.Errorf("failed to delete stale comments: %w", err)
+		}
+	}
+
+	if err := s.repo.SaveCommentsTx(tx, batch.comments); err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to save comments: %w", err)
+	}
+
+	if !partial {
+		if err := s.repo.DeleteStaleWorklogsTx(tx, batch.fullyLoggedKeys, batch.worklogIDs); err != nil {
+			return nil, nil, nil, fmt.Errorf("failed to delete stale worklogs: %w", err)
+		}
+	}
+
+	if err := s.repo.SaveWorklogsTx(tx, batch.worklogs); err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to save worklogs: %w", err)
+	}
+
+	if !partial {
+		if err := s.repo.DeleteStaleIssueLinksTx(tx, batch.fullyLinkedKeys, batch.linkIDs); err != nil {
+			return nil, nil, nil, fmt.Errorf("failed to delete stale issue links: %w", err)
+		}
+	}
+
+	if err := s.repo.SaveIssueLinksTx(tx, batch.links); err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to save issue links: %w", err)
+	}
+
+	if err := s.repo.SaveIssueDimensionsTx(tx, batch.dimensions); err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to save issue dimensions: %w", err)
+	}
+
+	return batch.issues, savedAuthors, failures, nil
+}
+
+func issueKeys(issues []models.JiraIssue) []string {
+	keys := make([]string, len(issues))
+	for i, issue := range issues {
+		keys[i] = issue.Key
+	}
+	return keys
 }
@@ -0,0 +1,294 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"jiraAnalyzer/jiraConnector/internal/models"
+	"jiraAnalyzer/jiraConnector/internal/repository"
+	"log"
+	"sync"
+	"time"
+)
+
+var (
+	ErrJobNotFound   = errors.New("sync job not found")
+	ErrJobNotRunning = errors.New("sync job is not running")
+	ErrProjectBusy   = errors.New("project is already being synced")
+
+	errJobCancelled = errors.New("sync job cancelled")
+	errShutdown     = errors.New("connector is shutting down")
+)
+
+// jobUpdateTimeout ограничивает запись состояния задачи в БД. Состояние
+// пишется и после отмены задачи, поэтому используется отдельный контекст.
+const jobUpdateTimeout = 5 * time.Second
+
+// JobService выполняет синхронизацию проектов в фоне и хранит состояние
+// задач в Postgres, чтобы оно переживало перезапуск коннектора.
+type JobService struct {
+	repo *repository.Repository
+	etl  *ETLService
+
+	baseCtx context.Context
+	stop    context.CancelCauseFunc
+
+	mu      sync.Mutex
+	running map[int]context.CancelCauseFunc
+	// active - проекты, которые сейчас синхронизируются, и id их задач
+	active map[string]int
+	wg     sync.WaitGroup
+}
+
+func NewJobService(repo *repository.Repository, etl *ETLService) *JobService {
+	baseCtx, stop := context.WithCancelCause(context.Background())
+	return &JobService{
+		repo:    repo,
+		etl:     etl,
+		baseCtx: baseCtx,
+		stop:    stop,
+		running: make(map[int]context.CancelCauseFunc),
+		active:  make(map[string]int),
+	}
+}
+
+// Recover помечает прерванными задачи, которые выполнялись до перезапуска.
+func (s *JobService) Recover(ctx context.Context) error {
+	count, err := s.repo.InterruptActiveSyncJobs(ctx, "interrupted by connector restart")
+	if err != nil {
+		return err
+	}
+
+	if count > 0 {
+		log.Printf("Marked %d unfinished sync jobs as interrupted", count)
+	}
+	return nil
+}
+
+// Enqueue создаёт задачу синхронизации и запускает её в фоне. Если какой-то
+// из проектов уже синхронизируется, возвращается ErrProjectBusy.
+func (s *JobService) Enqueue(ctx context.Context, projectKeys []string, opts SyncOptions) (int, error) {
+	if err := s.reserve(projectKeys); err != nil {
+		return 0, err
+	}
+
+	// Задача создаётся без блокировки, чтобы запись в БД не задерживала
+	// другие запросы. Проекты на это время зарезервированы.
+	jobID, err := s.repo.CreateSyncJob(ctx, projectKeys, opts.Full, opts.DryRun != nil)
+	if err != nil {
+		s.mu.Lock()
+		for _, key := range projectKeys {
+			delete(s.active, key)
+		}
+		s.mu.Unlock()
+		s.wg.Done()
+		return 0, err
+	}
+
+	// Если коннектор останавливается, задача сразу завершится как прерванная
+	s.mu.Lock()
+	jobCtx, cancel := context.WithCancelCause(s.baseCtx)
+	s.running[jobID] = cancel
+	for _, key := range projectKeys {
+		s.active[key] = jobID
+	}
+	s.mu.Unlock()
+
+	go s.run(jobCtx, jobID, projectKeys, opts)
+
+	log.Printf("Sync job %d enqueued for projects %v", jobID, projectKeys)
+	return jobID, nil
+}
+
+// pendingJobID - id задачи у проектов, зарезервированных до её создания.
+const pendingJobID = 0
+
+// reserve резервирует проекты за создаваемой задачей. Shutdown ждёт
+// зарезервированную задачу так же, как выполняющуюся.
+func (s *JobService) reserve(projectKeys []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.baseCtx.Err() != nil {
+		return errShutdown
+	}
+
+	for _, key := range projectKeys {
+		if jobID, ok := s.active[key]; ok {
+			if jobID == pendingJobID {
+				return fmt.Errorf("%w: %s", ErrProjectBusy, key)
+			}
+			return fmt.Errorf("%w: %s (job %d)", ErrProjectBusy, key, jobID)
+		}
+	}
+
+	for _, key := range projectKeys {
+		s.active[key] = pendingJobID
+	}
+	s.wg.Add(1)
+	return nil
+}
+
+func (s *JobService) GetJob(ctx context.Context, jobID int) (models.DBSyncJob, error) {
+	job, err := s.repo.GetSyncJob(ctx, jobID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.DBSyncJob{}, ErrJobNotFound
+	}
+	return job, err
+}
+
+func (s *JobService) ListJobs(ctx context.Context, limit int) ([]models.DBSyncJob, error) {
+	return s.repo.ListSyncJobs(ctx, limit)
+}
+
+// Cancel прерывает выполняющуюся задачу. Итоговый статус задача
+// выставит сама после остановки всех горутин.
+func (s *JobService) Cancel(ctx context.Context, jobID int) error {
+	s.mu.Lock()
+	cancel, ok := s.running[jobID]
+	s.mu.Unlock()
+
+	if !ok {
+		if _, err := s.GetJob(ctx, jobID); err != nil {
+			return err
+		}
+		return ErrJobNotRunning
+	}
+
+	cancel(errJobCancelled)
+	return nil
+}
+
+// Shutdown прерывает все задачи и ждёт их завершения не дольше, чем позволяет ctx.
+func (s *JobService) Shutdown(ctx context.Context) error {
+	s.mu.Lock()
+	s.stop(errShutdown)
+	s.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("sync jobs did not stop in time: %w", ctx.Err())
+	}
+}
+
+func (s *JobService) run(ctx context.Context, jobID int, projectKeys []string, opts SyncOptions) {
+	defer s.wg.Done()
+	defer func() {
+		s.mu.Lock()
+		delete(s.running, jobID)
+		for _, key := range projectKeys {
+			delete(s.active, key)
+		}
+		s.mu.Unlock()
+	}()
+
+	progress := &jobProgress{repo: s.repo, jobID: jobID}
+	progress.update(func(ctx context.Context) error {
+		return s.repo.StartSyncJob(ctx, jobID)
+	})
+
+	opts.Progress = progress
+	err := s.etl.UpdateProject(ctx, projectKeys, opts)
+
+	status := models.JobStatusSucceeded
+	switch {
+	case err == nil:
+	case errors.Is(context.Cause(ctx), errJobCancelled):
+		status = models.JobStatusCancelled
+	case errors.Is(context.Cause(ctx), errShutdown):
+		status = models.JobStatusInterrupted
+		progress.addError(errShutdown.Error())
+	default:
+		status = models.JobStatusFailed
+	}
+
+	// Отчёт неудачной пробной синхронизации неполон, его не сохраняем
+	if err == nil && opts.DryRun != nil {
+		s.saveReport(jobID, opts.DryRun)
+	}
+
+	if err != nil {
+		log.Printf("Sync job %d finished with status %s: %v", jobID, status, err)
+	} else {
+		log.Printf("Sync job %d finished successfully", jobID)
+	}
+
+	s.finish(jobID, status)
+}
+
+func (s *JobService) saveReport(jobID int, report *DryRunReport) {
+	data, err := json.Marshal(report)
+	if err != nil {
+		log.Printf("Failed to encode dry-run report of sync job %d: %v", jobID, err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), jobUpdateTimeout)
+	defer cancel()
+
+	if err := s.repo.SaveSyncJobReport(ctx, jobID, data); err != nil {
+		log.Printf("Failed to save dry-run report of sync job %d: %v", jobID, err)
+	}
+}
+
+func (s *JobService) finish(jobID int, status string) {
+	ctx, cancel := context.WithTimeout(context.Background(), jobUpdateTimeout)
+	defer cancel()
+
+	if err := s.repo.FinishSyncJob(ctx, jobID, status); err != nil {
+		log.Printf("Failed to finish sync job %d: %v", jobID, err)
+	}
+}
+
+// jobProgress сохраняет ход синхронизации в строку задачи.
+type jobProgress struct {
+	repo  *repository.Repository
+	jobID int
+}
+
+func (p *jobProgress) BatchesPlanned(_ string, batches int) {
+	p.update(func(ctx context.Context) error {
+		return p.repo.AddSyncJobBatches(ctx, p.jobID, batches)
+	})
+}
+
+func (p *jobProgress) BatchLoaded(_ string, issues int) {
+	p.update(func(ctx context.Context) error {
+		return p.repo.AddSyncJobProgress(ctx, p.jobID, issues)
+	})
+}
+
+func (p *jobProgress) IssuesReconciled(_ string, result ReconcileResult) {
+	p.update(func(ctx context.Context) error {
+		return p.repo.AddSyncJobReconciliation(ctx, p.jobID, result.Deleted, result.Moved)
+	})
+}
+
+func (p *jobProgress) ProjectFailed(projectKey string, err error) {
+	p.addError(fmt.Sprintf("%s: %v", projectKey, err))
+}
+
+func (p *jobProgress) addError(message string) {
+	p.update(func(ctx context.Context) error {
+		return p.repo.AddSyncJobError(ctx, p.jobID, message)
+	})
+}
+
+func (p *jobProgress) update(fn func(ctx context.Context) error) {
+	ctx, cancel := context.WithTimeout(context.Background(), jobUpdateTimeout)
+	defer cancel()
+
+	if err := fn(ctx); err != nil {
+		log.Printf("Failed to update sync job %d: %v", p.jobID, err)
+	}
+}
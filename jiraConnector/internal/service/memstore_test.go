@@ -0,0 +1,761 @@
+package service_test
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"github.com/lib/pq"
+	"jiraAnalyzer/jiraConnector/internal/models"
+	"sort"
+	"sync"
+	"time"
+)
+
+var errNotSupported = errors.New("not supported by memory store")
+
+// memStore - JiraDB в памяти для тестов ETL без PostgreSQL. Транзакции
+// настоящие *sql.Tx поверх драйвера-заглушки: записи в транзакции
+// применяются при Commit и отбрасываются при Rollback. Авторы, как
+// и последовательность в PostgreSQL, получают id сразу. Как и в схеме,
+// длина summary задачи ограничена summaryLimit.
+type memStore struct {
+	db *sql.DB
+
+	// beginMu связывает *sql.Tx с транзакцией драйвера, созданной для него
+	beginMu sync.Mutex
+	begun   *memTx
+
+	mu         sync.Mutex
+	txs        map[*sql.Tx]*memTx
+	projects   map[string]models.DBProject
+	issues     map[string]*memIssue
+	changelogs map[string]models.DBChangelog
+	fields     map[string]models.DBFieldChange
+	comments   map[string]models.DBComment
+	worklogs   map[string]models.DBWorklog
+	links      map[string]models.DBIssueLink
+	dimensions map[string]models.DBIssueDimensions
+	authors    map[string]models.DBAuthor
+	sprints    map[int]models.DBSprint
+	watermarks map[string]time.Time
+	runs       []*models.DBSyncRun
+	// checkpoints - контрольные точки по id синхронизации
+	checkpoints map[int][]models.DBSyncCheckpoint
+	deadLetters []*models.DBDeadLetter
+	deliveries  map[string]*memDelivery
+	commits     int
+	// failCommits - сколько следующих Commit завершатся ошибкой
+	failCommits int
+	// summaryLimit - длина VARCHAR колонки issues.summary
+	summaryLimit int
+}
+
+type memDelivery struct {
+	claimedAt time.Time
+	processed bool
+}
+
+type memIssue struct {
+	models.DBIssue
+	Deleted bool
+	MovedTo string
+}
+
+func newMemStore() *memStore {
+	s := &memStore{
+		txs:        make(map[*sql.Tx]*memTx),
+		projects:   make(map[string]models.DBProject),
+		issues:     make(map[string]*memIssue),
+		changelogs: make(map[string]models.DBChangelog),
+		fields:     make(map[string]models.DBFieldChange),
+		comments:   make(map[string]models.DBComment),
+		worklogs:   make(map[string]models.DBWorklog),
+		links:      make(map[string]models.DBIssueLink),
+		dimensions: make(map[string]models.DBIssueDimensions),
+		authors:    make(map[string]models.DBAuthor),
+		sprints:    make(map[int]models.DBSprint),
+		watermarks: make(map[string]time.Time),
+
+		checkpoints: make(map[int][]models.DBSyncCheckpoint),
+		deliveries:  make(map[string]*memDelivery),
+
+		summaryLimit: 255,
+	}
+	s.db = sql.OpenDB(memConnector{store: s})
+	return s
+}
+
+// Драйвер-заглушка: соединения умеют только открывать транзакции.
+
+type memConnector struct{ store *memStore }
+
+func (c memConnector) Connect(context.Context) (driver.Conn, error) { return memConn(c), nil }
+func (c memConnector) Driver() driver.Driver                        { return c }
+func (c memConnector) Open(string) (driver.Conn, error)             { return memConn(c), nil }
+
+type memConn struct{ store *memStore }
+
+func (c memConn) Prepare(string) (driver.Stmt, error) { return nil, errNotSupported }
+func (c memConn) Close() error                        { return nil }
+func (c memConn) Begin() (driver.Tx, error) {
+	tx := &memTx{store: c.store, savepoints: make(map[string]int)}
+	c.store.begun = tx
+	return tx, nil
+}
+
+// memTx копит изменения транзакции до Commit. Savepoint запоминает
+// число накопленных изменений.
+type memTx struct {
+	store      *memStore
+	ops        []func()
+	savepoints map[string]int
+}
+
+func (t *memTx) Commit() error {
+	t.store.mu.Lock()
+	defer t.store.mu.Unlock()
+	if t.store.failCommits > 0 {
+		t.store.failCommits--
+		return errors.New("commit failed")
+	}
+	for _, op := range t.ops {
+		op()
+	}
+	t.store.commits++
+	return nil
+}
+
+func (t *memTx) Rollback() error { return nil }
+
+func (s *memStore) BeginTx(ctx context.Context) (*sql.Tx, error) {
+	s.beginMu.Lock()
+	defer s.beginMu.Unlock()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	s.txs[tx] = s.begun
+	s.mu.Unlock()
+	return tx, nil
+}
+
+// stage добавляет изменение в транзакцию tx.
+func (s *memStore) stage(tx *sql.Tx, op func()) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t, ok := s.txs[tx]
+	if !ok {
+		return errors.New("unknown transaction")
+	}
+	t.ops = append(t.ops, op)
+	return nil
+}
+
+func (s *memStore) SavepointTx(tx *sql.Tx, name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t := s.txs[tx]
+	t.savepoints[name] = len(t.ops)
+	return nil
+}
+
+func (s *memStore) RollbackToSavepointTx(tx *sql.Tx, name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t := s.txs[tx]
+	n, ok := t.savepoints[name]
+	if !ok {
+		return fmt.Errorf("unknown savepoint %s", name)
+	}
+	t.ops = t.ops[:n]
+	return nil
+}
+
+func (s *memStore) ReleaseSavepointTx(tx *sql.Tx, name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.txs[tx].savepoints, name)
+	return nil
+}
+
+// Проекты
+
+func (s *memStore) CheckProjectExists(ctx context.Context, projectKey string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.projects[projectKey]
+	return ok, nil
+}
+
+func (s *memStore) SaveProject(ctx context.Context, project models.DBProject) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	project.ID = len(s.projects) + 1
+	s.projects[project.Key] = project
+	return nil
+}
+
+// Задачи
+
+func (s *memStore) SaveIssuesTx(tx *sql.Tx, issues []models.DBIssue) error {
+	for _, issue := range issues {
+		if len(issue.Summary) > s.summaryLimit {
+			return fmt.Errorf("failed to bulk upsert issues: %w", &pq.Error{
+				Code:    "22001",
+				Message: fmt.Sprintf("value too long for type character varying(%d)", s.summaryLimit),
+			})
+		}
+	}
+	return s.stage(tx, func() {
+		for _, issue := range issues {
+			stored, ok := s.issues[issue.Key]
+			if ok && stored.Updated.After(issue.Updated) {
+				continue
+			}
+			if ok && issue.Closed == nil {
+				issue.Closed = stored.Closed
+			}
+			s.issues[issue.Key] = &memIssue{DBIssue: issue}
+		}
+	})
+}
+
+func (s *memStore) SaveChangelogTx(tx *sql.Tx, changelogs []models.DBChangelog) error {
+	return s.stage(tx, func() {
+		for _, cl := range changelogs {
+			key := cl.IssueID + "|" + cl.Created.String()
+			if _, ok := s.changelogs[key]; !ok {
+				s.changelogs[key] = cl
+			}
+		}
+	})
+}
+
+func (s *memStore) SaveFieldChangesTx(tx *sql.Tx, changes []models.DBFieldChange) error {
+	return s.stage(tx, func() {
+		for _, c := range changes {
+			s.fields[fmt.Sprintf("%s|%s|%d", c.IssueID, c.HistoryID, c.ItemIndex)] = c
+		}
+	})
+}
+
+func (s *memStore) SaveCommentsTx(tx *sql.Tx, comments []models.DBComment) error {
+	return s.stage(tx, func() {
+		for _, c := range comments {
+			s.comments[c.JiraID] = c
+		}
+	})
+}
+
+func (s *memStore) DeleteStaleCommentsTx(tx *sql.Tx, issueKeys []string, keepIDs []string) error {
+	return s.stage(tx, func() {
+		deleteStale(s.comments, issueKeys, keepIDs, func(c models.DBComment) string { return c.IssueID })
+	})
+}
+
+func (s *memStore) SaveWorklogsTx(tx *sql.Tx, worklogs []models.DBWorklog) error {
+	return s.stage(tx, func() {
+		for _, w := range worklogs {
+			s.worklogs[w.JiraID] = w
+		}
+	})
+}
+
+func (s *memStore) DeleteStaleWorklogsTx(tx *sql.Tx, issueKeys []string, keepIDs []string) error {
+	return s.stage(tx, func() {
+		deleteStale(s.worklogs, issueKeys, keepIDs, func(w models.DBWorklog) string { return w.IssueID })
+	})
+}
+
+func (s *memStore) SaveIssueLinksTx(tx *sql.Tx, links []models.DBIssueLink) error {
+	return s.stage(tx, func() {
+		for _, l := range links {
+			s.links[l.JiraID] = l
+		}
+	})
+}
+
+func (s *memStore) DeleteStaleIssueLinksTx(tx *sql.Tx, issueKeys []string, keepIDs []string) error {
+	return s.stage(tx, func() {
+		deleteStale(s.links, issueKeys, keepIDs, func(l models.DBIssueLink) string { return l.SourceKey })
+	})
+}
+
+// deleteStale удаляет записи задач issueKeys, кроме keepIDs.
+func deleteStale[T any](rows map[string]T, issueKeys, keepIDs []string, issueOf func(T) string) {
+	issues := make(map[string]bool, len(issueKeys))
+	for _, key := range issueKeys {
+		issues[key] = true
+	}
+	keep := make(map[string]bool, len(keepIDs))
+	for _, id := range keepIDs {
+		keep[id] = true
+	}
+	for id, row := range rows {
+		if issues[issueOf(row)] && !keep[id] {
+			delete(rows, id)
+		}
+	}
+}
+
+func (s *memStore) SaveIssueDimensionsTx(tx *sql.Tx, dimensions []models.DBIssueDimensions) error {
+	return s.stage(tx, func() {
+		for _, d := range dimensions {
+			s.dimensions[d.IssueKey] = d
+		}
+	})
+}
+
+func (s *memStore) GetIssuesByKeys(ctx context.Context, keys []string) ([]models.DBIssue, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var issues []models.DBIssue
+	for _, key := range keys {
+		if issue, ok := s.issues[key]; ok {
+			issues = append(issues, issue.DBIssue)
+		}
+	}
+	return issues, nil
+}
+
+func (s *memStore) GetStatusChanges(ctx context.Context, issueKeys []string) ([]models.DBChangelog, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return selectByIssue(s.changelogs, issueKeys, func(c models.DBChangelog) string { return c.IssueID }), nil
+}
+
+func (s *memStore) GetFieldChanges(ctx context.Context, issueKeys []string) ([]models.DBFieldChange, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return selectByIssue(s.fields, issueKeys, func(c models.DBFieldChange) string { return c.IssueID }), nil
+}
+
+// selectByIssue возвращает записи задач issueKeys.
+func selectByIssue[T any](rows map[string]T, issueKeys []string, issueOf func(T) string) []T {
+	issues := make(map[string]bool, len(issueKeys))
+	for _, key := range issueKeys {
+		issues[key] = true
+	}
+	var selected []T
+	for _, row := range rows {
+		if issues[issueOf(row)] {
+			selected = append(selected, row)
+		}
+	}
+	return selected
+}
+
+func (s *memStore) CheckIssueExists(ctx context.Context, issueKey string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.issues[issueKey]
+	return ok, nil
+}
+
+func (s *memStore) DeleteComment(ctx context.Context, jiraID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.comments, jiraID)
+	return nil
+}
+
+// Сверка задач с Jira
+
+func (s *memStore) GetActiveIssueKeys(ctx context.Context, projectKey string) ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var keys []string
+	for key, issue := range s.issues {
+		if issue.ProjectKey == projectKey && !issue.Deleted {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+func (s *memStore) MarkIssuesDeleted(ctx context.Context, keys []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, key := range keys {
+		if issue, ok := s.issues[key]; ok {
+			issue.Deleted = true
+		}
+	}
+	return nil
+}
+
+func (s *memStore) MoveIssue(ctx context.Context, oldKey, newKey, newProjectKey string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	issue, ok := s.issues[oldKey]
+	if !ok {
+		return false, nil
+	}
+	_, projectExists := s.projects[newProjectKey]
+	if _, taken := s.issues[newKey]; taken || !projectExists {
+		issue.Deleted = true
+		issue.MovedTo = newKey
+		return false, nil
+	}
+
+	delete(s.issues, oldKey)
+	issue.Key, issue.ProjectKey = newKey, newProjectKey
+	issue.Deleted, issue.MovedTo = false, ""
+	s.issues[newKey] = issue
+	for id, c := range s.comments {
+		if c.IssueID == oldKey {
+			c.IssueID = newKey
+			s.comments[id] = c
+		}
+	}
+	for id, w := range s.worklogs {
+		if w.IssueID == oldKey {
+			w.IssueID = newKey
+			s.worklogs[id] = w
+		}
+	}
+	return true, nil
+}
+
+// Доставки webhook
+
+func (s *memStore) ClaimWebhookDelivery(ctx context.Context, deliveryID, event, issueKey string, claimTimeout time.Duration) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if d, ok := s.deliveries[deliveryID]; ok {
+		if d.processed {
+			return models.WebhookDeliveryProcessed, nil
+		}
+		if time.Since(d.claimedAt) < claimTimeout {
+			return models.WebhookDeliveryPending, nil
+		}
+	}
+	s.deliveries[deliveryID] = &memDelivery{claimedAt: time.Now()}
+	return models.WebhookDeliveryClaimed, nil
+}
+
+func (s *memStore) CompleteWebhookDelivery(ctx context.Context, deliveryID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if d, ok := s.deliveries[deliveryID]; ok {
+		d.processed = true
+	}
+	return nil
+}
+
+func (s *memStore) ReleaseWebhookDelivery(ctx context.Context, deliveryID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if d, ok := s.deliveries[deliveryID]; ok && !d.processed {
+		delete(s.deliveries, deliveryID)
+	}
+	return nil
+}
+
+func (s *memStore) PruneWebhookDeliveries(ctx context.Context, olderThan time.Duration) (int64, error) {
+	return 0, errNotSupported
+}
+
+// Авторы
+
+func (s *memStore) GetAuthors(ctx context.Context) ([]models.DBAuthor, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	authors := make([]models.DBAuthor, 0, len(s.authors))
+	for _, a := range s.authors {
+		authors = append(authors, a)
+	}
+	return authors, nil
+}
+
+func (s *memStore) SaveAuthorsTx(tx *sql.Tx, authors []models.DBAuthor) (map[string]int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ids := make(map[string]int, len(authors))
+	for _, a := range authors {
+		if stored, ok := s.authors[a.AccountID]; ok {
+			a.ID = stored.ID
+		} else {
+			a.ID = len(s.authors) + 1
+		}
+		s.authors[a.AccountID] = a
+		ids[a.AccountID] = a.ID
+	}
+	return ids, nil
+}
+
+// Спринты
+
+func (s *memStore) GetClosedSprintIDs(ctx context.Context, projectKey string) (map[int]bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	closed := make(map[int]bool)
+	for id, sprint := range s.sprints {
+		if sprint.ProjectKey == projectKey && sprint.State == models.SprintStateClosed {
+			closed[id] = true
+		}
+	}
+	return closed, nil
+}
+
+func (s *memStore) SaveSprintTx(tx *sql.Tx, sprint models.DBSprint) error {
+	return s.stage(tx, func() { s.sprints[sprint.ID] = sprint })
+}
+
+func (s *memStore) ReplaceSprintIssuesTx(tx *sql.Tx, sprintID int, issueKeys []string) error {
+	return nil
+}
+
+// Состояние синхронизации
+
+func (s *memStore) GetSyncWatermark(ctx context.Context, projectKey string) (*time.Time, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	watermark, ok := s.watermarks[projectKey]
+	if !ok {
+		return nil, nil
+	}
+	return &watermark, nil
+}
+
+func (s *memStore) SaveSyncWatermark(ctx context.Context, projectKey string, syncedAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.watermarks[projectKey] = syncedAt
+	return nil
+}
+
+func (s *memStore) GetActiveSyncRun(ctx context.Context, projectKey string) (*models.DBSyncRun, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, run := range s.runs {
+		if run.ProjectKey == projectKey && run.FinishedAt == nil {
+			active := *run
+			return &active, nil
+		}
+	}
+	return nil, nil
+}
+
+func (s *memStore) CreateSyncRun(ctx context.Context, run models.DBSyncRun) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now().UTC()
+	for _, r := range s.runs {
+		if r.ProjectKey == run.ProjectKey && r.FinishedAt == nil {
+			r.Status, r.FinishedAt = models.SyncRunAbandoned, &now
+			delete(s.checkpoints, r.ID)
+		}
+	}
+	run.ID = len(s.runs) + 1
+	run.Status = models.SyncRunRunning
+	s.runs = append(s.runs, &run)
+	return run.ID, nil
+}
+
+func (s *memStore) StartSyncRunPass(ctx context.Context, runID, pass int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.runs[runID-1].Pass = pass
+	return nil
+}
+
+func (s *memStore) GetSyncCheckpoints(ctx context.Context, runID int) ([]models.DBSyncCheckpoint, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]models.DBSyncCheckpoint(nil), s.checkpoints[runID]...), nil
+}
+
+func (s *memStore) SaveSyncCheckpointTx(tx *sql.Tx, checkpoint models.DBSyncCheckpoint) error {
+	return s.stage(tx, func() {
+		s.checkpoints[checkpoint.RunID] = append(s.checkpoints[checkpoint.RunID], checkpoint)
+	})
+}
+
+func (s *memStore) FinishSyncRun(ctx context.Context, runID int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now().UTC()
+	run := s.runs[runID-1]
+	run.Status, run.FinishedAt = models.SyncRunSucceeded, &now
+	delete(s.checkpoints, runID)
+	return nil
+}
+
+// Задачи, не прошедшие ETL
+
+func (s *memStore) SaveDeadLettersTx(tx *sql.Tx, letters []models.DBDeadLetter) error {
+	return s.stage(tx, func() {
+		now := time.Now().UTC()
+		for _, l := range letters {
+			if open := s.openDeadLetter(l.IssueKey); open != nil {
+				if l.SyncRunID == nil {
+					l.SyncRunID = open.SyncRunID
+				}
+				l.ID, l.Attempts, l.CreatedAt, l.UpdatedAt = open.ID, open.Attempts+1, open.CreatedAt, now
+				*open = l
+				continue
+			}
+			l.ID, l.Attempts, l.CreatedAt, l.UpdatedAt = len(s.deadLetters)+1, 1, now, now
+			s.deadLetters = append(s.deadLetters, &l)
+		}
+	})
+}
+
+func (s *memStore) openDeadLetter(issueKey string) *models.DBDeadLetter {
+	for _, l := range s.deadLetters {
+		if l.IssueKey == issueKey && l.ResolvedAt == nil {
+			return l
+		}
+	}
+	return nil
+}
+
+func (s *memStore) ResolveDeadLettersTx(tx *sql.Tx, issueKeys []string) error {
+	return s.stage(tx, func() {
+		now := time.Now().UTC()
+		for _, key := range issueKeys {
+			if open := s.openDeadLetter(key); open != nil {
+				open.ResolvedAt, open.UpdatedAt = &now, now
+			}
+		}
+	})
+}
+
+func (s *memStore) GetDeadLetter(ctx context.Context, id int) (models.DBDeadLetter, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if id < 1 || id > len(s.deadLetters) {
+		return models.DBDeadLetter{}, fmt.Errorf("failed to get dead letter: %w", sql.ErrNoRows)
+	}
+	return *s.deadLetters[id-1], nil
+}
+
+func (s *memStore) ListDeadLetters(ctx context.Context, projectKey string, resolved bool, limit int) ([]models.DBDeadLetter, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var letters []models.DBDeadLetter
+	for i := len(s.deadLetters) - 1; i >= 0 && len(letters) < limit; i-- {
+		l := s.deadLetters[i]
+		if (projectKey == "" || l.ProjectKey == projectKey) && (resolved || l.ResolvedAt == nil) {
+			letters = append(letters, *l)
+		}
+	}
+	return letters, nil
+}
+
+// Фоновые задачи и планировщик в тестах ETL не используются
+
+func (s *memStore) CreateSyncJob(ctx context.Context, projectKeys []string, full, dryRun bool) (int, error) {
+	return 0, errNotSupported
+}
+
+func (s *memStore) GetSyncJob(ctx context.Context, jobID int) (models.DBSyncJob, error) {
+	return models.DBSyncJob{}, errNotSupported
+}
+
+func (s *memStore) ListSyncJobs(ctx context.Context, limit int) ([]models.DBSyncJob, error) {
+	return nil, errNotSupported
+}
+
+func (s *memStore) StartSyncJob(ctx context.Context, jobID int) error { return errNotSupported }
+
+func (s *memStore) AddSyncJobBatches(ctx context.Context, jobID int, batches int) error {
+	return errNotSupported
+}
+
+func (s *memStore) AddSyncJobProgress(ctx context.Context, jobID int, issues int) error {
+	return errNotSupported
+}
+
+func (s *memStore) AddSyncJobError(ctx context.Context, jobID int, message string) error {
+	return errNotSupported
+}
+
+func (s *memStore) AddSyncJobReconciliation(ctx context.Context, jobID int, deleted, moved int) error {
+	return errNotSupported
+}
+
+func (s *memStore) FinishSyncJob(ctx context.Context, jobID int, status string) error {
+	return errNotSupported
+}
+
+func (s *memStore) SaveSyncJobReport(ctx context.Context, jobID int, report []byte) error {
+	return errNotSupported
+}
+
+func (s *memStore) InterruptActiveSyncJobs(ctx context.Context, reason string) (int64, error) {
+	return 0, errNotSupported
+}
+
+func (s *memStore) SaveScheduleRun(ctx context.Context, run models.DBScheduleRun) error {
+	return errNotSupported
+}
+
+func (s *memStore) ListScheduleRuns(ctx context.Context, scheduleName string, limit int) ([]models.DBScheduleRun, error) {
+	return nil, errNotSupported
+}
+
+// Проверки состояния в тестах
+
+func (s *memStore) issue(key string) (memIssue, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	issue, ok := s.issues[key]
+	if !ok {
+		return memIssue{}, false
+	}
+	return *issue, true
+}
+
+func (s *memStore) issueKeys() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	keys := make([]string, 0, len(s.issues))
+	for key := range s.issues {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// count возвращает число записей задачи в таблице: comments, worklogs,
+// changelogs или links.
+func (s *memStore) count(table, issueKey string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n := 0
+	switch table {
+	case "comments":
+		for _, c := range s.comments {
+			if c.IssueID == issueKey {
+				n++
+			}
+		}
+	case "worklogs":
+		for _, w := range s.worklogs {
+			if w.IssueID == issueKey {
+				n++
+			}
+		}
+	case "changelogs":
+		for _, cl := range s.changelogs {
+			if cl.IssueID == issueKey {
+				n++
+			}
+		}
+	case "links":
+		for _, l := range s.links {
+			if l.SourceKey == issueKey {
+				n++
+			}
+		}
+	}
+	return n
+}
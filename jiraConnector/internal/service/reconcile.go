@@ -0,0 +1,105 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"jiraAnalyzer/jiraConnector/internal/models"
+	"log"
+	"sort"
+)
+
+// ReconcileResult - итог сверки задач проекта с Jira после полной синхронизации.
+type ReconcileResult struct {
+	// Deleted - задачи, удалённые в Jira
+	Deleted int `json:"deleted"`
+	// Moved - задачи, перенесённые в другой проект под новым ключом
+	Moved int `json:"moved"`
+	// Loaded - задачи, которые есть в Jira, но не попали в выборку
+	Loaded int `json:"loaded"`
+}
+
+// reconcileIssues находит задачи проекта, которых не было среди загруженных
+// при полной синхронизации, и уточняет их судьбу в Jira. Задача, которую
+// Jira не находит, помечается удалённой. Перенесённая задача переходит
+// под новый ключ, если её новый проект синхронизируется, иначе помечается
+// удалённой с новым ключом в moved_to. Задача, которая есть в Jira под тем
+// же ключом, загружается отдельно: страницы выборки могли сдвинуться, пока
+// их загружали.
+func (s *ETLService) reconcileIssues(ctx context.Context, projectKey string, seen map[string]bool) (ReconcileResult, error) {
+	var result ReconcileResult
+
+	stored, err := s.repo.GetActiveIssueKeys(ctx, projectKey)
+	if err != nil {
+		return result, err
+	}
+
+	var missing []string
+	for _, key := range stored {
+		if !seen[key] {
+			missing = append(missing, key)
+		}
+	}
+	if len(missing) == 0 {
+		return result, nil
+	}
+	sort.Strings(missing)
+	log.Printf("Reconciling %d issues of project %s missing from Jira search", len(missing), projectKey)
+
+	var deleted, unloaded []string
+	for _, key := range missing {
+		ref, err := s.repo.GetIssueRef(ctx, key)
+		if err != nil {
+			return result, fmt.Errorf("failed to look up issue %s: %w", key, err)
+		}
+
+		switch {
+		case ref == nil:
+			deleted = append(deleted, key)
+		case ref.Key != key:
+			reassigned, err := s.repo.MoveIssue(ctx, key, ref.Key, ref.Fields.Project.Key)
+			if err != nil {
+				return result, err
+			}
+			if reassigned {
+				log.Printf("Issue %s moved to %s, reassigned to project %s", key, ref.Key, ref.Fields.Project.Key)
+			} else {
+				log.Printf("Issue %s moved to %s, marked as moved", key, ref.Key)
+			}
+			result.Moved++
+		default:
+			unloaded = append(unloaded, key)
+		}
+	}
+
+	var issues []models.JiraIssue
+	for _, key := range unloaded {
+		issue, err := s.repo.GetIssue(ctx, key)
+		if err != nil {
+			return result, fmt.Errorf("failed to load issue %s: %w", key, err)
+		}
+		// Задачу удалили между запросами
+		if issue == nil {
+			deleted = append(deleted, key)
+			continue
+		}
+		log.Printf("Issue %s exists in Jira but was not loaded, loading it", key)
+		issues = append(issues, *issue)
+	}
+	if len(issues) > 0 {
+		if err := s.completeWorklogs(ctx, issues); err != nil {
+			return result, err
+		}
+		if _, err := s.saveIssues(ctx, projectKey, issues, false, nil); err != nil {
+			return result, fmt.Errorf("failed to save unloaded issues: %w", err)
+		}
+	}
+	result.Loaded = len(issues)
+
+	if err := s.repo.MarkIssuesDeleted(ctx, deleted); err != nil {
+		return result, err
+	}
+	result.Deleted = len(deleted)
+
+	log.Printf("Reconciled project %s: %d deleted, %d moved, %d loaded", projectKey, result.Deleted, result.Moved, result.Loaded)
+	return result, nil
+}
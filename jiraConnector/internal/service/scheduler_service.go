@@ -0,0 +1,221 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"jiraAnalyzer/jiraConnector/internal/models"
+	"jiraAnalyzer/jiraConnector/internal/repository"
+	"log"
+	"path"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Режимы синхронизации в расписании
+const (
+	SyncModeIncremental = "incremental"
+	SyncModeFull        = "full"
+)
+
+type SchedulerConfig struct {
+	Schedules []ScheduleConfig `yaml:"schedules"`
+}
+
+// ScheduleConfig описывает одно расписание. Project - ключ проекта или
+// шаблон вида "HADOOP*", задаётся либо Cron, либо Interval.
+type ScheduleConfig struct {
+	Name     string        `yaml:"name"`
+	Project  string        `yaml:"project"`
+	Cron     string        `yaml:"cron"`
+	Interval time.Duration `yaml:"interval"`
+	Mode     string        `yaml:"mode"`
+}
+
+type schedule struct {
+	ScheduleConfig
+	cron *cronSchedule
+}
+
+func (s schedule) next(t time.Time) time.Time {
+	if s.cron != nil {
+		return s.cron.next(t)
+	}
+	return t.Add(s.Interval)
+}
+
+// SchedulerService периодически ставит синхронизацию проектов в очередь
+// JobService. Пересечение запусков одного проекта не допускает JobService.
+type SchedulerService struct {
+	repo      *repository.Repository
+	jobs      *JobService
+	schedules []schedule
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func NewSchedulerService(repo *repository.Repository, jobs *JobService, cfg SchedulerConfig) (*SchedulerService, error) {
+	schedules := make([]schedule, 0, len(cfg.Schedules))
+	names := make(map[string]struct{}, len(cfg.Schedules))
+
+	for _, sc := range cfg.Schedules {
+		if sc.Project == "" {
+			return nil, fmt.Errorf("schedule %q: project is required", sc.Name)
+		}
+		if sc.Name == "" {
+			sc.Name = sc.Project
+		}
+		if _, ok := names[sc.Name]; ok {
+			return nil, fmt.Errorf("schedule %q is defined twice", sc.Name)
+		}
+		names[sc.Name] = struct{}{}
+
+		if sc.Mode == "" {
+			sc.Mode = SyncModeIncremental
+		}
+		if sc.Mode != SyncModeIncremental && sc.Mode != SyncModeFull {
+			return nil, fmt.Errorf("schedule %q: unknown mode %q", sc.Name, sc.Mode)
+		}
+
+		sch := schedule{ScheduleConfig: sc}
+		switch {
+		case sc.Cron != "" && sc.Interval != 0:
+			return nil, fmt.Errorf("schedule %q: cron and interval are mutually exclusive", sc.Name)
+		case sc.Cron != "":
+			cron, err := parseCron(sc.Cron)
+			if err != nil {
+				return nil, fmt.Errorf("schedule %q: %w", sc.Name, err)
+			}
+			sch.cron = cron
+		case sc.Interval > 0:
+		default:
+			return nil, fmt.Errorf("schedule %q: cron or positive interval is required", sc.Name)
+		}
+
+		schedules = append(schedules, sch)
+	}
+
+	return &SchedulerService{
+		repo:      repo,
+		jobs:      jobs,
+		schedules: schedules,
+	}, nil
+}
+
+func (s *SchedulerService) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+
+	for _, sch := range s.schedules {
+		s.wg.Add(1)
+		go s.loop(ctx, sch)
+	}
+
+	log.Printf("Scheduler started with %d schedules", len(s.schedules))
+}
+
+// Stop останавливает планировщик. Уже запущенные задачи останавливает JobService.
+func (s *SchedulerService) Stop() {
+	if s.cancel == nil {
+		return
+	}
+
+	s.cancel()
+	s.wg.Wait()
+	log.Println("Scheduler stopped")
+}
+
+func (s *SchedulerService) Runs(ctx context.Context, scheduleName string, limit int) ([]models.DBScheduleRun, error) {
+	return s.repo.ListScheduleRuns(ctx, scheduleName, limit)
+}
+
+func (s *SchedulerService) loop(ctx context.Context, sch schedule) {
+	defer s.wg.Done()
+
+	for {
+		next := sch.next(time.Now())
+		if next.IsZero() {
+			log.Printf("Schedule %s never fires, stopping it", sch.Name)
+			return
+		}
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+			s.trigger(ctx, sch, next)
+		}
+	}
+}
+
+func (s *SchedulerService) trigger(ctx context.Context, sch schedule, scheduledAt time.Time) {
+	full := sch.Mode == SyncModeFull
+
+	projectKeys, err := s.resolveProjects(ctx, sch.Project)
+	if err != nil {
+		log.Printf("Schedule %s: failed to resolve projects: %v", sch.Name, err)
+		s.saveRun(ctx, sch, sch.Project, full, nil, models.ScheduleRunFailed, err.Error(), scheduledAt)
+		return
+	}
+
+	for _, key := range projectKeys {
+		jobID, err := s.jobs.Enqueue(ctx, []string{key}, SyncOptions{Full: full})
+		switch {
+		case errors.Is(err, ErrProjectBusy):
+			log.Printf("Schedule %s: skipping %s, previous sync is still running", sch.Name, key)
+			s.saveRun(ctx, sch, key, full, nil, models.ScheduleRunSkipped, err.Error(), scheduledAt)
+		case err != nil:
+			log.Printf("Schedule %s: failed to enqueue %s: %v", sch.Name, key, err)
+			s.saveRun(ctx, sch, key, full, nil, models.ScheduleRunFailed, err.Error(), scheduledAt)
+		default:
+			s.saveRun(ctx, sch, key, full, &jobID, models.ScheduleRunStarted, "", scheduledAt)
+		}
+	}
+}
+
+// resolveProjects раскрывает шаблон в список ключей проектов Jira.
+func (s *SchedulerService) resolveProjects(ctx context.Context, pattern string) ([]string, error) {
+	if !strings.ContainsAny(pattern, "*?[") {
+		return []string{pattern}, nil
+	}
+
+	projects, err := s.repo.GetAllProjects(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch projects: %w", err)
+	}
+
+	var keys []string
+	for _, p := range projects {
+		matched, err := path.Match(pattern, p.Key)
+		if err != nil {
+			return nil, fmt.Errorf("invalid project pattern %q: %w", pattern, err)
+		}
+		if matched {
+			keys = append(keys, p.Key)
+		}
+	}
+
+	return keys, nil
+}
+
+func (s *SchedulerService) saveRun(ctx context.Context, sch schedule, projectKey string, full bool, jobID *int, status, message string, scheduledAt time.Time) {
+	run := models.DBScheduleRun{
+		ScheduleName: sch.Name,
+		ProjectKey:   projectKey,
+		Full:         full,
+		JobID:        jobID,
+		Status:       status,
+		ScheduledAt:  scheduledAt.UTC(),
+	}
+	if message != "" {
+		run.Message = &message
+	}
+
+	if err := s.repo.SaveScheduleRun(ctx, run); err != nil {
+		log.Printf("Schedule %s: failed to save run: %v", sch.Name, err)
+	}
+}
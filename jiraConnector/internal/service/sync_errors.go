@@ -0,0 +1,68 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"jiraAnalyzer/jiraConnector/internal/repository/jira"
+)
+
+// Причины неудачной синхронизации проекта
+const (
+	// FailureJiraUnavailable - Jira не отвечает или отвечает 5xx после всех повторов
+	FailureJiraUnavailable = "jira unavailable"
+	// FailureJiraAuth - Jira отклонила учётные данные или не хватает прав
+	FailureJiraAuth = "jira authentication failed"
+	// FailureJiraRejected - Jira отклонила запрос (400, 404), например проект не найден
+	FailureJiraRejected = "jira rejected request"
+	// FailureProjectNotFound - проекта нет среди доступных в Jira
+	FailureProjectNotFound = "project not found in jira"
+	FailureTimeout         = "timed out"
+	// FailureInternal - ошибка коннектора: БД, преобразование данных
+	FailureInternal = "internal error"
+)
+
+var errProjectNotFound = errors.New("project not found")
+
+// ProjectSyncError - ошибка синхронизации проекта с классифицированной причиной.
+type ProjectSyncError struct {
+	ProjectKey string
+	Reason     string
+	Err        error
+}
+
+func newProjectSyncError(projectKey string, err error) *ProjectSyncError {
+	return &ProjectSyncError{ProjectKey: projectKey, Reason: failureReason(err), Err: err}
+}
+
+func (e *ProjectSyncError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Reason, e.Err)
+}
+
+func (e *ProjectSyncError) Unwrap() error {
+	return e.Err
+}
+
+// failureReason определяет причину неудачи по цепочке ошибок.
+func failureReason(err error) string {
+	var reqErr *jira.RequestError
+	switch {
+	case errors.Is(err, errProjectNotFound):
+		return FailureProjectNotFound
+	case errors.Is(err, jira.ErrCircuitOpen):
+		return FailureJiraUnavailable
+	case errors.As(err, &reqErr):
+		switch reqErr.Kind {
+		case jira.ErrorAuth:
+			return FailureJiraAuth
+		case jira.ErrorPermanent:
+			return FailureJiraRejected
+		default:
+			return FailureJiraUnavailable
+		}
+	case errors.Is(err, context.DeadlineExceeded):
+		return FailureTimeout
+	default:
+		return FailureInternal
+	}
+}
@@ -0,0 +1,153 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"jiraAnalyzer/jiraConnector/internal/models"
+	"log"
+	"time"
+)
+
+// syncRun - синхронизация проекта с контрольными точками. Каждый батч
+// отмечается в sync_checkpoints в своей транзакции, поэтому упавшая или
+// прерванная синхронизация продолжается с того места, где остановилась.
+//
+// Задачи выбираются по возрастанию ключа, и каждый проход загружает ту же
+// выборку, что и первый. Новый проход продолжает её с первого батча,
+// не сохранённого подряд с начала выборки. Если задачи перед ним удалили,
+// батчи сдвинулись бы и часть задач пропустилась, поэтому последний
+// сохранённый батч загружается повторно: если в нём оказались задачи не
+// из сохранённых батчей, выборка загружается с начала. Задачи, сохранённые
+// предыдущими проходами, при сверке считаются существующими, если проход
+// не начал выборку заново: удалённые из них обнаружит следующая полная
+// синхронизация.
+type syncRun struct {
+	models.DBSyncRun
+	// seen - задачи, сохранённые предыдущими проходами
+	seen map[string]bool
+	// resumeAt - смещение первого батча, не сохранённого подряд с начала
+	// выборки, prefix - задачи батчей до него
+	resumeAt int
+	prefix   map[string]bool
+}
+
+// resumeOffset возвращает смещение, с которого проход продолжает выборку.
+func (r *syncRun) resumeOffset() int {
+	if r == nil {
+		return 0
+	}
+	return r.resumeAt
+}
+
+// unshifted проверяет, что повторно загруженный последний батч префикса
+// содержит только задачи префикса.
+func (r *syncRun) unshifted(keys []string) bool {
+	for _, key := range keys {
+		if !r.prefix[key] {
+			return false
+		}
+	}
+	return true
+}
+
+// restart отмечает, что проход загружает выборку с начала: задачи прошлых
+// проходов больше не считаются загруженными.
+func (r *syncRun) restart() {
+	r.seen = make(map[string]bool)
+}
+
+// checkpoint возвращает контрольную точку батча startAt текущего прохода
+// или nil без синхронизации.
+func (r *syncRun) checkpoint(startAt int) *models.DBSyncCheckpoint {
+	if r == nil {
+		return nil
+	}
+	return &models.DBSyncCheckpoint{RunID: r.ID, Pass: r.Pass, StartAt: startAt}
+}
+
+// createSyncRun начинает новую синхронизацию проекта.
+func (s *ETLService) createSyncRun(ctx context.Context, projectKey string, full bool, updatedSince *time.Time, startedAt time.Time) (*syncRun, error) {
+	run := &syncRun{
+		DBSyncRun: models.DBSyncRun{
+			ProjectKey:   projectKey,
+			Full:         full,
+			UpdatedSince: updatedSince,
+			StartedAt:    startedAt,
+			Pass:         1,
+		},
+		seen: make(map[string]bool),
+	}
+
+	id, err := s.repo.CreateSyncRun(ctx, run.DBSyncRun)
+	if err != nil {
+		return nil, err
+	}
+	run.ID = id
+	return run, nil
+}
+
+// resumeSyncRun продолжает незавершённую синхронизацию проекта новым
+// проходом или возвращает nil, если продолжать нечего.
+func (s *ETLService) resumeSyncRun(ctx context.Context, projectKey string, full bool) (*syncRun, error) {
+	active, err := s.repo.GetActiveSyncRun(ctx, projectKey)
+	if err != nil || active == nil {
+		return nil, err
+	}
+	// Инкрементальная синхронизация не заменяет полную, её заменит новая
+	// полная синхронизация
+	if full && !active.Full {
+		return nil, nil
+	}
+
+	checkpoints, err := s.repo.GetSyncCheckpoints(ctx, active.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	run := &syncRun{DBSyncRun: *active, seen: make(map[string]bool), prefix: make(map[string]bool)}
+	done := make(map[int]models.DBSyncCheckpoint)
+	for _, c := range checkpoints {
+		for _, key := range c.IssueKeys {
+			run.seen[key] = true
+		}
+		// Контрольные точки упорядочены по проходам, у смещения остаётся
+		// батч последнего прохода
+		done[c.StartAt] = c
+	}
+
+	// Батчи идут с шагом IssueInOneRequest. Пустой батч тоже отмечается:
+	// в выборке GitHub он мог состоять из одних pull request'ов. Проход,
+	// начавший выборку заново, сдвинул смещения, поэтому батчи прошлых
+	// проходов после его батчей в префикс не входят.
+	pass := 0
+	for {
+		c, ok := done[run.resumeAt]
+		if !ok || c.Pass < pass {
+			break
+		}
+		pass = c.Pass
+		for _, key := range c.IssueKeys {
+			run.prefix[key] = true
+		}
+		run.resumeAt += s.IssueInOneRequest
+	}
+
+	run.Pass++
+	if err := s.repo.StartSyncRunPass(ctx, run.ID, run.Pass); err != nil {
+		return nil, err
+	}
+
+	log.Printf("Resuming sync of project %s (pass %d) from offset %d", projectKey, run.Pass, run.resumeAt)
+	return run, nil
+}
+
+// finishSyncRun завершает синхронизацию после сохранения watermark.
+func (s *ETLService) finishSyncRun(ctx context.Context, run *syncRun) error {
+	if run == nil {
+		return nil
+	}
+	if err := s.repo.FinishSyncRun(ctx, run.ID); err != nil {
+		return fmt.Errorf("failed to finish sync run: %w", err)
+	}
+	return nil
+}
@@ -1,6 +1,7 @@
 package service
 
 import (
+	"encoding/json"
 	"fmt"
 	"jiraAnalyzer/jiraConnector/internal/models"
 	"log"
@@ -18,32 +19,41 @@ func (s *ETLService) transformProject(projects []models.JiraProject, projectKey
 			}, nil
 		}
 	}
-	return models.DBProject{}, fmt.Errorf("project %s not found", projectKey)
+	return models.DBProject{}, fmt.Errorf("%w: %s", errProjectNotFound, projectKey)
 }
 
-func (s *ETLService) transformIssue(issue models.JiraIssue, projectKey string) (models.DBIssue, error) {
+func (s *ETLService) transformIssue(issue models.JiraIssue, projectKey string, authors authorIDs) (models.DBIssue, error) {
 	dbIssue := models.DBIssue{}
-
-	creatorID, err := s.repo.GetOrCreateAuthor(issue.Fields.Creator.DisplayName)
-	if err != nil {
-		return dbIssue, fmt.Errorf("failed to get or create creator: %w", err)
-	}
+	creatorID := authors.id(issue.Fields.Creator)
 
 	var assigneeID *int
 	if issue.Fields.Assignee != nil {
 		log.Printf("Assignee: %+v", issue.Fields.Assignee)
-		id, err := s.repo.GetOrCreateAuthor(issue.Fields.Assignee.DisplayName)
-		if err != nil {
-			return dbIssue, fmt.Errorf("failed to get or create assignee: %w", err)
-		}
+		id := authors.id(*issue.Fields.Assignee)
 		assigneeID = &id
 	}
 
-	var closedTime *time.Time
-	if parsedTime, err := GetClosedTime(issue.Changelog); err == nil {
-		closedTime = parsedTime
-	} else {
-		log.Printf("Failed to get closed time for issue %s: %v", issue.Key, err)
+	closedTime, err := GetClosedTime(issue.Changelog)
+	if err != nil {
+		return dbIssue, fmt.Errorf("failed to get closed time: %w", err)
+	}
+	// Без истории изменений, например в XML-выгрузке, временем закрытия
+	// закрытой задачи считается дата её решения
+	if closedTime == nil && len(issue.Changelog.Histories) == 0 &&
+		strings.EqualFold(issue.Fields.Status.Name, "closed") && issue.Fields.Resolved != "" {
+		resolved, err := parseJiraTime(issue.Fields.Resolved)
+		if err != nil {
+			return dbIssue, fmt.Errorf("failed to parse resolution time: %w", err)
+		}
+		closedTime = &resolved
+	}
+	created, err := parseJiraTime(issue.Fields.Created)
+	if err != nil {
+		return dbIssue, fmt.Errorf("failed to parse created time: %w", err)
+	}
+	updated, err := parseJiraTime(issue.Fields.Updated)
+	if err != nil {
+		return dbIssue, fmt.Errorf("failed to parse updated time: %w", err)
 	}
 
 	log.Printf("Transforming issue: %s, creator: %v, assignee: %v, timespent: %d, closed: %v",
@@ -62,8 +72,8 @@ func (s *ETLService) transformIssue(issue models.JiraIssue, projectKey string) (
 	dbIssue = models.DBIssue{
 		Key:         issue.Key,
 		ProjectKey:  projectKey,
-		Created:     parseJiraTime(issue.Fields.Created),
-		Updated:     parseJiraTime(issue.Fields.Updated),
+		Created:     created,
+		Updated:     updated,
 		Closed:      closedTime,
 		Summary:     issue.Fields.Summary,
 		Description: issue.Fields.Description,
@@ -75,44 +85,260 @@ func (s *ETLService) transformIssue(issue models.JiraIssue, projectKey string) (
 		AssigneeID:  assigneeID,
 	}
 
+	dbIssue.CustomFields, err = json.Marshal(s.customFields.extract(issue.Key, issue.Fields.Custom))
+	if err != nil {
+		return dbIssue, fmt.Errorf("failed to marshal custom fields: %w", err)
+	}
+
 	return dbIssue, nil
 }
 
-func (s *ETLService) extractChangelogs(issue models.JiraIssue) ([]models.DBChangelog, error) {
+// transformAuthor преобразует пользователя Jira. Email виден не всем
+// учётным записям, поэтому пустой email не затирает сохранённый.
+func transformAuthor(author models.JiraAuthor) models.DBAuthor {
+	return models.DBAuthor{
+		AccountID:   author.Identity(),
+		DisplayName: author.DisplayName,
+		Email:       nullIfEmpty(author.EmailAddress),
+		Active:      author.Active,
+	}
+}
+
+// extractChangelogs разбирает историю задачи: переходы статусов сохраняются
+// в status_changes, а изменения всех полей, включая статус, - в field_changes.
+func (s *ETLService) extractChangelogs(issue models.JiraIssue, authors authorIDs) ([]models.DBChangelog, []models.DBFieldChange, error) {
 	var dbChangelogs []models.DBChangelog
+	var dbFieldChanges []models.DBFieldChange
 	for _, history := range issue.Changelog.Histories {
-		for _, item := range history.Items {
-			if item.Field == "status" {
-				authorID, err := s.repo.GetOrCreateAuthor(history.Author.DisplayName)
-				if err != nil {
-					return nil, err
-				}
+		if len(history.Items) == 0 {
+			continue
+		}
+
+		authorID := authors.id(history.Author)
+		created, err := parseJiraTime(history.Created)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to parse history %s time: %w", history.ID, err)
+		}
 
+		for i, item := range history.Items {
+			if item.Field == "status" {
 				log.Printf("Processing changelog: from=%s, to=%s", item.FromString, item.ToString)
 				dbChangelogs = append(dbChangelogs, models.DBChangelog{
 					IssueID:    issue.Key,
 					AuthorID:   authorID,
-					Created:    parseJiraTime(history.Created),
+					Created:    created,
 					FromStatus: item.FromString,
 					ToStatus:   item.ToString,
 				})
 			}
+
+			dbFieldChanges = append(dbFieldChanges, models.DBFieldChange{
+				IssueID:    issue.Key,
+				AuthorID:   authorID,
+				HistoryID:  history.ID,
+				ItemIndex:  i,
+				Created:    created,
+				Field:      item.Field,
+				FieldType:  item.FieldType,
+				FromValue:  item.From,
+				FromString: nullIfEmpty(item.FromString),
+				ToValue:    item.To,
+				ToString:   nullIfEmpty(item.ToString),
+			})
+		}
+	}
+
+	return dbChangelogs, dbFieldChanges, nil
+}
+
+// extractComments возвращает комментарии задачи. Второй результат сообщает,
+// получены ли из Jira все комментарии задачи.
+func (s *ETLService) extractComments(issue models.JiraIssue, authors authorIDs) ([]models.DBComment, bool, error) {
+	page := issue.Fields.Comment
+	dbComments := make([]models.DBComment, 0, len(page.Comments))
+
+	for _, comment := range page.Comments {
+		dbComment, err := transformComment(issue.Key, comment, authors)
+		if err != nil {
+			return nil, false, err
+		}
+		dbComments = append(dbComments, dbComment)
+	}
+
+	complete := page.StartAt == 0 && len(page.Comments) >= page.Total
+	return dbComments, complete, nil
+}
+
+func transformComment(issueKey string, comment models.JiraComment, authors authorIDs) (models.DBComment, error) {
+	created, err := parseJiraTime(comment.Created)
+	if err != nil {
+		return models.DBComment{}, fmt.Errorf("failed to parse comment %s time: %w", comment.ID, err)
+	}
+	updated, err := parseJiraTime(comment.Updated)
+	if err != nil {
+		return models.DBComment{}, fmt.Errorf("failed to parse comment %s time: %w", comment.ID, err)
+	}
+
+	return models.DBComment{
+		JiraID:   comment.ID,
+		IssueID:  issueKey,
+		AuthorID: authors.id(comment.Author),
+		Created:  created,
+		Updated:  updated,
+		Body:     comment.Body,
+	}, nil
+}
+
+// extractWorklogs возвращает списания времени по задаче. Ожидается, что
+// обрезанный список уже дозагружен через GetIssueWorklogs. Второй результат
+// сообщает, получены ли все списания задачи.
+func (s *ETLService) extractWorklogs(issue models.JiraIssue, authors authorIDs) ([]models.DBWorklog, bool, error) {
+	page := issue.Fields.Worklog
+	if page == nil {
+		return nil, false, nil
+	}
+	worklogs := page.Worklogs
+	dbWorklogs := make([]models.DBWorklog, 0, len(worklogs))
+
+	for _, worklog := range worklogs {
+		var times [3]time.Time
+		for i, str := range []string{worklog.Started, worklog.Created, worklog.Updated} {
+			var err error
+			if times[i], err = parseJiraTime(str); err != nil {
+				return nil, false, fmt.Errorf("failed to parse worklog %s time: %w", worklog.ID, err)
+			}
+		}
+
+		dbWorklogs = append(dbWorklogs, models.DBWorklog{
+			JiraID:           worklog.ID,
+			IssueID:          issue.Key,
+			AuthorID:         authors.id(worklog.Author),
+			Started:          times[0],
+			TimeSpentSeconds: worklog.TimeSpentSeconds,
+			Created:          times[1],
+			Updated:          times[2],
+			Comment:          nullIfEmpty(worklog.Comment),
+		})
+	}
+
+	return dbWorklogs, !page.Truncated(), nil
+}
+
+// extractIssueLinks возвращает связи задачи в прямом направлении. Одна и та же
+// связь приходит в обеих задачах и сохраняется один раз по jira_id.
+func (s *ETLService) extractIssueLinks(issue models.JiraIssue) []models.DBIssueLink {
+	links := make([]models.DBIssueLink, 0, len(issue.Fields.IssueLinks))
+
+	for _, link := range issue.Fields.IssueLinks {
+		dbLink := models.DBIssueLink{
+			JiraID:   link.ID,
+			LinkType: link.Type.Name,
+			Outward:  link.Type.Outward,
+			Inward:   link.Type.Inward,
+		}
+
+		switch {
+		case link.OutwardIssue != nil:
+			dbLink.SourceKey, dbLink.TargetKey = issue.Key, link.OutwardIssue.Key
+		case link.InwardIssue != nil:
+			dbLink.SourceKey, dbLink.TargetKey = link.InwardIssue.Key, issue.Key
+		default:
+			log.Printf("Skipping link %s of issue %s without linked issue", link.ID, issue.Key)
+			continue
+		}
+
+		links = append(links, dbLink)
+	}
+
+	return links
+}
+
+// transformSprint преобразует спринт Agile API. Спринт относится к доске,
+// на которой создан, а если она неизвестна - к доске, через которую получен.
+func (s *ETLService) transformSprint(sprint models.JiraSprint, projectKey string, boardID int) models.DBSprint {
+	if sprint.OriginBoardID != 0 {
+		boardID = sprint.OriginBoardID
+	}
+
+	return models.DBSprint{
+		ID:           sprint.ID,
+		BoardID:      boardID,
+		ProjectKey:   projectKey,
+		Name:         sprint.Name,
+		State:        sprint.State,
+		Goal:         nullIfEmpty(sprint.Goal),
+		StartDate:    parseAgileTime(sprint.StartDate),
+		EndDate:      parseAgileTime(sprint.EndDate),
+		CompleteDate: parseAgileTime(sprint.CompleteDate),
+	}
+}
+
+// extractDimensions возвращает метки, компоненты и fix-версии задачи.
+// Компоненты и версии принадлежат проекту задачи.
+func (s *ETLService) extractDimensions(issue models.JiraIssue, projectKey string) models.DBIssueDimensions {
+	dimensions := models.DBIssueDimensions{
+		IssueKey: issue.Key,
+		Labels:   issue.Fields.Labels,
+	}
+
+	for _, component := range issue.Fields.Components {
+		dimensions.Components = append(dimensions.Components, models.DBComponent{
+			JiraID:     component.ID,
+			ProjectKey: projectKey,
+			Name:       component.Name,
+		})
+	}
+
+	for _, version := range issue.Fields.FixVersions {
+		dbVersion := models.DBVersion{
+			JiraID:     version.ID,
+			ProjectKey: projectKey,
+			Name:       version.Name,
+			Released:   version.Released,
+		}
+		if releaseDate, err := time.Parse(time.DateOnly, version.ReleaseDate); err == nil {
+			dbVersion.ReleaseDate = &releaseDate
 		}
+		dimensions.FixVersions = append(dimensions.FixVersions, dbVersion)
 	}
 
-	return dbChangelogs, nil
+	return dimensions
 }
 
-func parseJiraTime(str string) time.Time {
-	layout := "2006-01-02T15:04:05.000-0700"
-	t, err := time.Parse(layout, str)
+func nullIfEmpty(str string) *string {
+	if str == "" {
+		return nil
+	}
+	return &str
+}
+
+// parseJiraTime разбирает дату REST API Jira. Задача с неверной датой
+// не сохраняется, а попадает в etl_dead_letters.
+func parseJiraTime(str string) (time.Time, error) {
+	t, err := time.Parse(models.JiraTimeLayout, str)
 	if err != nil {
-		log.Printf("Failed to parse Jira time: %v", err)
-		return time.Time{} // Возвращаем пустую дату
+		return time.Time{}, fmt.Errorf("invalid Jira time %q: %w", str, err)
 	}
-	return t
+	return t, nil
 }
 
+// parseAgileTime разбирает дату Agile API в формате ISO 8601. Пустая
+// дата (например, у будущего спринта) возвращается как nil.
+func parseAgileTime(str string) *time.Time {
+	if str == "" {
+		return nil
+	}
+
+	t, err := time.Parse(time.RFC3339, str)
+	if err != nil {
+		log.Printf("Failed to parse Jira Agile time: %v", err)
+		return nil
+	}
+	return &t
+}
+
+// GetClosedTime возвращает время последнего перехода в статус Closed или
+// nil, если задача не закрыта.
 func GetClosedTime(changelog models.JiraChangelog) (*time.Time, error) {
 	log.Printf("Processing changelog with %d histories", len(changelog.Histories))
 	for i := len(changelog.Histories) - 1; i >= 0; i-- {
@@ -121,13 +347,13 @@ func GetClosedTime(changelog models.JiraChangelog) (*time.Time, error) {
 		for _, item := range history.Items {
 			log.Printf("Processing changelog: from=%s, to=%s", item.FromString, item.ToString)
 			if item.Field == "status" && strings.ToLower(item.ToString) == "closed" {
-				parsedTime := parseJiraTime(history.Created)
-				if parsedTime.IsZero() {
-					return nil, fmt.Errorf("failed to parse closed time")
+				parsedTime, err := parseJiraTime(history.Created)
+				if err != nil {
+					return nil, err
 				}
 				return &parsedTime, nil
 			}
 		}
 	}
-	return nil, fmt.Errorf("task is not closed")
+	return nil, nil
 }
@@ -0,0 +1,269 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"jiraAnalyzer/jiraConnector/internal/models"
+	"jiraAnalyzer/jiraConnector/internal/repository"
+	"jiraAnalyzer/jiraConnector/internal/repository/jira"
+	"log"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	ErrWebhookDisabled    = errors.New("webhook is not configured")
+	ErrInvalidSignature   = errors.New("invalid webhook signature")
+	ErrInvalidWebhookBody = errors.New("invalid webhook payload")
+	// ErrWebhookInProgress - доставку ещё обрабатывает другой запрос,
+	// Jira должна повторить её позже
+	ErrWebhookInProgress = errors.New("webhook delivery is being processed")
+)
+
+// Результаты обработки доставки webhook
+const (
+	WebhookProcessed = "processed"
+	// WebhookDuplicate - повтор уже принятой доставки
+	WebhookDuplicate = "duplicate"
+	// WebhookIgnored - событие не поддерживается или относится
+	// к проекту либо задаче, которые не синхронизируются
+	WebhookIgnored = "ignored"
+)
+
+const (
+	defaultWebhookRetention = 7 * 24 * time.Hour
+	// webhookPruneInterval - как часто удаляются старые доставки
+	webhookPruneInterval = time.Hour
+	// webhookClaimTimeout - через сколько необработанную доставку можно
+	// принять заново, если её обработчик упал
+	webhookClaimTimeout = 5 * time.Minute
+)
+
+type WebhookConfig struct {
+	// Secret - общий секрет webhook. Без секрета webhook отключён
+	Secret jira.Secret `yaml:"secret"`
+	// Retention - сколько хранятся идентификаторы доставок для дедупликации
+	Retention time.Duration `yaml:"retention"`
+}
+
+// WebhookService принимает события webhook Jira и применяет их к базе
+// тем же преобразованием, что и синхронизация.
+type WebhookService struct {
+	repo      *repository.Repository
+	etl       *ETLService
+	secret    string
+	retention time.Duration
+
+	pruneMu   sync.Mutex
+	lastPrune time.Time
+}
+
+func NewWebhookService(repo *repository.Repository, etl *ETLService, cfg WebhookConfig) (*WebhookService, error) {
+	s := &WebhookService{repo: repo, etl: etl, retention: cfg.Retention}
+	if s.retention <= 0 {
+		s.retention = defaultWebhookRetention
+	}
+
+	if cfg.Secret.IsSet() {
+		secret, err := cfg.Secret.Resolve()
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve webhook secret: %w", err)
+		}
+		s.secret = secret
+	}
+
+	return s, nil
+}
+
+// Verify проверяет подлинность доставки. Jira Cloud подписывает тело
+// HMAC-SHA256 в заголовке X-Hub-Signature, для Jira Server без подписи
+// секрет передаётся параметром в URL webhook.
+func (s *WebhookService) Verify(body []byte, signature, token string) error {
+	if s.secret == "" {
+		return ErrWebhookDisabled
+	}
+
+	if signature != "" {
+		expected, err := hex.DecodeString(strings.TrimPrefix(signature, "sha256="))
+		if err != nil {
+			return ErrInvalidSignature
+		}
+		mac := hmac.New(sha256.New, []byte(s.secret))
+		mac.Write(body)
+		if !hmac.Equal(mac.Sum(nil), expected) {
+			return ErrInvalidSignature
+		}
+		return nil
+	}
+
+	if token != "" && subtle.ConstantTimeCompare([]byte(token), []byte(s.secret)) == 1 {
+		return nil
+	}
+	return ErrInvalidSignature
+}
+
+// Handle обрабатывает доставку webhook. Повторная доставка с тем же
+// deliveryID пропускается, только если первая уже обработана: доставка
+// отмечается обработанной после записи в базу. Без deliveryID доставка
+// определяется по телу: повторы Jira отправляет с тем же телом.
+func (s *WebhookService) Handle(ctx context.Context, deliveryID string, body []byte) (string, error) {
+	var event models.JiraWebhookEvent
+	if err := json.Unmarshal(body, &event); err != nil {
+		return "", fmt.Errorf("%w: %w", ErrInvalidWebhookBody, err)
+	}
+
+	switch event.WebhookEvent {
+	case models.WebhookIssueCreated, models.WebhookIssueUpdated, models.WebhookIssueDeleted,
+		models.WebhookCommentCreated, models.WebhookCommentUpdated, models.WebhookCommentDeleted:
+	default:
+		log.Printf("Ignoring webhook event %q", event.WebhookEvent)
+		return WebhookIgnored, nil
+	}
+
+	if event.Issue == nil || event.Issue.Key == "" {
+		return "", fmt.Errorf("%w: %s without issue", ErrInvalidWebhookBody, event.WebhookEvent)
+	}
+	if strings.HasPrefix(event.WebhookEvent, "comment_") && event.Comment == nil {
+		return "", fmt.Errorf("%w: %s without comment", ErrInvalidWebhookBody, event.WebhookEvent)
+	}
+
+	if deliveryID == "" {
+		sum := sha256.Sum256(body)
+		deliveryID = "sha256:" + hex.EncodeToString(sum[:])
+	}
+
+	state, err := s.repo.ClaimWebhookDelivery(ctx, deliveryID, event.WebhookEvent, event.Issue.Key, webhookClaimTimeout)
+	if err != nil {
+		return "", err
+	}
+	switch state {
+	case models.WebhookDeliveryProcessed:
+		log.Printf("Skipping duplicate webhook delivery %s", deliveryID)
+		return WebhookDuplicate, nil
+	case models.WebhookDeliveryPending:
+		return "", fmt.Errorf("%w: %s", ErrWebhookInProgress, deliveryID)
+	}
+	s.pruneDeliveries(ctx)
+
+	status, err := s.apply(ctx, event)
+	if err != nil {
+		// Jira повторит доставку, и она должна быть обработана заново
+		if releaseErr := s.repo.ReleaseWebhookDelivery(context.WithoutCancel(ctx), deliveryID); releaseErr != nil {
+			log.Printf("Failed to release webhook delivery %s: %v", deliveryID, releaseErr)
+		}
+		return "", fmt.Errorf("failed to apply %s for issue %s: %w", event.WebhookEvent, event.Issue.Key, err)
+	}
+	// Изменения уже записаны. Если отметка не сохранится, доставку
+	// после webhookClaimTimeout можно будет применить повторно
+	if err := s.repo.CompleteWebhookDelivery(context.WithoutCancel(ctx), deliveryID); err != nil {
+		log.Printf("Failed to complete webhook delivery %s: %v", deliveryID, err)
+	}
+
+	log.Printf("Webhook %s for issue %s: %s", event.WebhookEvent, event.Issue.Key, status)
+	return status, nil
+}
+
+func (s *WebhookService) apply(ctx context.Context, event models.JiraWebhookEvent) (string, error) {
+	issue := *event.Issue
+
+	switch event.WebhookEvent {
+	case models.WebhookIssueCreated, models.WebhookIssueUpdated:
+		projectKey := issue.Fields.Project.Key
+		exists, err := s.repo.CheckProjectExists(ctx, projectKey)
+		if err != nil {
+			return "", fmt.Errorf("failed to check project exists: %w", err)
+		}
+		if !exists {
+			return WebhookIgnored, nil
+		}
+
+		if history, ok := webhookHistory(event); ok {
+			issue.Changelog.Histories = append(issue.Changelog.Histories, history)
+		}
+		// Webhook содержит не все комментарии и списания задачи, поэтому
+		// удалённые записи вычищает только синхронизация
+		if _, err := s.etl.saveIssues(ctx, projectKey, []models.JiraIssue{issue}, true, nil); err != nil {
+			return "", err
+		}
+
+	case models.WebhookIssueDeleted:
+		if err := s.repo.MarkIssuesDeleted(ctx, []string{issue.Key}); err != nil {
+			return "", err
+		}
+
+	case models.WebhookCommentCreated, models.WebhookCommentUpdated:
+		exists, err := s.repo.CheckIssueExists(ctx, issue.Key)
+		if err != nil {
+			return "", err
+		}
+		if !exists {
+			return WebhookIgnored, nil
+		}
+		if err := s.etl.saveComment(ctx, issue.Key, *event.Comment); err != nil {
+			return "", err
+		}
+
+	case models.WebhookCommentDeleted:
+		if err := s.repo.DeleteComment(ctx, event.Comment.ID); err != nil {
+			return "", err
+		}
+	}
+
+	return WebhookProcessed, nil
+}
+
+// webhookHistory собирает запись истории из изменения, вызвавшего событие.
+func webhookHistory(event models.JiraWebhookEvent) (models.JiraHistory, bool) {
+	if event.Changelog == nil || len(event.Changelog.Items) == 0 {
+		return models.JiraHistory{}, false
+	}
+
+	// Jira выставляет updated задачи равным времени изменения, с этим же
+	// временем запись истории придёт при синхронизации, и переход статуса
+	// не задвоится. Время события может отличаться на миллисекунды.
+	created := event.Issue.Fields.Updated
+	if created == "" {
+		timestamp := time.Now()
+		if event.Timestamp > 0 {
+			timestamp = time.UnixMilli(event.Timestamp)
+		}
+		created = timestamp.Format(models.JiraTimeLayout)
+	}
+
+	history := models.JiraHistory{
+		ID:      event.Changelog.ID,
+		Created: created,
+		Items:   event.Changelog.Items,
+	}
+	if event.User != nil {
+		history.Author = *event.User
+	}
+	return history, true
+}
+
+// pruneDeliveries удаляет устаревшие доставки не чаще webhookPruneInterval.
+func (s *WebhookService) pruneDeliveries(ctx context.Context) {
+	s.pruneMu.Lock()
+	if time.Since(s.lastPrune) < webhookPruneInterval {
+		s.pruneMu.Unlock()
+		return
+	}
+	s.lastPrune = time.Now()
+	s.pruneMu.Unlock()
+
+	count, err := s.repo.PruneWebhookDeliveries(ctx, s.retention)
+	if err != nil {
+		log.Printf("Failed to prune webhook deliveries: %v", err)
+		return
+	}
+	if count > 0 {
+		log.Printf("Pruned %d webhook deliveries", count)
+	}
+}
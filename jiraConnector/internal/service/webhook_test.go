@@ -0,0 +1,100 @@
+package service_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"jiraAnalyzer/jiraConnector/internal/models"
+	"jiraAnalyzer/jiraConnector/internal/service"
+	"testing"
+	"time"
+)
+
+// webhookUpdate собирает доставку jira:issue_updated с новым summary задачи.
+func webhookUpdate(t *testing.T, env *testEnv, key, summary string) []byte {
+	t.Helper()
+	issue, err := env.repo.GetIssue(context.Background(), key)
+	if err != nil || issue == nil {
+		t.Fatalf("get issue %s: %v", key, err)
+	}
+	issue.Fields.Summary = summary
+
+	body, err := json.Marshal(models.JiraWebhookEvent{WebhookEvent: models.WebhookIssueUpdated, Issue: issue})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return body
+}
+
+func TestWebhookRedeliveryAfterFailedApply(t *testing.T) {
+	env := newTestEnv(t, nil)
+	if err := env.sync(t, service.SyncOptions{Full: true}, "DEMO"); err != nil {
+		t.Fatal(err)
+	}
+	webhook, err := service.NewWebhookService(env.repo, env.etl, service.WebhookConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	body := webhookUpdate(t, env, "DEMO-1", "Changed by webhook")
+	ctx := context.Background()
+
+	// Запись задачи не удалась, доставка не должна считаться принятой
+	env.store.failCommits = 1
+	if _, err := webhook.Handle(ctx, "delivery-1", body); err == nil {
+		t.Fatal("webhook applied despite failed commit")
+	}
+	if issue, _ := env.store.issue("DEMO-1"); issue.Summary == "Changed by webhook" {
+		t.Fatal("issue updated despite failed commit")
+	}
+
+	status, err := webhook.Handle(ctx, "delivery-1", body)
+	if err != nil {
+		t.Fatalf("redelivery: %v", err)
+	}
+	if status != service.WebhookProcessed {
+		t.Errorf("redelivery status = %q, want %q", status, service.WebhookProcessed)
+	}
+	if issue, _ := env.store.issue("DEMO-1"); issue.Summary != "Changed by webhook" {
+		t.Errorf("summary = %q after redelivery", issue.Summary)
+	}
+
+	status, err = webhook.Handle(ctx, "delivery-1", body)
+	if err != nil || status != service.WebhookDuplicate {
+		t.Errorf("second redelivery = %q, %v, want %q", status, err, service.WebhookDuplicate)
+	}
+}
+
+func TestWebhookRedeliveryWhileProcessing(t *testing.T) {
+	env := newTestEnv(t, nil)
+	if err := env.sync(t, service.SyncOptions{Full: true}, "DEMO"); err != nil {
+		t.Fatal(err)
+	}
+	webhook, err := service.NewWebhookService(env.repo, env.etl, service.WebhookConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	body := webhookUpdate(t, env, "DEMO-1", "Changed by webhook")
+	ctx := context.Background()
+
+	// Первую попытку ещё обрабатывает другой запрос: повтор не может
+	// ответить успехом, пока неизвестно, чем она закончится
+	state, err := env.store.ClaimWebhookDelivery(ctx, "delivery-1", models.WebhookIssueUpdated, "DEMO-1", time.Hour)
+	if err != nil || state != models.WebhookDeliveryClaimed {
+		t.Fatalf("claim = %q, %v", state, err)
+	}
+	if _, err := webhook.Handle(ctx, "delivery-1", body); !errors.Is(err, service.ErrWebhookInProgress) {
+		t.Fatalf("error = %v, want %v", err, service.ErrWebhookInProgress)
+	}
+
+	// Первая попытка не удалась, повтор обрабатывается заново
+	if err := env.store.ReleaseWebhookDelivery(ctx, "delivery-1"); err != nil {
+		t.Fatal(err)
+	}
+	status, err := webhook.Handle(ctx, "delivery-1", body)
+	if err != nil || status != service.WebhookProcessed {
+		t.Fatalf("redelivery = %q, %v, want %q", status, err, service.WebhookProcessed)
+	}
+	if issue, _ := env.store.issue("DEMO-1"); issue.Summary != "Changed by webhook" {
+		t.Errorf("summary = %q after redelivery", issue.Summary)
+	}
+}
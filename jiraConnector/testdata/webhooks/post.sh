@@ -0,0 +1,22 @@
+#!/bin/sh
+# Отправляет записанное событие webhook в локальный коннектор с подписью,
+# как это делает Jira Cloud. Повторный запуск с тем же DELIVERY_ID
+# проверяет отсев повторных доставок.
+#
+#   JIRA_WEBHOOK_SECRET=secret ./post.sh issue_updated.json
+#   DELIVERY_ID=retry-1 JIRA_WEBHOOK_SECRET=secret ./post.sh comment_created.json
+set -eu
+
+payload=${1:?usage: post.sh <payload.json>}
+url=${CONNECTOR_URL:-http://localhost:8080}/webhooks/jira
+secret=${JIRA_WEBHOOK_SECRET:?JIRA_WEBHOOK_SECRET is not set}
+delivery=${DELIVERY_ID:-$(basename "$payload" .json)-$(date +%s)}
+
+signature=$(openssl dgst -sha256 -hmac "$secret" -hex < "$payload" | sed 's/^.* //')
+
+curl -sS -X POST "$url" \
+    -H "Content-Type: application/json" \
+    -H "X-Atlassian-Webhook-Identifier: $delivery" \
+    -H "X-Hub-Signature: sha256=$signature" \
+    --data-binary "@$payload"
+echo